@@ -0,0 +1,76 @@
+package amqpjobs
+
+import (
+	"fmt"
+	"math"
+
+	json "github.com/json-iterator/go"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner/v2/utils"
+)
+
+// argsFromPipeline parses queue or exchange arguments passed via the Declare RPC call as a JSON object
+func argsFromPipeline(pipe *pipeline.Pipeline, key string) (amqp.Table, error) {
+	str := pipe.String(key, "")
+	if str == "" {
+		return nil, nil
+	}
+
+	args := make(map[string]interface{})
+	err := json.Unmarshal(utils.AsBytes(str), &args)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTable(args), nil
+}
+
+// toTable converts configuration values into the AMQP compatible types
+func toTable(args map[string]interface{}) amqp.Table {
+	if len(args) == 0 {
+		return nil
+	}
+
+	table := make(amqp.Table, len(args))
+	for k, v := range args {
+		table[k] = toField(v)
+	}
+
+	return table
+}
+
+func toField(v interface{}) interface{} {
+	switch val := v.(type) {
+	case float64:
+		// JSON numbers are decoded as float64, but the broker expects integers for the x-args like x-delivery-limit
+		if val == math.Trunc(val) {
+			return int64(val)
+		}
+		return val
+	case int:
+		return int64(val)
+	case uint:
+		return int64(val)
+	case uint32:
+		return int64(val)
+	case uint64:
+		return int64(val)
+	case map[string]interface{}:
+		return toTable(val)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k := range val {
+			m[fmt.Sprint(k)] = val[k]
+		}
+		return toTable(m)
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i := 0; i < len(val); i++ {
+			arr[i] = toField(val[i])
+		}
+		return arr
+	default:
+		return val
+	}
+}
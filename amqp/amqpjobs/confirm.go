@@ -0,0 +1,75 @@
+package amqpjobs
+
+import (
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// size of the NotifyPublish channel, the confirmations are read by the dispatcher, so it's only a burst buffer
+const confirmsBuffer int = 100
+
+// confirmer dispatches the publisher confirmations of the publishing channel to the waiting publishers by the delivery tag.
+// Confirmations are read in the dedicated goroutine, so the late confirmations (after the confirm timeout) never block
+// the amqp connection reader.
+type confirmer struct {
+	mu      sync.Mutex
+	closed  bool
+	pending map[uint64]chan amqp.Confirmation
+}
+
+func newConfirmer(confirms chan amqp.Confirmation) *confirmer {
+	c := &confirmer{
+		pending: make(map[uint64]chan amqp.Confirmation),
+	}
+
+	go c.dispatch(confirms)
+
+	return c
+}
+
+func (c *confirmer) dispatch(confirms chan amqp.Confirmation) {
+	for conf := range confirms {
+		c.mu.Lock()
+		ch, ok := c.pending[conf.DeliveryTag]
+		delete(c.pending, conf.DeliveryTag)
+		c.mu.Unlock()
+
+		// no waiters - confirmation for the timed out publish
+		if ok {
+			ch <- conf
+		}
+	}
+
+	// publishing channel closed
+	c.mu.Lock()
+	c.closed = true
+	for tag, ch := range c.pending {
+		close(ch)
+		delete(c.pending, tag)
+	}
+	c.mu.Unlock()
+}
+
+// wait registers the publish with the delivery tag, should be called before the publishing.
+// The returned channel receives the confirmation or is closed when the publishing channel is closed.
+func (c *confirmer) wait(tag uint64) <-chan amqp.Confirmation {
+	ch := make(chan amqp.Confirmation, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		close(ch)
+	} else {
+		c.pending[tag] = ch
+	}
+	c.mu.Unlock()
+
+	return ch
+}
+
+// forget removes the waiter, used when the publish failed or timed out
+func (c *confirmer) forget(tag uint64) {
+	c.mu.Lock()
+	delete(c.pending, tag)
+	c.mu.Unlock()
+}
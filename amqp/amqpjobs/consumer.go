@@ -2,7 +2,6 @@ package amqpjobs
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -53,6 +52,20 @@ type consumer struct {
 	routingKey    string
 	multipleAck   bool
 	requeueOnFail bool
+	durable       bool
+	autoDelete    bool
+	queueArgs     amqp.Table
+	exchangeArgs  amqp.Table
+
+	// publisher confirms and mandatory returns, confirmer and notify channel are bound to the current publishing channel
+	confirms       bool
+	confirmTimeout time.Duration
+	mandatory      bool
+	confirmer      *confirmer
+	notifyReturn   chan amqp.Return
+
+	// delayStrategy is resolved during the initialization, auto is replaced with the actual strategy
+	delayStrategy string
 
 	listeners uint32
 	delayed   *int64
@@ -110,6 +123,15 @@ func NewAMQPConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Configur
 		exclusive:     conf.Exclusive,
 		multipleAck:   conf.MultipleAck,
 		requeueOnFail: conf.RequeueOnFail,
+		durable:       conf.Durable,
+		autoDelete:    conf.AutoDelete,
+		queueArgs:     toTable(conf.QueueArgs),
+		exchangeArgs:  toTable(conf.ExchangeArgs),
+
+		confirms:       conf.PublisherConfirms,
+		confirmTimeout: conf.ConfirmTimeout,
+		mandatory:      conf.Mandatory,
+		delayStrategy:  conf.DelayStrategy,
 	}
 
 	jb.conn, err = amqp.Dial(conf.Addr)
@@ -125,7 +147,7 @@ func NewAMQPConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Configur
 		return nil, errors.E(op, err)
 	}
 
-	pch, err := jb.conn.Channel()
+	pch, err := jb.publishChannel()
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -156,6 +178,16 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 		return nil, errors.E(op, err)
 	}
 	conf.InitDefault()
+
+	qArgs, err := argsFromPipeline(pipeline, queueArgs)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	exArgs, err := argsFromPipeline(pipeline, exchangeArgs)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
 	// PARSE CONFIGURATION -------
 
 	jb := &consumer{
@@ -176,6 +208,15 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 		exclusive:     pipeline.Bool(exclusive, false),
 		multipleAck:   pipeline.Bool(multipleAsk, false),
 		requeueOnFail: pipeline.Bool(requeueOnFail, false),
+		durable:       pipeline.Bool(durable, false),
+		autoDelete:    pipeline.Bool(autoDelete, false),
+		queueArgs:     qArgs,
+		exchangeArgs:  exArgs,
+
+		confirms:       pipeline.Bool(confirms, false),
+		confirmTimeout: pipeline.Duration(confirmTimeout, time.Second*10),
+		mandatory:      pipeline.Bool(mandatory, false),
+		delayStrategy:  pipeline.String(delayStrategy, delayDLX),
 	}
 
 	jb.conn, err = amqp.Dial(conf.Addr)
@@ -191,7 +232,7 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 		return nil, errors.E(op, err)
 	}
 
-	pch, err := jb.conn.Channel()
+	pch, err := jb.publishChannel()
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -226,6 +267,99 @@ func (c *consumer) Push(ctx context.Context, job *job.Job) error {
 	return nil
 }
 
+// PushBatch publishes the jobs in one AMQP transaction on the separate channel. Delayed jobs can't be published
+// in the transaction (delay queues are declared on the fly), so the batch with the delayed jobs is pushed one by one.
+func (c *consumer) PushBatch(ctx context.Context, jobs []*job.Job) error {
+	const op = errors.Op("rabbitmq_push_batch")
+
+	pipe := c.pipeline.Load().(*pipeline.Pipeline)
+	for i := 0; i < len(jobs); i++ {
+		if pipe.Name() != jobs[i].Options.Pipeline {
+			return errors.E(op, errors.Errorf("no such pipeline: %s, actual: %s", jobs[i].Options.Pipeline, pipe.Name()))
+		}
+	}
+
+	for i := 0; i < len(jobs); i++ {
+		if jobs[i].Options.Delay > 0 {
+			return c.pushEach(ctx, jobs)
+		}
+	}
+
+	select {
+	case pch := <-c.publishChan:
+		// the publishing channel is taken to protect the connection from the redial
+		defer func() {
+			c.publishChan <- pch
+		}()
+
+		err := c.publishTx(jobs)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, errors.TimeOut, ctx.Err())
+	}
+}
+
+func (c *consumer) publishTx(jobs []*job.Job) error {
+	txCh, err := c.conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = txCh.Close()
+	}()
+
+	err = txCh.Tx()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(jobs); i++ {
+		msg := fromJob(jobs[i])
+		table, errP := pack(msg.ID(), msg)
+		if errP != nil {
+			_ = txCh.TxRollback()
+			return errP
+		}
+
+		errP = txCh.Publish(c.exchangeName, c.routingKey, false, false, amqp.Publishing{
+			Headers:      table,
+			ContentType:  contentType,
+			Timestamp:    time.Now(),
+			DeliveryMode: amqp.Persistent,
+			MessageId:    msg.ID(),
+			Body:         msg.Body(),
+		})
+		if errP != nil {
+			_ = txCh.TxRollback()
+			return errP
+		}
+	}
+
+	return txCh.TxCommit()
+}
+
+// pushEach pushes the jobs one by one and reports the failed jobs
+func (c *consumer) pushEach(ctx context.Context, jobs []*job.Job) error {
+	errs := make(map[int]error)
+	for i := 0; i < len(jobs); i++ {
+		err := c.handleItem(ctx, fromJob(jobs[i]))
+		if err != nil {
+			errs[i] = err
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &jobState.BatchError{Errors: errs}
+}
+
 func (c *consumer) Register(_ context.Context, p *pipeline.Pipeline) error {
 	c.pipeline.Store(p)
 	return nil
@@ -290,6 +424,11 @@ func (c *consumer) State(ctx context.Context) (*jobState.State, error) {
 			return nil, errors.E(op, err)
 		}
 
+		delayed, err := c.delayedState(pch)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+
 		pipe := c.pipeline.Load().(*pipeline.Pipeline)
 
 		return &jobState.State{
@@ -297,7 +436,7 @@ func (c *consumer) State(ctx context.Context) (*jobState.State, error) {
 			Driver:   pipe.Driver(),
 			Queue:    q.Name,
 			Active:   int64(q.Messages),
-			Delayed:  atomic.LoadInt64(c.delayed),
+			Delayed:  delayed,
 			Ready:    ready(atomic.LoadUint32(&c.listeners)),
 		}, nil
 
@@ -421,50 +560,20 @@ func (c *consumer) handleItem(ctx context.Context, msg *Item) error {
 
 		// handle timeouts
 		if msg.Options.DelayDuration() > 0 {
-			atomic.AddInt64(c.delayed, 1)
-			// TODO declare separate method for this if condition
-			// TODO dlx cache channel??
-			delayMs := int64(msg.Options.DelayDuration().Seconds() * 1000)
-			tmpQ := fmt.Sprintf("delayed-%d.%s.%s", delayMs, c.exchangeName, c.queue)
-			_, err = pch.QueueDeclare(tmpQ, true, false, false, false, amqp.Table{
-				dlx:           c.exchangeName,
-				dlxRoutingKey: c.routingKey,
-				dlxTTL:        delayMs,
-				dlxExpires:    delayMs * 2,
-			})
-			if err != nil {
-				atomic.AddInt64(c.delayed, ^int64(0))
-				return errors.E(op, err)
-			}
-
-			err = pch.QueueBind(tmpQ, tmpQ, c.exchangeName, false, nil)
+			err = c.publishDelayed(ctx, pch, msg, table)
 			if err != nil {
-				atomic.AddInt64(c.delayed, ^int64(0))
-				return errors.E(op, err)
-			}
-
-			// insert to the local, limited pipeline
-			err = pch.Publish(c.exchangeName, tmpQ, false, false, amqp.Publishing{
-				Headers:      table,
-				ContentType:  contentType,
-				Timestamp:    time.Now(),
-				DeliveryMode: amqp.Persistent,
-				Body:         msg.Body(),
-			})
-
-			if err != nil {
-				atomic.AddInt64(c.delayed, ^int64(0))
 				return errors.E(op, err)
 			}
 
 			return nil
 		}
 
-		err = pch.Publish(c.exchangeName, c.routingKey, false, false, amqp.Publishing{
+		err = c.publish(ctx, pch, c.exchangeName, c.routingKey, amqp.Publishing{
 			Headers:      table,
 			ContentType:  contentType,
 			Timestamp:    time.Now(),
 			DeliveryMode: amqp.Persistent,
+			MessageId:    msg.ID(),
 			Body:         msg.Body(),
 		})
 
@@ -478,6 +587,83 @@ func (c *consumer) handleItem(ctx context.Context, msg *Item) error {
 	}
 }
 
+// publish sends the message to the exchange. With publisher confirms enabled, it waits for the broker ack and
+// reports nacks, timeouts and mandatory returns as errors. Should be called with the publishing channel taken from the publishChan.
+func (c *consumer) publish(ctx context.Context, pch *amqp.Channel, exchange, key string, msg amqp.Publishing) error {
+	const op = errors.Op("rabbitmq_publish")
+	// sequence number of the message which is going to be published
+	seq := pch.GetNextPublishSeqNo()
+
+	// delayed-message exchange routes the message only after the delay, so it can't be published as mandatory
+	mandatory := c.mandatory && exchange == c.exchangeName
+
+	if !c.confirms {
+		err := pch.Publish(exchange, key, mandatory, false, msg)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return nil
+	}
+
+	// registered before the publishing, so the confirmation can't be received earlier than the waiter
+	wait := c.confirmer.wait(seq)
+
+	err := pch.Publish(exchange, key, mandatory, false, msg)
+	if err != nil {
+		c.confirmer.forget(seq)
+		return errors.E(op, err)
+	}
+
+	timer := time.NewTimer(c.confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case conf, ok := <-wait:
+		if !ok {
+			return errors.E(op, errors.Str("publishing channel closed before the confirmation received"))
+		}
+
+		if !conf.Ack {
+			return errors.E(op, errors.Errorf("message was not confirmed by the broker, ID: %s", msg.MessageId))
+		}
+
+		if !mandatory {
+			return nil
+		}
+
+		return c.checkReturned(msg.MessageId)
+	case <-timer.C:
+		c.confirmer.forget(seq)
+		return errors.E(op, errors.TimeOut, errors.Errorf("publisher confirm timeout exceeded, ID: %s", msg.MessageId))
+	case <-ctx.Done():
+		c.confirmer.forget(seq)
+		return errors.E(op, errors.TimeOut, ctx.Err())
+	}
+}
+
+// checkReturned checks if the message was returned by the broker as unroutable. The broker sends the basic.return
+// before the basic.ack, so the return is already in the channel when confirmation is received.
+func (c *consumer) checkReturned(id string) error {
+	for {
+		select {
+		case ret, ok := <-c.notifyReturn:
+			if !ok {
+				return nil
+			}
+
+			if ret.MessageId == id {
+				return errors.E(errors.Op("rabbitmq_publish"), errors.Errorf("message returned by the broker, ID: %s, code: %d, reason: %s", id, ret.ReplyCode, ret.ReplyText))
+			}
+
+			// stale return from the message which was timed out previously
+			c.log.Error("message returned by the broker", "ID", ret.MessageId, "exchange", ret.Exchange, "routing key", ret.RoutingKey, "reply code", ret.ReplyCode, "reason", ret.ReplyText)
+		default:
+			return nil
+		}
+	}
+}
+
 func (c *consumer) handleQPush(ctx context.Context, msg []byte, queue string) error {
 	const op = errors.Op("rabbitmq_handle_item")
 	ch, err := c.conn.Channel()
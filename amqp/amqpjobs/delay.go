@@ -0,0 +1,290 @@
+package amqpjobs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/spiral/errors"
+)
+
+// delay strategies
+const (
+	// per-delay queue with the DLX pointing to the pipeline exchange (default)
+	delayDLX string = "dlx"
+	// x-delayed-message exchange, requires rabbitmq_delayed_message_exchange plugin
+	delayExchange string = "delayed_exchange"
+	// fixed set of TTL queues, message re-routed via the next bucket until it's ready
+	delayTTLBuckets string = "ttl_buckets"
+	// delayed exchange if the broker supports it, TTL buckets otherwise
+	delayAuto string = "auto"
+
+	delayedMessageType string = "x-delayed-message"
+	delayedType        string = "x-delayed-type"
+	delayHeader        string = "x-delay"
+	// deliverAt holds the unix time in milliseconds when the message should be delivered to the worker
+	deliverAt string = "rr_deliver_at"
+)
+
+// ttlBuckets are sorted from the largest to the smallest
+var ttlBuckets = [...]time.Duration{ //nolint:gochecknoglobals
+	time.Hour,
+	time.Minute * 10,
+	time.Minute,
+	time.Second * 10,
+	time.Second,
+}
+
+// bucketFor returns the largest bucket which fits into the delay, 0 means that the message should be delivered now
+func bucketFor(delay time.Duration) time.Duration {
+	for i := 0; i < len(ttlBuckets); i++ {
+		if delay >= ttlBuckets[i] {
+			return ttlBuckets[i]
+		}
+	}
+
+	return 0
+}
+
+func (c *consumer) bucketQueue(bucket time.Duration) string {
+	return fmt.Sprintf("delayed-bucket-%d.%s.%s", bucket.Milliseconds(), c.exchangeName, c.queue)
+}
+
+// probeDelayedExchange declares the delayed exchange on a throwaway connection. The unknown exchange type is
+// reported by the broker as the connection-level error (503 COMMAND_INVALID), which closes the whole connection.
+func (c *consumer) probeDelayedExchange() error {
+	conn, err := amqp.Dial(c.connStr)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	return c.declareDelayedExchange(channel)
+}
+
+func (c *consumer) delayedExchangeName() string {
+	return c.exchangeName + ".delayed"
+}
+
+// initDelayed declares the exchanges and queues needed by the delay strategy. Should be called after the main
+// exchange and queue were declared.
+func (c *consumer) initDelayed() error {
+	const op = errors.Op("rabbitmq_init_delayed")
+
+	if c.delayStrategy == delayAuto {
+		err := c.probeDelayedExchange()
+		if err != nil {
+			c.log.Warn("x-delayed-message exchange is not available, falling back to the TTL buckets", "error", err)
+			c.delayStrategy = delayTTLBuckets
+		} else {
+			c.delayStrategy = delayExchange
+		}
+	}
+
+	switch c.delayStrategy {
+	case delayExchange:
+		channel, err := c.conn.Channel()
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		err = c.declareDelayedExchange(channel)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		err = channel.QueueBind(c.queue, c.routingKey, c.delayedExchangeName(), false, nil)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return channel.Close()
+	case delayTTLBuckets:
+		channel, err := c.conn.Channel()
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		for i := 0; i < len(ttlBuckets); i++ {
+			q := c.bucketQueue(ttlBuckets[i])
+			_, err = channel.QueueDeclare(q, true, false, false, false, amqp.Table{
+				dlx:           c.exchangeName,
+				dlxRoutingKey: c.routingKey,
+				dlxTTL:        ttlBuckets[i].Milliseconds(),
+			})
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			err = channel.QueueBind(q, q, c.exchangeName, false, nil)
+			if err != nil {
+				return errors.E(op, err)
+			}
+		}
+
+		return channel.Close()
+	default:
+		return nil
+	}
+}
+
+func (c *consumer) declareDelayedExchange(channel *amqp.Channel) error {
+	return channel.ExchangeDeclare(
+		c.delayedExchangeName(),
+		delayedMessageType,
+		true,
+		false,
+		false,
+		false,
+		amqp.Table{delayedType: c.exchangeType},
+	)
+}
+
+// publishDelayed publishes the message using the configured delay strategy
+func (c *consumer) publishDelayed(ctx context.Context, pch *amqp.Channel, msg *Item, table amqp.Table) error {
+	const op = errors.Op("rabbitmq_publish_delayed")
+	delay := msg.Options.DelayDuration()
+
+	publishing := amqp.Publishing{
+		Headers:      table,
+		ContentType:  contentType,
+		Timestamp:    time.Now(),
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID(),
+		Body:         msg.Body(),
+	}
+
+	switch c.delayStrategy {
+	case delayExchange:
+		atomic.AddInt64(c.delayed, 1)
+		table[delayHeader] = delay.Milliseconds()
+
+		err := c.publish(ctx, pch, c.delayedExchangeName(), c.routingKey, publishing)
+		if err != nil {
+			decrementDelayed(c.delayed)
+			return errors.E(op, err)
+		}
+
+		return nil
+	case delayTTLBuckets:
+		table[deliverAt] = time.Now().Add(delay).UnixMilli()
+
+		err := c.publish(ctx, pch, c.exchangeName, c.bucketQueue(bucketFor(delay)), publishing)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return nil
+	default:
+		atomic.AddInt64(c.delayed, 1)
+		delayMs := delay.Milliseconds()
+		tmpQ := fmt.Sprintf("delayed-%d.%s.%s", delayMs, c.exchangeName, c.queue)
+		_, err := pch.QueueDeclare(tmpQ, true, false, false, false, amqp.Table{
+			dlx:           c.exchangeName,
+			dlxRoutingKey: c.routingKey,
+			dlxTTL:        delayMs,
+			dlxExpires:    delayMs * 2,
+		})
+		if err != nil {
+			decrementDelayed(c.delayed)
+			return errors.E(op, err)
+		}
+
+		err = pch.QueueBind(tmpQ, tmpQ, c.exchangeName, false, nil)
+		if err != nil {
+			decrementDelayed(c.delayed)
+			return errors.E(op, err)
+		}
+
+		// insert to the local, limited pipeline
+		err = c.publish(ctx, pch, c.exchangeName, tmpQ, publishing)
+		if err != nil {
+			decrementDelayed(c.delayed)
+			return errors.E(op, err)
+		}
+
+		return nil
+	}
+}
+
+// notReady returns the time left for the message returned from the TTL bucket, 0 if the message is ready
+func notReady(d amqp.Delivery) time.Duration {
+	at, ok := d.Headers[deliverAt].(int64)
+	if !ok {
+		return 0
+	}
+
+	return time.Until(time.UnixMilli(at))
+}
+
+// reroute moves the message, which is not ready to be delivered yet, to the largest TTL bucket fitting into the time left
+func (c *consumer) reroute(d amqp.Delivery, left time.Duration) error {
+	const op = errors.Op("rabbitmq_reroute")
+	ctx, cancel := context.WithTimeout(context.Background(), c.confirmTimeout)
+	defer cancel()
+
+	select {
+	case pch := <-c.publishChan:
+		err := c.publish(ctx, pch, c.exchangeName, c.bucketQueue(bucketFor(left)), amqp.Publishing{
+			Headers:      d.Headers,
+			ContentType:  contentType,
+			Timestamp:    time.Now(),
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Body:         d.Body,
+		})
+		c.publishChan <- pch
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return errors.E(op, errors.TimeOut, ctx.Err())
+	}
+}
+
+// delayedState returns the number of delayed messages. For the TTL buckets it's the number of messages in the
+// buckets, for other strategies - number of delayed messages published but not yet delivered to this consumer.
+func (c *consumer) delayedState(pch *amqp.Channel) (int64, error) {
+	if c.delayStrategy != delayTTLBuckets {
+		return atomic.LoadInt64(c.delayed), nil
+	}
+
+	var total int64
+	for i := 0; i < len(ttlBuckets); i++ {
+		q, err := pch.QueueInspect(c.bucketQueue(ttlBuckets[i]))
+		if err != nil {
+			return 0, err
+		}
+
+		total += int64(q.Messages)
+	}
+
+	return total, nil
+}
+
+// decrementDelayed decrements the delayed counter, but not below zero, because messages might be pushed by the other
+// consumer
+func decrementDelayed(delayed *int64) {
+	for {
+		val := atomic.LoadInt64(delayed)
+		if val <= 0 {
+			return
+		}
+
+		if atomic.CompareAndSwapInt64(delayed, val, val-1) {
+			return
+		}
+	}
+}
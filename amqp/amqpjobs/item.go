@@ -3,7 +3,6 @@ package amqpjobs
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 	"time"
 
 	json "github.com/json-iterator/go"
@@ -59,8 +58,6 @@ type Options struct {
 	requeueFn func(context.Context, *Item) error
 	qPushFn   func(context.Context, []byte, string) error
 
-	// delayed jobs TODO(rustatian): figure out how to get stats from the DLX
-	delayed     *int64
 	multipleAsk bool
 	requeue     bool
 }
@@ -103,24 +100,15 @@ func (i *Item) Context() ([]byte, error) {
 }
 
 func (i *Item) Ack() error {
-	if i.Options.Delay > 0 {
-		atomic.AddInt64(i.Options.delayed, ^int64(0))
-	}
 	return i.Options.ack(i.Options.multipleAsk)
 }
 
 func (i *Item) Nack() error {
-	if i.Options.Delay > 0 {
-		atomic.AddInt64(i.Options.delayed, ^int64(0))
-	}
 	return i.Options.nack(false, i.Options.requeue)
 }
 
 // Requeue with the provided delay, handled by the Nack
 func (i *Item) Requeue(headers map[string][]string, delay int64) error {
-	if i.Options.Delay > 0 {
-		atomic.AddInt64(i.Options.delayed, ^int64(0))
-	}
 	// overwrite the delay
 	i.Options.Delay = delay
 	i.Headers = headers
@@ -171,7 +159,6 @@ func (c *consumer) fromDelivery(d amqp.Delivery) (*Item, error) {
 
 	item.Options.ack = d.Ack
 	item.Options.nack = d.Nack
-	item.Options.delayed = c.delayed
 
 	// requeue func
 	item.Options.requeueFn = c.handleItem
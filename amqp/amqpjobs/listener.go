@@ -14,6 +14,29 @@ func (c *consumer) listener(deliv <-chan amqp.Delivery) {
 					return
 				}
 
+				// the message returned from the TTL bucket, but it's not the time to deliver it yet
+				if c.delayStrategy == delayTTLBuckets {
+					if left := notReady(msg); left > 0 && bucketFor(left) > 0 {
+						err := c.reroute(msg, left)
+						if err != nil {
+							c.log.Error("delayed message reroute", "error", err)
+							err = msg.Nack(false, true)
+							if err != nil {
+								c.log.Error("nack failed", "error", err)
+							}
+
+							continue
+						}
+
+						err = msg.Ack(false)
+						if err != nil {
+							c.log.Error("ack failed", "error", err)
+						}
+
+						continue
+					}
+				}
+
 				d, err := c.fromDelivery(msg)
 				if err != nil {
 					c.log.Error("amqp delivery convert", "error", err)
@@ -25,6 +48,11 @@ func (c *consumer) listener(deliv <-chan amqp.Delivery) {
 					continue
 				}
 
+				// delayed message arrived
+				if d.Options.Delay > 0 && c.delayStrategy != delayTTLBuckets {
+					decrementDelayed(c.delayed)
+				}
+
 				// insert job into the main priority queue
 				c.pq.Insert(d)
 			}
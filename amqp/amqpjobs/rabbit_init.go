@@ -1,6 +1,7 @@
 package amqpjobs
 
 import (
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/spiral/errors"
 )
 
@@ -22,7 +23,7 @@ func (c *consumer) initRabbitMQ() error {
 		false,
 		false,
 		false,
-		nil,
+		c.exchangeArgs,
 	)
 	if err != nil {
 		return errors.E(op, err)
@@ -31,11 +32,11 @@ func (c *consumer) initRabbitMQ() error {
 	// verify or declare a queue
 	q, err := channel.QueueDeclare(
 		c.queue,
-		false,
-		false,
+		c.durable,
+		c.autoDelete,
 		c.exclusive,
 		false,
-		nil,
+		c.queueArgs,
 	)
 	if err != nil {
 		return errors.E(op, err)
@@ -53,5 +54,43 @@ func (c *consumer) initRabbitMQ() error {
 		return errors.E(op, err)
 	}
 
-	return channel.Close()
+	err = channel.Close()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return c.initDelayed()
+}
+
+// publishChannel opens a new channel used to publish the messages and puts it into the confirm mode if needed
+func (c *consumer) publishChannel() (*amqp.Channel, error) {
+	const op = errors.Op("jobs_plugin_rmq_publish_channel")
+	pch, err := c.conn.Channel()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if c.confirms {
+		err = pch.Confirm(false)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+
+		c.confirmer = newConfirmer(pch.NotifyPublish(make(chan amqp.Confirmation, confirmsBuffer)))
+	}
+
+	if c.mandatory {
+		c.notifyReturn = pch.NotifyReturn(make(chan amqp.Return, 10))
+
+		// without confirms we can't associate the returned message with the push, so just log it
+		if !c.confirms {
+			go func(returns chan amqp.Return) {
+				for ret := range returns {
+					c.log.Error("message returned by the broker", "ID", ret.MessageId, "exchange", ret.Exchange, "routing key", ret.RoutingKey, "reply code", ret.ReplyCode, "reason", ret.ReplyText)
+				}
+			}(c.notifyReturn)
+		}
+	}
+
+	return pch, nil
 }
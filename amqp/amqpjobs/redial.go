@@ -59,7 +59,7 @@ func (c *consumer) redialer() { //nolint:gocognit
 					}
 
 					// redeclare publish channel
-					pch, errPubCh := c.conn.Channel()
+					pch, errPubCh := c.publishChannel()
 					if errPubCh != nil {
 						return errors.E(op, errPubCh)
 					}
@@ -2,7 +2,10 @@ package jobs
 
 import (
 	"context"
+	stderr "errors"
+	"fmt"
 
+	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
 	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
@@ -22,6 +25,75 @@ type Consumer interface {
 	State(ctx context.Context) (*State, error)
 }
 
+// BatchPusher is an optional interface for the drivers which are able to push several jobs in one call.
+// Drivers should push all jobs or none of them. If it's not possible, *BatchError should be returned
+// when only part of the jobs were pushed.
+type BatchPusher interface {
+	PushBatch(ctx context.Context, jobs []*job.Job) error
+}
+
+// Parent is an optional interface for the drivers which consume the jobs through the child pipelines (e.g. sharded).
+// Child pipelines are known after the Register, the jobs plugin resolves the jobs of the child pipelines by them.
+type Parent interface {
+	Children() []*pipeline.Pipeline
+}
+
+// BatchError is returned by the BatchPusher when only part of the jobs were pushed
+type BatchError struct {
+	// Errors of the failed jobs, keys are the jobs indexes in the batch, other jobs are pushed
+	Errors map[int]error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of the batch jobs failed to push", len(e.Errors))
+}
+
+// Valid reports whether all failed jobs indexes are within the batch of size n
+func (e *BatchError) Valid(n int) bool {
+	if len(e.Errors) == 0 {
+		return false
+	}
+
+	for i := range e.Errors {
+		if i < 0 || i >= n {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pusher pushes jobs into the declared pipelines, implemented by the jobs plugin
+type Pusher interface {
+	Push(j *job.Job) error
+}
+
+// NoSuchPipelineError is returned by the Pusher when the job pipeline is not declared
+type NoSuchPipelineError struct {
+	Pipeline string
+}
+
+func (e *NoSuchPipelineError) Error() string {
+	return "no such pipeline, requested: " + e.Pipeline
+}
+
+// IsNoSuchPipeline reports whether the push failed because the job pipeline is not declared, so the push retry
+// doesn't help until the pipeline is declared
+func IsNoSuchPipeline(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *NoSuchPipelineError:
+			return true
+		case *errors.Error:
+			err = e.Err
+		default:
+			err = stderr.Unwrap(err)
+		}
+	}
+
+	return false
+}
+
 // Acknowledger provides queue specific item management
 type Acknowledger interface {
 	// Ack - acknowledge the Item after processing
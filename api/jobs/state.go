@@ -14,6 +14,10 @@ type State struct {
 	Delayed int64
 	// Reserved jobs which are in the driver but not consumed yet
 	Reserved int64
+	// Buried jobs which are kept by the driver but will not be consumed until kicked
+	Buried int64
 	// Status - 1 Ready, 0 - Paused
 	Ready bool
+	// Extra contains driver specific counters, like file size or per-bucket number of jobs
+	Extra map[string]int64
 }
@@ -16,6 +16,7 @@ type Storage interface {
 
 	// Set used to upload item to KV with TTL
 	// 0 value in TTL means no TTL
+	// Item tags are stored in the tags index, see InvalidateTags
 	Set(items ...*kvv1.Item) error
 
 	// MExpire sets the TTL for multiply keys
@@ -31,6 +32,32 @@ type Storage interface {
 	// Delete one or multiple keys.
 	Delete(keys ...string) error
 
+	// Incr atomically increments the integer value of the key by delta and returns the new value.
+	// Missing key is created with the delta value. Timeout (RFC 3339, empty - no TTL) is set
+	// only if the key has no TTL yet, so the fixed window counters might be used.
+	Incr(key string, delta int64, timeout string) (int64, error)
+
+	// Decr atomically decrements the integer value of the key by delta, see Incr
+	// Memcached counters can't be negative, the value is floored at 0
+	Decr(key string, delta int64, timeout string) (int64, error)
+
+	// SetNX sets the item only if the key doesn't exist, returns false if the item was not set
+	SetNX(item *kvv1.Item) (bool, error)
+
+	// CompareAndSwap atomically replaces the value of the key with the newValue only if the current value is the oldValue.
+	// Returns false if the value was not replaced (missing key or another value). Timeout (RFC 3339) is set for
+	// the new value, empty timeout means no TTL.
+	CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error)
+
+	// Keys returns the page of the keys with the prefix (empty - all keys) and the cursor of the next page.
+	// Empty cursor starts the listing, empty next cursor means the last page. Limit is the max number of keys
+	// in the page (a hint for the redis). Not supported for the memcached.
+	Keys(prefix string, cursor string, limit int) ([]string, string, error)
+
+	// InvalidateTags deletes all keys tagged with any of the tags and the tags index.
+	// Not supported for the memcached.
+	InvalidateTags(tags ...string) error
+
 	// Stop the storage driver
 	Stop()
 }
@@ -40,3 +67,34 @@ type Constructor interface {
 	// KvFromConfig provides Storage based on the config key
 	KvFromConfig(key string) (Storage, error)
 }
+
+// Change operations, see Notifier
+const (
+	OpSet    string = "set"
+	OpDelete string = "delete"
+	// OpExpire - TTL of the key is changed
+	OpExpire string = "expire"
+	// OpClear - all keys are deleted, the key is empty
+	OpClear string = "clear"
+	// OpInvalidate - tagged keys are deleted, the key is the tag
+	OpInvalidate string = "invalidate"
+)
+
+// Notifier is implemented by the storages which detect the changes themselves, including the changes made by other
+// processes (e.g. redis keyspace notifications)
+type Notifier interface {
+	// Notify sends the changes to the fn until the storage is stopped, returns false if the notifications are not available
+	Notify(fn func(key, op string)) (bool, error)
+}
+
+// Tagger is implemented by the storages which return the item tags, used to export the keys with their tags
+type Tagger interface {
+	// Tags returns the tags of the tagged keys, keys without tags are omitted
+	Tags(keys ...string) (map[string][]string, error)
+}
+
+// StorageProvider provides configured storages by their names (sections in the kv plugin configuration)
+type StorageProvider interface {
+	// Storage returns the storage by name
+	Storage(name string) (Storage, error)
+}
@@ -0,0 +1,23 @@
+package kv
+
+import "time"
+
+// Timeout converts the TTL returned by the Storage.TTL into the RFC 3339 timeout, empty - no TTL.
+// The redis driver returns the remaining duration (negative if the key has no TTL), other drivers return the timeout.
+func Timeout(ttl string, now time.Time) string {
+	if ttl == "" {
+		return ""
+	}
+
+	if _, err := time.Parse(time.RFC3339, ttl); err == nil {
+		return ttl
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return ""
+	}
+
+	// round up, so the key doesn't expire earlier
+	return now.Add(d + time.Second - 1).Format(time.RFC3339)
+}
@@ -0,0 +1,21 @@
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	now := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "", Timeout("", now))
+	assert.Equal(t, "2021-11-01T10:05:00Z", Timeout("2021-11-01T10:05:00Z", now))
+
+	// redis durations
+	assert.Equal(t, "2021-11-01T10:01:00Z", Timeout("1m0s", now))
+	assert.Equal(t, "2021-11-01T10:00:02Z", Timeout("1.5s", now))
+	assert.Equal(t, "", Timeout("-1ns", now))
+	assert.Equal(t, "", Timeout("-2ns", now))
+}
@@ -6,6 +6,10 @@ import "context"
 This interface is in BETA. It might be changed.
 */
 
+// ReservedTopicPrefix is the prefix of the topics published by RoadRunner itself (e.g. jobs events),
+// messages with such topics are not accepted from the RPC
+const ReservedTopicPrefix string = "rr:"
+
 // PubSub interface designed to implement on any storage type to provide pub-sub abilities
 // Publisher used to receive messages from the PHP app via RPC
 // Subscriber should be implemented to subscribe to a topics and provide a connections list per topic
@@ -10,6 +10,8 @@ const (
 	tubePriority   string = "tube_priority"
 	tube           string = "tube"
 	reserveTimeout string = "reserve_timeout"
+	buryOnNack     string = "bury_on_nack"
+	touchInterval  string = "touch_interval"
 )
 
 type config struct {
@@ -22,6 +24,11 @@ type config struct {
 	TubePriority   *uint32       `mapstructure:"tube_priority"`
 	Tube           string        `mapstructure:"tube"`
 	ReserveTimeout time.Duration `mapstructure:"reserve_timeout"`
+	// BuryOnNack buries failed jobs instead of deleting them, buried jobs might be kicked back via RPC
+	BuryOnNack bool `mapstructure:"bury_on_nack"`
+	// TouchInterval is the interval to touch reserved jobs, so their TTR does not expire while they are processed.
+	// 0 - disabled
+	TouchInterval time.Duration `mapstructure:"touch_interval"`
 }
 
 func (c *config) InitDefault() {
@@ -120,6 +120,13 @@ func (cp *ConnPool) Delete(_ context.Context, id uint64) error {
 	return nil
 }
 
+func (cp *ConnPool) Touch(_ context.Context, id uint64) error {
+	cp.RLock()
+	defer cp.RUnlock()
+
+	return cp.conn.Touch(id)
+}
+
 func (cp *ConnPool) Stats(_ context.Context) (map[string]string, error) {
 	cp.RLock()
 	defer cp.RUnlock()
@@ -137,6 +144,24 @@ func (cp *ConnPool) Stats(_ context.Context) (map[string]string, error) {
 	return stat, nil
 }
 
+// TubeStats returns statistical information about the tube
+func (cp *ConnPool) TubeStats(_ context.Context) (map[string]string, error) {
+	cp.RLock()
+	defer cp.RUnlock()
+
+	stat, err := cp.t.Stats()
+	if err != nil {
+		errR := cp.checkAndRedial(err)
+		if errR != nil {
+			return nil, errors.Errorf("err: %s\nerr redial: %s", err, errR)
+		} else {
+			return cp.t.Stats()
+		}
+	}
+
+	return stat, nil
+}
+
 // Stop and close the connections
 func (cp *ConnPool) Stop() {
 	cp.Lock()
@@ -42,6 +42,9 @@ type consumer struct {
 	tName        string
 	tubePriority *uint32
 	priority     int64
+	// bury failed jobs instead of deleting them
+	buryOnNack    bool
+	touchInterval time.Duration
 
 	stopCh    chan struct{}
 	requeueCh chan *Item
@@ -97,6 +100,8 @@ func NewBeanstalkConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Con
 		reserveTimeout: conf.ReserveTimeout,
 		tubePriority:   conf.TubePriority,
 		priority:       conf.PipePriority,
+		buryOnNack:     conf.BuryOnNack,
+		touchInterval:  conf.TouchInterval,
 
 		// buffered with two because jobs root plugin can call Stop at the same time as Pause
 		stopCh:      make(chan struct{}, 2),
@@ -147,6 +152,8 @@ func FromPipeline(pipe *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.Conf
 		reserveTimeout: time.Second * time.Duration(pipe.Int(reserveTimeout, 5)),
 		tubePriority:   utils.Uint32(uint32(pipe.Int(tubePriority, 1))),
 		priority:       pipe.Priority(),
+		buryOnNack:     pipe.Bool(buryOnNack, false),
+		touchInterval:  pipe.Duration(touchInterval, 0),
 
 		// buffered with two because jobs root plugin can call Stop at the same time as Pause
 		stopCh:      make(chan struct{}, 2),
@@ -183,7 +190,7 @@ func (c *consumer) Register(_ context.Context, p *pipeline.Pipeline) error {
 // State https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L514
 func (c *consumer) State(ctx context.Context) (*jobState.State, error) {
 	const op = errors.Op("beanstalk_state")
-	stat, err := c.pool.Stats(ctx)
+	stat, err := c.pool.TubeStats(ctx)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -198,22 +205,27 @@ func (c *consumer) State(ctx context.Context) (*jobState.State, error) {
 	}
 
 	// set stat, skip errors (replace with 0)
-	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L523
+	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L612
 	if v, err := strconv.Atoi(stat["current-jobs-ready"]); err == nil {
 		out.Active = int64(v)
 	}
 
-	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L525
+	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L614
 	if v, err := strconv.Atoi(stat["current-jobs-reserved"]); err == nil {
 		// this is not an error, reserved in beanstalk behaves like an active jobs
 		out.Reserved = int64(v)
 	}
 
-	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L528
+	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L617
 	if v, err := strconv.Atoi(stat["current-jobs-delayed"]); err == nil {
 		out.Delayed = int64(v)
 	}
 
+	// https://github.com/beanstalkd/beanstalkd/blob/master/doc/protocol.txt#L619
+	if v, err := strconv.Atoi(stat["current-jobs-buried"]); err == nil {
+		out.Buried = int64(v)
+	}
+
 	return out, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"sync"
 	"time"
 
 	"github.com/beanstalkd/go-beanstalk"
@@ -47,6 +48,11 @@ type Options struct {
 	conn        *beanstalk.Conn
 	requeueFn   func(context.Context, *Item) error
 	handleTPush func([]byte, string) error
+	// bury the job on Nack instead of deleting it
+	buryOnNack   bool
+	tubePriority uint32
+	// stops periodic touch of the reserved job (if enabled)
+	stopTouch func()
 }
 
 // DelayDuration returns delay duration in a form of time.Duration.
@@ -87,14 +93,22 @@ func (i *Item) Context() ([]byte, error) {
 }
 
 func (i *Item) Ack() error {
+	i.stopTouching()
 	return i.Options.conn.Delete(i.Options.id)
 }
 
+// Nack deletes the job or buries it if bury_on_nack option is set.
+// Buried jobs are not consumed until kicked.
 func (i *Item) Nack() error {
+	i.stopTouching()
+	if i.Options.buryOnNack {
+		return i.Options.conn.Bury(i.Options.id, i.Options.tubePriority)
+	}
 	return i.Options.conn.Delete(i.Options.id)
 }
 
 func (i *Item) Requeue(headers map[string][]string, delay int64) error {
+	i.stopTouching()
 	// overwrite the delay
 	i.Options.Delay = delay
 	i.Headers = headers
@@ -122,6 +136,12 @@ func (i *Item) Respond(data []byte, queue string) error {
 	return nil
 }
 
+func (i *Item) stopTouching() {
+	if i.Options.stopTouch != nil {
+		i.Options.stopTouch()
+	}
+}
+
 func fromJob(job *job.Job) *Item {
 	return &Item{
 		Job:     job.Job,
@@ -145,6 +165,20 @@ func (c *consumer) unpack(id uint64, data []byte, out *Item) error {
 	out.Options.id = id
 	out.Options.requeueFn = c.handleItem
 	out.Options.handleTPush = c.handleTPush
+	out.Options.buryOnNack = c.buryOnNack
+	out.Options.tubePriority = *c.tubePriority
+
+	if c.touchInterval > 0 {
+		stopCh := make(chan struct{})
+		once := &sync.Once{}
+		out.Options.stopTouch = func() {
+			once.Do(func() {
+				close(stopCh)
+			})
+		}
+
+		go c.touch(id, stopCh)
+	}
 
 	return nil
 }
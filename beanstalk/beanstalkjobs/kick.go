@@ -0,0 +1,57 @@
+package beanstalkjobs
+
+import (
+	"math"
+	"strings"
+
+	"github.com/beanstalkd/go-beanstalk"
+	"github.com/spiral/errors"
+	cfgPlugin "github.com/spiral/roadrunner-plugins/v2/config"
+)
+
+// Kick moves up to bound buried jobs of the tube back into the ready queue. bound <= 0 means all buried jobs.
+// Returns number of the kicked jobs.
+func Kick(cfg cfgPlugin.Configurer, tubeName string, bound int) (int, error) {
+	const op = errors.Op("beanstalk_kick")
+
+	var conf config
+	if !cfg.Has(pluginName) {
+		return 0, errors.E(op, errors.Str("no global beanstalk configuration, global configuration should contain beanstalk addrs and timeout"))
+	}
+
+	err := cfg.UnmarshalKey(pluginName, &conf)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	conf.InitDefault()
+
+	if tubeName == "" {
+		tubeName = conf.Tube
+	}
+
+	if bound <= 0 {
+		bound = math.MaxInt32
+	}
+
+	dsn := strings.Split(conf.Addr, "://")
+	if len(dsn) != 2 {
+		return 0, errors.E(op, errors.Errorf("invalid socket DSN (tcp://127.0.0.1:11300, unix://beanstalk.sock), provided: %s", conf.Addr))
+	}
+
+	conn, err := beanstalk.DialTimeout(dsn[0], dsn[1], conf.Timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	n, err := beanstalk.NewTube(conn, tubeName).Kick(bound)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return n, nil
+}
@@ -2,6 +2,7 @@ package beanstalkjobs
 
 import (
 	"context"
+	"time"
 
 	"github.com/beanstalkd/go-beanstalk"
 )
@@ -41,3 +42,24 @@ func (c *consumer) listen() {
 		}
 	}
 }
+
+// touch periodically requests more time to work on the reserved job, so its TTR does not expire.
+// Should be shorter than the TTR (timeout option) minus reserve_timeout,
+// because the touch command shares the connection with the reserve command.
+func (c *consumer) touch(id uint64, stopCh chan struct{}) {
+	ticker := time.NewTicker(c.touchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			err := c.pool.Touch(context.Background(), id)
+			if err != nil {
+				c.log.Warn("beanstalk touch", "id", id, "error", err)
+				return
+			}
+		}
+	}
+}
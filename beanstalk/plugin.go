@@ -38,6 +38,11 @@ func (p *Plugin) Name() string {
 
 func (p *Plugin) Available() {}
 
+// RPC returns associated rpc service.
+func (p *Plugin) RPC() interface{} {
+	return &rpc{p: p}
+}
+
 func (p *Plugin) ConsumerFromConfig(configKey string, pq priorityqueue.Queue) (jobs.Consumer, error) {
 	return beanstalkjobs.NewBeanstalkConsumer(configKey, p.log, p.cfg, pq)
 }
@@ -0,0 +1,30 @@
+package beanstalk
+
+import (
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/beanstalk/beanstalkjobs"
+)
+
+type rpc struct {
+	p *Plugin
+}
+
+// KickRequest used to kick buried jobs back into the ready queue
+type KickRequest struct {
+	// Tube name
+	Tube string `json:"tube"`
+	// Bound is the max number of jobs to kick, 0 - all buried jobs
+	Bound int `json:"bound"`
+}
+
+// Kick moves buried jobs of the tube back into the ready queue and returns number of the kicked jobs
+func (r *rpc) Kick(in *KickRequest, kicked *int) error {
+	const op = errors.Op("beanstalk_rpc_kick")
+	n, err := beanstalkjobs.Kick(r.p.cfg, in.Tube, in.Bound)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	*kicked = n
+	return nil
+}
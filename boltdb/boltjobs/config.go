@@ -1,5 +1,9 @@
 package boltjobs
 
+import (
+	"time"
+)
+
 const (
 	file     string = "file"
 	priority string = "priority"
@@ -9,6 +13,8 @@ const (
 type config struct {
 	// global
 	Permissions int `mapstructure:"permissions"`
+	// CompactInterval is the interval to compact the database files, 0 - disabled
+	CompactInterval time.Duration `mapstructure:"compact_interval"`
 
 	// local
 	File     string `mapstructure:"file"`
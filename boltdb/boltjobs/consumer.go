@@ -24,6 +24,10 @@ const (
 	PluginName string = "boltdb"
 	rrDB       string = "rr.db"
 
+	// PipelinesBucket is the root bucket, every pipeline has its own nested bucket with
+	// the push, processing and delayed buckets inside, so one file might be shared between the pipelines
+	PipelinesBucket string = "pipelines"
+
 	PushBucket    string = "push"
 	InQueueBucket string = "processing"
 	DelayBucket   string = "delayed"
@@ -35,7 +39,11 @@ type consumer struct {
 	priority    int
 	prefetch    int
 
-	db *bolt.DB
+	db *db
+	// the database file is released only once, Stop might be called several times (e.g. Stop and then Destroy)
+	releaseOnce sync.Once
+	// pipeline bucket name
+	bucket []byte
 
 	bPool    sync.Pool
 	log      logger.Logger
@@ -74,52 +82,7 @@ func NewBoltDBJobs(configKey string, log logger.Logger, cfg cfgPlugin.Configurer
 	}
 
 	localCfg.InitDefaults()
-	db, err := bolt.Open(localCfg.File, os.FileMode(localCfg.Permissions), &bolt.Options{
-		Timeout:        time.Second * 20,
-		NoGrowSync:     false,
-		NoFreelistSync: false,
-		ReadOnly:       false,
-		NoSync:         false,
-	})
-
-	if err != nil {
-		return nil, errors.E(op, err)
-	}
-
-	// create bucket if it does not exist
-	// tx.Commit invokes via the db.Update
-	err = db.Update(func(tx *bolt.Tx) error {
-		const upOp = errors.Op("boltdb_plugin_update")
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(DelayBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(PushBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(InQueueBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-		cursor := inQb.Cursor()
-
-		pushB := tx.Bucket(utils.AsBytes(PushBucket))
-
-		// get all items, which are in the InQueueBucket and put them into the PushBucket
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			err = pushB.Put(k, v)
-			if err != nil {
-				return errors.E(op, err)
-			}
-		}
-		return nil
-	})
-
+	db, err := openDB(localCfg.File, os.FileMode(localCfg.Permissions), localCfg.CompactInterval, log)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -154,7 +117,7 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 	}
 
 	var conf config
-	err := cfg.UnmarshalKey(PluginName, conf)
+	err := cfg.UnmarshalKey(PluginName, &conf)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -162,53 +125,7 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 	// add default values
 	conf.InitDefaults()
 
-	db, err := bolt.Open(pipeline.String(file, rrDB), os.FileMode(conf.Permissions), &bolt.Options{
-		Timeout:        time.Second * 20,
-		NoGrowSync:     false,
-		NoFreelistSync: false,
-		ReadOnly:       false,
-		NoSync:         false,
-	})
-
-	if err != nil {
-		return nil, errors.E(op, err)
-	}
-
-	// create bucket if it does not exist
-	// tx.Commit invokes via the db.Update
-	err = db.Update(func(tx *bolt.Tx) error {
-		const upOp = errors.Op("boltdb_plugin_update")
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(DelayBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(PushBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		_, err = tx.CreateBucketIfNotExists(utils.AsBytes(InQueueBucket))
-		if err != nil {
-			return errors.E(op, upOp)
-		}
-
-		inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-		cursor := inQb.Cursor()
-
-		pushB := tx.Bucket(utils.AsBytes(PushBucket))
-
-		// get all items, which are in the InQueueBucket and put them into the PushBucket
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			err = pushB.Put(k, v)
-			if err != nil {
-				return errors.E(op, err)
-			}
-		}
-
-		return nil
-	})
-
+	db, err := openDB(pipeline.String(file, rrDB), os.FileMode(conf.Permissions), conf.CompactInterval, log)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
@@ -236,45 +153,152 @@ func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.
 
 func (c *consumer) Push(_ context.Context, job *job.Job) error {
 	const op = errors.Op("boltdb_jobs_push")
+	var delayed bool
 	err := c.db.Update(func(tx *bolt.Tx) error {
-		item := fromJob(job)
-		// pool with buffers
-		buf := c.get()
-		// encode the job
-		enc := gob.NewEncoder(buf)
-		err := enc.Encode(item)
-		if err != nil {
-			c.put(buf)
-			return errors.E(op, err)
+		var err error
+		delayed, err = c.insert(tx, job)
+		return err
+	})
+
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if delayed {
+		atomic.AddUint64(c.delayed, 1)
+		return nil
+	}
+
+	// increment active counter
+	atomic.AddUint64(c.active, 1)
+
+	return nil
+}
+
+// PushBatch pushes all jobs in one transaction
+func (c *consumer) PushBatch(_ context.Context, jobs []*job.Job) error {
+	const op = errors.Op("boltdb_jobs_push_batch")
+	var active, delayed uint64
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < len(jobs); i++ {
+			d, err := c.insert(tx, jobs[i])
+			if err != nil {
+				return err
+			}
+
+			if d {
+				delayed++
+				continue
+			}
+
+			active++
 		}
 
-		value := make([]byte, buf.Len())
-		copy(value, buf.Bytes())
+		return nil
+	})
+
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	atomic.AddUint64(c.delayed, delayed)
+	atomic.AddUint64(c.active, active)
+
+	return nil
+}
+
+// insert puts the job into the push or delay bucket, returns true if the job is delayed
+func (c *consumer) insert(tx *bolt.Tx, job *job.Job) (bool, error) {
+	item := fromJob(job)
+	// pool with buffers
+	buf := c.get()
+	// encode the job
+	enc := gob.NewEncoder(buf)
+	err := enc.Encode(item)
+	if err != nil {
 		c.put(buf)
+		return false, err
+	}
 
-		// handle delay
-		if item.Options.Delay > 0 {
-			b := tx.Bucket(utils.AsBytes(DelayBucket))
-			tKey := time.Now().UTC().Add(time.Second * time.Duration(item.Options.Delay)).Format(time.RFC3339)
+	value := make([]byte, buf.Len())
+	copy(value, buf.Bytes())
+	c.put(buf)
+
+	// handle delay
+	if item.Options.Delay > 0 {
+		b := bucket(tx, c.bucket, DelayBucket)
+		err = b.Put(delayKey(time.Now().Add(time.Second*time.Duration(item.Options.Delay)), item.ID()), value)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	b := bucket(tx, c.bucket, PushBucket)
+	err = b.Put(utils.AsBytes(item.ID()), value)
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
 
-			err = b.Put(utils.AsBytes(tKey), value)
+func (c *consumer) Register(_ context.Context, pipeline *pipeline.Pipeline) error {
+	const op = errors.Op("boltdb_register")
+	c.bucket = []byte(pipeline.Name())
+
+	// create buckets if they do not exist
+	// tx.Commit invokes via the db.Update
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(utils.AsBytes(PipelinesBucket))
+		if err != nil {
+			return err
+		}
+
+		pipeB, err := root.CreateBucketIfNotExists(c.bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []string{PushBucket, InQueueBucket, DelayBucket} {
+			_, err = pipeB.CreateBucketIfNotExists(utils.AsBytes(name))
 			if err != nil {
-				return errors.E(op, err)
+				return err
 			}
+		}
+
+		err = migrate(tx, pipeB)
+		if err != nil {
+			return err
+		}
+
+		inQb := pipeB.Bucket(utils.AsBytes(InQueueBucket))
+		pushB := pipeB.Bucket(utils.AsBytes(PushBucket))
 
-			atomic.AddUint64(c.delayed, 1)
+		// get all items, which are in the InQueueBucket (not acknowledged before the restart) and put them into the PushBucket.
+		// Keys are collected first, the cursor skips the next key after the Delete.
+		var keys [][]byte
+		cursor := inQb.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			err = pushB.Put(k, v)
+			if err != nil {
+				return err
+			}
 
-			return nil
+			keys = append(keys, k)
 		}
 
-		b := tx.Bucket(utils.AsBytes(PushBucket))
-		err = b.Put(utils.AsBytes(item.ID()), value)
-		if err != nil {
-			return errors.E(op, err)
+		for i := 0; i < len(keys); i++ {
+			err = inQb.Delete(keys[i])
+			if err != nil {
+				return err
+			}
 		}
 
-		// increment active counter
-		atomic.AddUint64(c.active, 1)
+		// restore counters, Stats doesn't count the keys written in this transaction
+		atomic.StoreUint64(c.active, count(pushB))
+		atomic.StoreUint64(c.delayed, count(pipeB.Bucket(utils.AsBytes(DelayBucket))))
 
 		return nil
 	})
@@ -283,10 +307,6 @@ func (c *consumer) Push(_ context.Context, job *job.Job) error {
 		return errors.E(op, err)
 	}
 
-	return nil
-}
-
-func (c *consumer) Register(_ context.Context, pipeline *pipeline.Pipeline) error {
 	c.pipeline.Store(pipeline)
 	return nil
 }
@@ -319,7 +339,12 @@ func (c *consumer) Stop(_ context.Context) error {
 
 	pipe := c.pipeline.Load().(*pipeline.Pipeline)
 	c.log.Debug("pipeline stopped", "driver", pipe.Driver(), "pipeline", pipe.Name(), "start", start, "elapsed", time.Since(start))
-	return c.db.Close()
+	var err error
+	c.releaseOnce.Do(func() {
+		err = c.db.release()
+	})
+
+	return err
 }
 
 func (c *consumer) Pause(_ context.Context, p string) {
@@ -369,8 +394,23 @@ func (c *consumer) Resume(_ context.Context, p string) {
 }
 
 func (c *consumer) State(_ context.Context) (*jobState.State, error) {
+	const op = errors.Op("boltdb_state")
 	pipe := c.pipeline.Load().(*pipeline.Pipeline)
 
+	extra := map[string]int64{
+		"file_size": c.db.Size(),
+	}
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		for _, name := range []string{PushBucket, InQueueBucket, DelayBucket} {
+			extra[name] = int64(bucket(tx, c.bucket, name).Stats().KeyN)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
 	return &jobState.State{
 		Pipeline: pipe.Name(),
 		Driver:   pipe.Driver(),
@@ -378,11 +418,54 @@ func (c *consumer) State(_ context.Context) (*jobState.State, error) {
 		Active:   int64(atomic.LoadUint64(c.active)),
 		Delayed:  int64(atomic.LoadUint64(c.delayed)),
 		Ready:    toBool(atomic.LoadUint32(&c.listeners)),
+		Extra:    extra,
 	}, nil
 }
 
 // Private
 
+// bucket returns the pipeline's nested bucket
+func bucket(tx *bolt.Tx, pipe []byte, name string) *bolt.Bucket {
+	return tx.Bucket(utils.AsBytes(PipelinesBucket)).Bucket(pipe).Bucket(utils.AsBytes(name))
+}
+
+// migrate moves jobs from the previous layout (global push/processing/delayed buckets) into the pipeline buckets.
+// The first registered pipeline on the file takes them.
+func migrate(tx *bolt.Tx, pipeB *bolt.Bucket) error {
+	for _, name := range []string{PushBucket, InQueueBucket, DelayBucket} {
+		legacy := tx.Bucket(utils.AsBytes(name))
+		if legacy == nil {
+			continue
+		}
+
+		dst := pipeB.Bucket(utils.AsBytes(name))
+		err := legacy.ForEach(func(k, v []byte) error {
+			return dst.Put(k, v)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.DeleteBucket(utils.AsBytes(name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// count returns the number of the keys in the bucket
+func count(b *bolt.Bucket) uint64 {
+	var n uint64
+	cursor := b.Cursor()
+	for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+		n++
+	}
+
+	return n
+}
+
 func (c *consumer) get() *bytes.Buffer {
 	return c.bPool.Get().(*bytes.Buffer)
 }
@@ -395,3 +478,8 @@ func (c *consumer) put(b *bytes.Buffer) {
 func toBool(r uint32) bool {
 	return r > 0
 }
+
+// delayKey is the DelayBucket key, jobs with the same delivery time are distinguished by the ID
+func delayKey(at time.Time, id string) []byte {
+	return utils.AsBytes(at.UTC().Format(time.RFC3339) + "/" + id)
+}
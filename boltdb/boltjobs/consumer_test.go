@@ -0,0 +1,72 @@
+package boltjobs
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+func TestRegisterRestoresProcessing(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rr.db")
+	d, err := openDB(file, 0o600, 0, logger.NewZapAdapter(zap.NewNop()))
+	require.NoError(t, err)
+	defer func() {
+		_ = d.release()
+	}()
+
+	// not acknowledged before the restart
+	err = d.Update(func(tx *bolt.Tx) error {
+		root, errC := tx.CreateBucketIfNotExists(utils.AsBytes(PipelinesBucket))
+		if errC != nil {
+			return errC
+		}
+
+		pipeB, errC := root.CreateBucketIfNotExists([]byte("test"))
+		if errC != nil {
+			return errC
+		}
+
+		inQb, errC := pipeB.CreateBucketIfNotExists(utils.AsBytes(InQueueBucket))
+		if errC != nil {
+			return errC
+		}
+
+		for i := 0; i < 10; i++ {
+			errC = inQb.Put([]byte("job-"+strconv.Itoa(i)), []byte("job"))
+			if errC != nil {
+				return errC
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	c := &consumer{
+		db:      d,
+		bPool:   sync.Pool{New: func() interface{} { return new(bytes.Buffer) }},
+		cond:    sync.NewCond(&sync.Mutex{}),
+		active:  utils.Uint64(0),
+		delayed: utils.Uint64(0),
+	}
+	require.NoError(t, c.Register(context.Background(), &pipeline.Pipeline{"name": "test"}))
+
+	assert.Equal(t, uint64(10), atomic.LoadUint64(c.active))
+	err = d.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 10, bucket(tx, c.bucket, PushBucket).Stats().KeyN)
+		assert.Equal(t, 0, bucket(tx, c.bucket, InQueueBucket).Stats().KeyN)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,237 @@
+package boltjobs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// compaction transaction size limit
+	compactTxMaxSize int64  = 64 * 1024 * 1024
+	compactSuffix    string = ".compact"
+)
+
+var (
+	dbsMu sync.Mutex //nolint:gochecknoglobals
+	// opened database files, shared between the pipelines (key - absolute path to the file)
+	dbs = make(map[string]*db) //nolint:gochecknoglobals
+)
+
+// db is a database file shared between the pipelines. All access goes through the Update/View methods,
+// so the underlying bolt.DB might be replaced by the compaction.
+type db struct {
+	mu   sync.RWMutex
+	bdb  *bolt.DB
+	path string
+	perm os.FileMode
+	log  logger.Logger
+
+	// number of pipelines using the file, guarded by dbsMu
+	refs   int
+	stopCh chan struct{}
+}
+
+// openDB returns already opened database file or opens a new one.
+// compactInterval > 0 starts the scheduled compaction for the newly opened file.
+func openDB(file string, perm os.FileMode, compactInterval time.Duration, log logger.Logger) (*db, error) {
+	const op = errors.Op("boltdb_open_db")
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	dbsMu.Lock()
+	defer dbsMu.Unlock()
+
+	if d, ok := dbs[path]; ok {
+		d.refs++
+		return d, nil
+	}
+
+	bdb, err := open(path, perm)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	d := &db{
+		bdb:    bdb,
+		path:   path,
+		perm:   perm,
+		log:    log,
+		refs:   1,
+		stopCh: make(chan struct{}),
+	}
+
+	if compactInterval > 0 {
+		go d.compactor(compactInterval)
+	}
+
+	dbs[path] = d
+	return d, nil
+}
+
+// Compact compacts already opened database file
+func Compact(file string) error {
+	const op = errors.Op("boltdb_compact")
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	dbsMu.Lock()
+	d, ok := dbs[path]
+	dbsMu.Unlock()
+
+	if !ok {
+		return errors.E(op, errors.Errorf("database file is not opened by any pipeline: %s", file))
+	}
+
+	err = d.compact()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func open(path string, perm os.FileMode) (*bolt.DB, error) {
+	return bolt.Open(path, perm, &bolt.Options{
+		Timeout:        time.Second * 20,
+		NoGrowSync:     false,
+		NoFreelistSync: false,
+		ReadOnly:       false,
+		NoSync:         false,
+	})
+}
+
+func (d *db) Update(fn func(tx *bolt.Tx) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.bdb.Update(fn)
+}
+
+func (d *db) View(fn func(tx *bolt.Tx) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.bdb.View(fn)
+}
+
+// Size returns the database file size
+func (d *db) Size() int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.size()
+}
+
+// release closes the file when it is not used by any pipeline
+func (d *db) release() error {
+	dbsMu.Lock()
+	defer dbsMu.Unlock()
+
+	// already closed
+	if d.refs <= 0 {
+		return nil
+	}
+
+	d.refs--
+	if d.refs > 0 {
+		return nil
+	}
+
+	delete(dbs, d.path)
+	close(d.stopCh)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.bdb.Close()
+}
+
+/*
+compact algorithm:
+1. Block all transactions.
+2. Copy live data into the fresh file (path + .compact).
+3. Replace the current file with the fresh one, switch to the fresh database (it's still opened) and close the current one.
+
+All transactions are blocked for the whole compaction, so push and consume on every pipeline sharing the file are paused
+until the copy is finished (the time depends on the live data size, not the file size). The copy can't be taken without
+the lock, otherwise the writes made during the compaction would be lost with the swap. The current database is used
+until the fresh file replaces it, so a failed compaction leaves the pipelines working on the current file.
+*/
+func (d *db) compact() error {
+	start := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	before := d.size()
+	tmp := d.path + compactSuffix
+	_ = os.Remove(tmp)
+
+	dst, err := open(tmp, d.perm)
+	if err != nil {
+		return err
+	}
+
+	err = bolt.Compact(dst, d.bdb, compactTxMaxSize)
+	if err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	err = dst.Sync()
+	if err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	// the opened fresh database keeps working on the renamed file
+	err = os.Rename(tmp, d.path)
+	if err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	old := d.bdb
+	d.bdb = dst
+
+	err = old.Close()
+	if err != nil {
+		d.log.Warn("boltdb file closing after the compaction failed", "file", d.path, "error", err)
+	}
+
+	d.log.Debug("boltdb file compacted", "file", d.path, "before", before, "after", d.size(), "start", start, "elapsed", time.Since(start))
+	return nil
+}
+
+func (d *db) compactor(interval time.Duration) {
+	tt := time.NewTicker(interval)
+	defer tt.Stop()
+
+	for {
+		select {
+		case <-tt.C:
+			err := d.compact()
+			if err != nil {
+				d.log.Error("boltdb compaction failed", "file", d.path, "error", err)
+			}
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+func (d *db) size() int64 {
+	fi, err := os.Stat(d.path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
@@ -0,0 +1,71 @@
+package boltjobs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+func TestSharedDBCompact(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rr.db")
+	log := logger.NewZapAdapter(zap.NewNop())
+
+	d1, err := openDB(file, 0o600, 0, log)
+	require.NoError(t, err)
+	// the second pipeline on the same file gets the same handle instead of waiting for the file lock
+	d2, err := openDB(file, 0o600, 0, log)
+	require.NoError(t, err)
+	require.True(t, d1 == d2)
+
+	err = d1.Update(func(tx *bolt.Tx) error {
+		b, errC := tx.CreateBucketIfNotExists([]byte("test"))
+		if errC != nil {
+			return errC
+		}
+		for i := 0; i < 1000; i++ {
+			errC = b.Put([]byte{byte(i >> 8), byte(i)}, make([]byte, 1024))
+			if errC != nil {
+				return errC
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = d1.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("test"))
+		for i := 0; i < 990; i++ {
+			errD := b.Delete([]byte{byte(i >> 8), byte(i)})
+			if errD != nil {
+				return errD
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	before := d1.Size()
+	require.NoError(t, Compact(file))
+	assert.Less(t, d1.Size(), before)
+
+	err = d2.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 10, tx.Bucket([]byte("test")).Stats().KeyN)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d1.release())
+	// still used by the second pipeline
+	require.NoError(t, Compact(file))
+	require.NoError(t, d2.release())
+	require.Error(t, Compact(file))
+
+	// released more times than opened
+	require.NoError(t, d2.release())
+	require.NoError(t, d1.release())
+}
@@ -43,7 +43,9 @@ type Options struct {
 	Delay int64 `json:"delay,omitempty"`
 
 	// private
-	db      *bbolt.DB
+	db *db
+	// pipeline bucket
+	bucket  []byte
 	active  *uint64
 	delayed *uint64
 }
@@ -79,16 +81,11 @@ func (i *Item) Context() ([]byte, error) {
 
 func (i *Item) Ack() error {
 	const op = errors.Op("boltdb_item_ack")
-	tx, err := i.Options.db.Begin(true)
+	err := i.Options.db.Update(func(tx *bbolt.Tx) error {
+		inQb := bucket(tx, i.Options.bucket, InQueueBucket)
+		return inQb.Delete(utils.AsBytes(i.ID()))
+	})
 	if err != nil {
-		_ = tx.Rollback()
-		return errors.E(op, err)
-	}
-
-	inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-	err = inQb.Delete(utils.AsBytes(i.ID()))
-	if err != nil {
-		_ = tx.Rollback()
 		return errors.E(op, err)
 	}
 
@@ -98,7 +95,7 @@ func (i *Item) Ack() error {
 		atomic.AddUint64(i.Options.active, ^uint64(0))
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (i *Item) Nack() error {
@@ -110,30 +107,24 @@ func (i *Item) Nack() error {
 		3. put it back to the PushBucket
 		4. Delete it from the InQueueBucket
 	*/
-	tx, err := i.Options.db.Begin(true)
-	if err != nil {
-		_ = tx.Rollback()
-		return errors.E(op, err)
-	}
+	err := i.Options.db.Update(func(tx *bbolt.Tx) error {
+		inQb := bucket(tx, i.Options.bucket, InQueueBucket)
+		v := inQb.Get(utils.AsBytes(i.ID()))
 
-	inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-	v := inQb.Get(utils.AsBytes(i.ID()))
+		pushB := bucket(tx, i.Options.bucket, PushBucket)
 
-	pushB := tx.Bucket(utils.AsBytes(PushBucket))
-
-	err = pushB.Put(utils.AsBytes(i.ID()), v)
-	if err != nil {
-		_ = tx.Rollback()
-		return errors.E(op, err)
-	}
+		err := pushB.Put(utils.AsBytes(i.ID()), v)
+		if err != nil {
+			return err
+		}
 
-	err = inQb.Delete(utils.AsBytes(i.ID()))
+		return inQb.Delete(utils.AsBytes(i.ID()))
+	})
 	if err != nil {
-		_ = tx.Rollback()
 		return errors.E(op, err)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 /*
@@ -143,7 +134,7 @@ Requeue algorithm:
 3. Delete item from the InQueueBucket
 4. Handle items with the delay:
    4.1. Get DelayBucket
-   4.2. Make a key by adding the delay to the time.Now() in RFC3339 format
+   4.2. Make a key by adding the delay to the time.Now() in RFC3339 format and the job ID
    4.3. Put this key with value to the DelayBucket
 5. W/o delay, put the key with value to the PushBucket (requeue)
 */
@@ -152,72 +143,53 @@ func (i *Item) Requeue(headers map[string][]string, delay int64) error {
 	i.Headers = headers
 	i.Options.Delay = delay
 
-	tx, err := i.Options.db.Begin(true)
+	// encode the item
+	buf := new(bytes.Buffer)
+	enc := gob.NewEncoder(buf)
+	err := enc.Encode(i)
 	if err != nil {
 		return errors.E(op, err)
 	}
 
-	inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-	err = inQb.Delete(utils.AsBytes(i.ID()))
-	if err != nil {
-		return errors.E(op, i.rollback(err, tx))
-	}
-
-	// encode the item
-	buf := new(bytes.Buffer)
-	enc := gob.NewEncoder(buf)
-	err = enc.Encode(i)
 	val := make([]byte, buf.Len())
 	copy(val, buf.Bytes())
 	buf.Reset()
 
-	if delay > 0 {
-		delayB := tx.Bucket(utils.AsBytes(DelayBucket))
-		tKey := time.Now().UTC().Add(time.Second * time.Duration(delay)).Format(time.RFC3339)
-
-		if err != nil {
-			return errors.E(op, i.rollback(err, tx))
-		}
-
-		err = delayB.Put(utils.AsBytes(tKey), val)
+	err = i.Options.db.Update(func(tx *bbolt.Tx) error {
+		inQb := bucket(tx, i.Options.bucket, InQueueBucket)
+		err = inQb.Delete(utils.AsBytes(i.ID()))
 		if err != nil {
-			return errors.E(op, i.rollback(err, tx))
+			return err
 		}
 
-		return tx.Commit()
-	}
+		if delay > 0 {
+			delayB := bucket(tx, i.Options.bucket, DelayBucket)
+			tKey := delayKey(time.Now().Add(time.Second*time.Duration(delay)), i.ID())
 
-	pushB := tx.Bucket(utils.AsBytes(PushBucket))
-	if err != nil {
-		return errors.E(op, i.rollback(err, tx))
-	}
+			return delayB.Put(tKey, val)
+		}
 
-	err = pushB.Put(utils.AsBytes(i.ID()), val)
+		pushB := bucket(tx, i.Options.bucket, PushBucket)
+		return pushB.Put(utils.AsBytes(i.ID()), val)
+	})
 	if err != nil {
-		return errors.E(op, i.rollback(err, tx))
+		return errors.E(op, err)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (i *Item) Respond(_ []byte, _ string) error {
 	return nil
 }
 
-func (i *Item) attachDB(db *bbolt.DB, active, delayed *uint64) {
+func (i *Item) attachDB(db *db, bucket []byte, active, delayed *uint64) {
 	i.Options.db = db
+	i.Options.bucket = bucket
 	i.Options.active = active
 	i.Options.delayed = delayed
 }
 
-func (i *Item) rollback(err error, tx *bbolt.Tx) error {
-	errR := tx.Rollback()
-	if errR != nil {
-		return errors.Errorf("transaction commit error: %v, rollback failed: %v", err, errR)
-	}
-	return errors.Errorf("transaction commit error: %v", err)
-}
-
 func fromJob(job *job.Job) *Item {
 	return &Item{
 		Job:     job.Job,
@@ -23,53 +23,53 @@ func (c *consumer) listener() {
 				time.Sleep(time.Second)
 				continue
 			}
-			tx, err := c.db.Begin(true)
-			if err != nil {
-				c.log.Error("failed to begin writable transaction, job will be read on the next attempt", "error", err)
-				continue
-			}
 
-			b := tx.Bucket(utils.AsBytes(PushBucket))
-			inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
+			var item *Item
+			err := c.db.Update(func(tx *bolt.Tx) error {
+				b := bucket(tx, c.bucket, PushBucket)
+				inQb := bucket(tx, c.bucket, InQueueBucket)
 
-			// get first item
-			k, v := b.Cursor().First()
-			if k == nil && v == nil {
-				_ = tx.Commit()
-				continue
-			}
+				// get first item
+				k, v := b.Cursor().First()
+				if k == nil && v == nil {
+					return nil
+				}
 
-			buf := bytes.NewReader(v)
-			dec := gob.NewDecoder(buf)
+				buf := bytes.NewReader(v)
+				dec := gob.NewDecoder(buf)
 
-			item := &Item{}
-			err = dec.Decode(item)
-			if err != nil {
-				c.rollback(err, tx)
-				continue
-			}
+				it := &Item{}
+				err := dec.Decode(it)
+				if err != nil {
+					return err
+				}
 
-			err = inQb.Put(utils.AsBytes(item.ID()), v)
-			if err != nil {
-				c.rollback(err, tx)
-				continue
-			}
+				err = inQb.Put(utils.AsBytes(it.ID()), v)
+				if err != nil {
+					return err
+				}
+
+				// delete key from the PushBucket
+				err = b.Delete(k)
+				if err != nil {
+					return err
+				}
+
+				item = it
+				return nil
+			})
 
-			// delete key from the PushBucket
-			err = b.Delete(k)
 			if err != nil {
-				c.rollback(err, tx)
+				c.log.Error("transaction failed, job will be read on the next attempt", "error", err)
 				continue
 			}
 
-			err = tx.Commit()
-			if err != nil {
-				c.rollback(err, tx)
+			if item == nil {
 				continue
 			}
 
 			// attach pointer to the DB
-			item.attachDB(c.db, c.active, c.delayed)
+			item.attachDB(c.db, c.bucket, c.active, c.delayed)
 			// as the last step, after commit, put the item into the PQ
 			c.pq.Insert(item)
 		}
@@ -95,63 +95,53 @@ func (c *consumer) delayedJobsListener() {
 			c.log.Debug("boltdb listener stopped")
 			return
 		case <-tt.C:
-			tx, err := c.db.Begin(true)
-			if err != nil {
-				c.log.Error("failed to begin writable transaction, job will be read on the next attempt", "error", err)
-				continue
-			}
-
-			delayB := tx.Bucket(utils.AsBytes(DelayBucket))
-			inQb := tx.Bucket(utils.AsBytes(InQueueBucket))
-
-			cursor := delayB.Cursor()
-			endDate := utils.AsBytes(time.Now().UTC().Format(time.RFC3339))
-
-			for k, v := cursor.Seek(startDate); k != nil && bytes.Compare(k, endDate) <= 0; k, v = cursor.Next() {
-				buf := bytes.NewReader(v)
-				dec := gob.NewDecoder(buf)
-
-				item := &Item{}
-				err = dec.Decode(item)
-				if err != nil {
-					c.rollback(err, tx)
-					continue
+			var items []*Item
+			err = c.db.Update(func(tx *bolt.Tx) error {
+				delayB := bucket(tx, c.bucket, DelayBucket)
+				inQb := bucket(tx, c.bucket, InQueueBucket)
+
+				cursor := delayB.Cursor()
+				// keys are prefixed with the time, so all keys for the current second are included
+				endDate := append(utils.AsBytes(time.Now().UTC().Format(time.RFC3339)), 0xff)
+
+				for k, v := cursor.Seek(startDate); k != nil && bytes.Compare(k, endDate) <= 0; k, v = cursor.Seek(startDate) {
+					buf := bytes.NewReader(v)
+					dec := gob.NewDecoder(buf)
+
+					item := &Item{}
+					errD := dec.Decode(item)
+					if errD != nil {
+						return errD
+					}
+
+					errD = inQb.Put(utils.AsBytes(item.ID()), v)
+					if errD != nil {
+						return errD
+					}
+
+					// delete key from the DelayBucket
+					errD = cursor.Delete()
+					if errD != nil {
+						return errD
+					}
+
+					items = append(items, item)
 				}
 
-				err = inQb.Put(utils.AsBytes(item.ID()), v)
-				if err != nil {
-					c.rollback(err, tx)
-					continue
-				}
+				return nil
+			})
 
-				// delete key from the PushBucket
-				err = delayB.Delete(k)
-				if err != nil {
-					c.rollback(err, tx)
-					continue
-				}
+			if err != nil {
+				c.log.Error("transaction failed, delayed jobs will be read on the next attempt", "error", err)
+				continue
+			}
 
+			for i := 0; i < len(items); i++ {
 				// attach pointer to the DB
-				item.attachDB(c.db, c.active, c.delayed)
+				items[i].attachDB(c.db, c.bucket, c.active, c.delayed)
 				// as the last step, after commit, put the item into the PQ
-				c.pq.Insert(item)
-			}
-
-			err = tx.Commit()
-			if err != nil {
-				c.rollback(err, tx)
-				continue
+				c.pq.Insert(items[i])
 			}
 		}
 	}
 }
-
-func (c *consumer) rollback(err error, tx *bolt.Tx) {
-	errR := tx.Rollback()
-	if errR != nil {
-		c.log.Error("transaction commit error, rollback failed", "error", err, "rollback error", errR)
-		return
-	}
-
-	c.log.Error("transaction commit error, rollback succeed", "error", err)
-}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +19,10 @@ import (
 
 const (
 	RootPluginName string = "kv"
+	// tagsSuffix is the suffix of the tags index bucket name
+	tagsSuffix string = ".tags"
+	// keyTagsSuffix is the suffix of the bucket with the tags of every tagged key
+	keyTagsSuffix string = ".key_tags"
 )
 
 type Driver struct {
@@ -29,6 +34,11 @@ type Driver struct {
 	log    logger.Logger
 	cfg    *Config
 
+	// tagsBucket contains the nested bucket with the keys for every tag
+	tagsBucket []byte
+	// keyTagsBucket contains the tags of every tagged key, used to update the tags index on overwrite and delete
+	keyTagsBucket []byte
+
 	// gc contains keys with timeouts
 	gc sync.Map
 	// default timeout for cache cleanup is 1 minute
@@ -59,6 +69,8 @@ func NewBoltDBDriver(log logger.Logger, key string, cfgPlugin config.Configurer)
 	d.cfg.InitDefaults()
 
 	d.bucket = []byte(d.cfg.bucket)
+	d.tagsBucket = []byte(d.cfg.bucket + tagsSuffix)
+	d.keyTagsBucket = []byte(d.cfg.bucket + keyTagsSuffix)
 	d.timeout = time.Duration(d.cfg.Interval) * time.Second
 	d.gc = sync.Map{}
 
@@ -273,6 +285,11 @@ func (d *Driver) Set(items ...*kvv1.Item) error {
 			return errors.E(op, err)
 		}
 
+		err = d.tag(tx, items[i])
+		if err != nil {
+			return errors.E(op, err)
+		}
+
 		// if there are no errors, and TTL > 0,  we put the key with timeout to the hashmap, for future check
 		// we do not need mutex here, since we use sync.Map
 		if items[i].Timeout != "" {
@@ -332,6 +349,11 @@ func (d *Driver) Delete(keys ...string) error {
 		if err != nil {
 			return errors.E(op, err)
 		}
+
+		err = d.untag(tx, key)
+		if err != nil {
+			return errors.E(op, err)
+		}
 	}
 
 	return nil
@@ -382,6 +404,44 @@ func (d *Driver) TTL(keys ...string) (map[string]string, error) {
 	return m, nil
 }
 
+// Tags returns the tags of the tagged keys
+func (d *Driver) Tags(keys ...string) (map[string][]string, error) {
+	const op = errors.Op("boltdb_driver_tags")
+	if keys == nil {
+		return nil, errors.E(op, errors.NoKeys)
+	}
+
+	m := make(map[string][]string, len(keys))
+	err := d.DB.View(func(tx *bolt.Tx) error {
+		kb := tx.Bucket(d.keyTagsBucket)
+		if kb == nil {
+			return nil
+		}
+
+		for i := range keys {
+			val := kb.Get([]byte(keys[i]))
+			if val == nil {
+				continue
+			}
+
+			var tags []string
+			err := gob.NewDecoder(bytes.NewReader(val)).Decode(&tags)
+			if err != nil {
+				return err
+			}
+
+			m[keys[i]] = tags
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return m, nil
+}
+
 func (d *Driver) Clear() error {
 	err := d.DB.Update(func(tx *bolt.Tx) error {
 		err := tx.DeleteBucket(d.bucket)
@@ -396,6 +456,18 @@ func (d *Driver) Clear() error {
 			return err
 		}
 
+		err = tx.DeleteBucket(d.tagsBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			d.log.Error("boltdb delete tags bucket", "error", err)
+			return err
+		}
+
+		err = tx.DeleteBucket(d.keyTagsBucket)
+		if err != nil && err != bolt.ErrBucketNotFound {
+			d.log.Error("boltdb delete key tags bucket", "error", err)
+			return err
+		}
+
 		return nil
 	})
 
@@ -449,6 +521,11 @@ func (d *Driver) startGCLoop() { //nolint:gocognit
 							if err != nil {
 								return errors.E(op, err)
 							}
+
+							err = d.untag(tx, k)
+							if err != nil {
+								return errors.E(op, err)
+							}
 							return nil
 						})
 						if err != nil {
@@ -470,3 +547,398 @@ func (d *Driver) startGCLoop() { //nolint:gocognit
 		}
 	}()
 }
+
+// Incr increments the integer value of the key in the single update transaction
+func (d *Driver) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("boltdb_driver_incr")
+	return d.incr(op, key, delta, timeout)
+}
+
+// Decr decrements the integer value of the key, see Incr
+func (d *Driver) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("boltdb_driver_decr")
+	return d.incr(op, key, -delta, timeout)
+}
+
+func (d *Driver) incr(op errors.Op, key string, delta int64, timeout string) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, errors.E(op, errors.EmptyKey)
+	}
+
+	if timeout != "" {
+		_, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return 0, errors.E(op, err)
+		}
+	}
+
+	var v int64
+	err := d.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(d.bucket)
+		if b == nil {
+			return errors.E(op, errors.NoSuchBucket)
+		}
+
+		// expired, but not yet removed by the GC key is started from the delta with the new TTL
+		if val := b.Get([]byte(key)); val != nil && !d.expired(key) {
+			var out []byte
+			err := gob.NewDecoder(bytes.NewReader(val)).Decode(&out)
+			if err != nil {
+				return err
+			}
+
+			v, err = strconv.ParseInt(utils.AsString(out), 10, 64)
+			if err != nil {
+				return errors.Errorf("value is not an integer: %s", key)
+			}
+
+			// keep the existing TTL
+			if tm, ok := d.gc.Load(key); ok {
+				timeout = tm.(string)
+			}
+		}
+
+		v += delta
+		return d.put(b, key, utils.AsBytes(strconv.FormatInt(v, 10)), timeout)
+	})
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+// SetNX puts the item only if the key doesn't exist or is expired, but not yet removed by the GC
+func (d *Driver) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("boltdb_driver_setnx")
+	if item == nil {
+		return false, errors.E(op, errors.EmptyItem)
+	}
+
+	if strings.TrimSpace(item.Key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	if item.Timeout != "" {
+		_, err := time.Parse(time.RFC3339, item.Timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+	}
+
+	applied := false
+	err := d.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(d.bucket)
+		if b == nil {
+			return errors.E(op, errors.NoSuchBucket)
+		}
+
+		if b.Get([]byte(item.Key)) != nil && !d.expired(item.Key) {
+			return nil
+		}
+
+		err := d.put(b, item.Key, item.Value, item.Timeout)
+		if err != nil {
+			return err
+		}
+
+		err = d.tag(tx, item)
+		if err != nil {
+			return err
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return applied, nil
+}
+
+// CompareAndSwap replaces the value in the single update transaction
+func (d *Driver) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("boltdb_driver_compare_and_swap")
+	if strings.TrimSpace(key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	if timeout != "" {
+		_, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+	}
+
+	applied := false
+	err := d.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(d.bucket)
+		if b == nil {
+			return errors.E(op, errors.NoSuchBucket)
+		}
+
+		val := b.Get([]byte(key))
+		if val == nil || d.expired(key) {
+			return nil
+		}
+
+		var current []byte
+		err := gob.NewDecoder(bytes.NewReader(val)).Decode(&current)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(current, oldValue) {
+			return nil
+		}
+
+		err = d.put(b, key, newValue, timeout)
+		if err != nil {
+			return err
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return applied, nil
+}
+
+// put stores the gob encoded value and replaces the key TTL
+func (d *Driver) put(b *bolt.Bucket, key string, value []byte, timeout string) error {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&value)
+	if err != nil {
+		return err
+	}
+
+	err = b.Put([]byte(key), buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if timeout == "" {
+		d.gc.Delete(key)
+		return nil
+	}
+
+	d.gc.Store(key, timeout)
+	return nil
+}
+
+// expired checks the key TTL, expired keys are removed by the GC loop with the interval
+func (d *Driver) expired(key string) bool {
+	tm, ok := d.gc.Load(key)
+	if !ok {
+		return false
+	}
+
+	t, err := time.Parse(time.RFC3339, tm.(string))
+	if err != nil {
+		return false
+	}
+
+	return time.Now().After(t)
+}
+
+// Keys lists the keys in the bucket order, the cursor is the last key of the page. Expired, but not yet removed by
+// the GC keys are skipped.
+func (d *Driver) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	const op = errors.Op("boltdb_driver_keys")
+	if limit <= 0 {
+		return nil, "", errors.E(op, errors.Str("limit should be positive"))
+	}
+
+	keys := make([]string, 0, limit)
+	next := ""
+	err := d.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(d.bucket)
+		if b == nil {
+			return errors.E(op, errors.NoSuchBucket)
+		}
+
+		c := b.Cursor()
+		pref := []byte(prefix)
+
+		start := pref
+		if cursor != "" {
+			start = []byte(cursor)
+		}
+
+		for k, _ := c.Seek(start); k != nil && bytes.HasPrefix(k, pref); k, _ = c.Next() {
+			if cursor != "" && string(k) == cursor {
+				continue
+			}
+
+			if d.expired(string(k)) {
+				continue
+			}
+
+			if len(keys) == limit {
+				// there are more keys
+				next = keys[len(keys)-1]
+				return nil
+			}
+
+			keys = append(keys, string(k))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", errors.E(op, err)
+	}
+
+	return keys, next, nil
+}
+
+// InvalidateTags deletes the tagged keys and the tags from the index in the single update transaction
+func (d *Driver) InvalidateTags(tags ...string) error {
+	const op = errors.Op("boltdb_driver_invalidate_tags")
+	if tags == nil {
+		return errors.E(op, errors.Str("no tags provided"))
+	}
+
+	var keys []string
+	err := d.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(d.bucket)
+		if b == nil {
+			return errors.E(op, errors.NoSuchBucket)
+		}
+
+		tb := tx.Bucket(d.tagsBucket)
+		if tb == nil {
+			return nil
+		}
+
+		for i := range tags {
+			nb := tb.Bucket([]byte(tags[i]))
+			if nb == nil {
+				continue
+			}
+
+			// the bucket can't be modified while iterating
+			var tagged []string
+			err := nb.ForEach(func(k, _ []byte) error {
+				tagged = append(tagged, string(k))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			// the key is removed from all its tags, empty tags are removed from the index
+			for _, k := range tagged {
+				err = b.Delete([]byte(k))
+				if err != nil {
+					return err
+				}
+
+				err = d.untag(tx, k)
+				if err != nil {
+					return err
+				}
+			}
+
+			keys = append(keys, tagged...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	for i := range keys {
+		d.gc.Delete(keys[i])
+	}
+
+	return nil
+}
+
+// tag replaces the item key tags in the tags index
+func (d *Driver) tag(tx *bolt.Tx, item *kvv1.Item) error {
+	err := d.untag(tx, item.Key)
+	if err != nil {
+		return err
+	}
+
+	if len(item.Tags) == 0 {
+		return nil
+	}
+
+	tb, err := tx.CreateBucketIfNotExists(d.tagsBucket)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range item.Tags {
+		nb, err := tb.CreateBucketIfNotExists([]byte(t))
+		if err != nil {
+			return err
+		}
+
+		err = nb.Put([]byte(item.Key), []byte{})
+		if err != nil {
+			return err
+		}
+	}
+
+	kb, err := tx.CreateBucketIfNotExists(d.keyTagsBucket)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	err = gob.NewEncoder(buf).Encode(item.Tags)
+	if err != nil {
+		return err
+	}
+
+	return kb.Put([]byte(item.Key), buf.Bytes())
+}
+
+// untag removes the key from the tags index, empty tags are removed
+func (d *Driver) untag(tx *bolt.Tx, key string) error {
+	kb := tx.Bucket(d.keyTagsBucket)
+	if kb == nil {
+		return nil
+	}
+
+	val := kb.Get([]byte(key))
+	if val == nil {
+		return nil
+	}
+
+	var tags []string
+	err := gob.NewDecoder(bytes.NewReader(val)).Decode(&tags)
+	if err != nil {
+		return err
+	}
+
+	tb := tx.Bucket(d.tagsBucket)
+	for i := 0; tb != nil && i < len(tags); i++ {
+		nb := tb.Bucket([]byte(tags[i]))
+		if nb == nil {
+			continue
+		}
+
+		err = nb.Delete([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		if k, _ := nb.Cursor().First(); k == nil {
+			err = tb.DeleteBucket([]byte(tags[i]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return kb.Delete([]byte(key))
+}
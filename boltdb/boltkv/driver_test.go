@@ -0,0 +1,128 @@
+package boltkv
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// newTestDriver creates the driver without the GC loop, so the expired keys are never removed
+func newTestDriver(t *testing.T) *Driver {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "rr.db"), 0o600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	d := &Driver{
+		DB:            db,
+		bucket:        []byte("rr"),
+		tagsBucket:    []byte("rr" + tagsSuffix),
+		keyTagsBucket: []byte("rr" + keyTagsSuffix),
+		log:           logger.NewZapAdapter(zap.NewNop()),
+		cfg:           &Config{},
+		stop:          make(chan struct{}),
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, errC := tx.CreateBucketIfNotExists(d.bucket)
+		return errC
+	})
+	require.NoError(t, err)
+
+	return d
+}
+
+func TestIncrExpired(t *testing.T) {
+	d := newTestDriver(t)
+
+	past := time.Now().Add(-time.Second).Format(time.RFC3339)
+	require.NoError(t, d.Set(&kvv1.Item{Key: "counter", Value: []byte("10"), Timeout: past}))
+
+	// expired, but not removed key is started from the delta with the new TTL
+	tt := time.Now().Add(time.Minute).Format(time.RFC3339)
+	v, err := d.Incr("counter", 2, tt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	ttl, err := d.TTL("counter")
+	require.NoError(t, err)
+	assert.Equal(t, tt, ttl["counter"])
+
+	// live key keeps the TTL
+	v, err = d.Decr("counter", 1, time.Now().Add(time.Hour).Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	ttl, err = d.TTL("counter")
+	require.NoError(t, err)
+	assert.Equal(t, tt, ttl["counter"])
+
+	out, err := d.MGet("counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), out["counter"])
+}
+
+func TestTagsIndex(t *testing.T) {
+	d := newTestDriver(t)
+
+	require.NoError(t, d.Set(
+		&kvv1.Item{Key: "a", Value: []byte("a"), Tags: []string{"t1", "t2"}},
+		&kvv1.Item{Key: "b", Value: []byte("b"), Tags: []string{"t1"}},
+		&kvv1.Item{Key: "c", Value: []byte("c"), Tags: []string{"t2"}},
+	))
+
+	// re-set without the tags and delete remove the keys from the index
+	require.NoError(t, d.Set(&kvv1.Item{Key: "a", Value: []byte("a2")}))
+	require.NoError(t, d.Delete("c"))
+	assert.Equal(t, []string{"t1"}, tagNames(t, d))
+
+	tags, err := d.Tags("a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"b": {"t1"}}, tags)
+
+	require.NoError(t, d.InvalidateTags("t1", "t2"))
+	m, err := d.Has("a", "b")
+	require.NoError(t, err)
+	assert.True(t, m["a"])
+	assert.False(t, m["b"])
+	assert.Empty(t, tagNames(t, d))
+
+	// counters and CAS keep the tags
+	require.NoError(t, d.Set(&kvv1.Item{Key: "n", Value: []byte("1"), Tags: []string{"t3"}}))
+	_, err = d.Incr("n", 1, "")
+	require.NoError(t, err)
+	ok, err := d.CompareAndSwap("n", []byte("2"), []byte("3"), "")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, d.InvalidateTags("t3"))
+	m, err = d.Has("n")
+	require.NoError(t, err)
+	assert.False(t, m["n"])
+}
+
+// tagNames returns the tags in the index
+func tagNames(t *testing.T, d *Driver) []string {
+	var tags []string
+	err := d.DB.View(func(tx *bolt.Tx) error {
+		tb := tx.Bucket(d.tagsBucket)
+		if tb == nil {
+			return nil
+		}
+
+		return tb.ForEach(func(k, _ []byte) error {
+			tags = append(tags, string(k))
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	return tags
+}
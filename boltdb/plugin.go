@@ -37,6 +37,11 @@ func (p *Plugin) Name() string {
 // Available interface implementation
 func (p *Plugin) Available() {}
 
+// RPC returns associated rpc service.
+func (p *Plugin) RPC() interface{} {
+	return &rpc{p: p}
+}
+
 func (p *Plugin) KvFromConfig(key string) (kv.Storage, error) {
 	const op = errors.Op("boltdb_plugin_provide")
 	st, err := boltkv.NewBoltDBDriver(p.log, key, p.cfg)
@@ -0,0 +1,22 @@
+package boltdb
+
+import (
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb/boltjobs"
+)
+
+type rpc struct {
+	p *Plugin
+}
+
+// Compact copies live data of the jobs database file into a fresh file and replaces the old one
+func (r *rpc) Compact(file string, ok *bool) error {
+	const op = errors.Op("boltdb_rpc_compact")
+	err := boltjobs.Compact(file)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	*ok = true
+	return nil
+}
@@ -5,3 +5,12 @@ import "github.com/spiral/roadrunner-plugins/v2/api/pubsub"
 type Broadcaster interface {
 	GetDriver(key string) (pubsub.SubReader, error)
 }
+
+// Publisher publishes messages into all initialized brokers, implemented by the broadcast plugin
+type Publisher interface {
+	Broadcaster
+	Publish(m *pubsub.Message) error
+	PublishAsync(m *pubsub.Message)
+	// PublishTo publishes the message only into the broker initialized by the GetDriver
+	PublishTo(key string, m *pubsub.Message) error
+}
@@ -30,6 +30,8 @@ type Plugin struct {
 	// and able to receive a payload
 	publishers   map[string]pubsub.PubSub
 	constructors map[string]pubsub.Constructor
+	// brokers share the publisher driver between the GetDriver callers, key is the same as in the publishers
+	brokers map[string]*broker
 }
 
 func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
@@ -46,6 +48,7 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
 
 	p.publishers = make(map[string]pubsub.PubSub)
 	p.constructors = make(map[string]pubsub.Constructor)
+	p.brokers = make(map[string]*broker)
 
 	p.log = log
 	p.cfgPlugin = cfg
@@ -57,6 +60,10 @@ func (p *Plugin) Serve() chan error {
 }
 
 func (p *Plugin) Stop() error {
+	for k := range p.brokers {
+		p.brokers[k].stop()
+	}
+
 	for k := range p.publishers {
 		p.publishers[k].Stop()
 	}
@@ -120,8 +127,31 @@ func (p *Plugin) PublishAsync(m *pubsub.Message) {
 	}()
 }
 
+// PublishTo publishes the message only into the broker with the provided key, the broker should be initialized by GetDriver
+func (p *Plugin) PublishTo(key string, m *pubsub.Message) error {
+	const op = errors.Op("broadcast_plugin_publish_to")
+	p.Lock()
+	defer p.Unlock()
+
+	ps, ok := p.publishers[fmt.Sprintf("%s.%s.%s", PluginName, key, conf)]
+	if !ok {
+		return errors.E(op, errors.Errorf("broker is not initialized: %s", key))
+	}
+
+	err := ps.Publish(m)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// GetDriver returns the reader of the broker messages. The broker driver is initialized on the first call and shared
+// by all readers, every reader gets only the messages of its own subscriptions.
 func (p *Plugin) GetDriver(key string) (pubsub.SubReader, error) {
 	const op = errors.Op("broadcast_plugin_get_driver")
+	p.Lock()
+	defer p.Unlock()
 
 	// choose a driver
 	if val, ok := p.cfg.Data[key]; ok {
@@ -140,6 +170,11 @@ func (p *Plugin) GetDriver(key string) (pubsub.SubReader, error) {
 		// config key for the particular sub-driver broadcast.memcached.config
 		configKey := fmt.Sprintf("%s.%s.%s", PluginName, key, conf)
 
+		// already initialized
+		if b, ok := p.brokers[configKey]; ok {
+			return b.reader(), nil
+		}
+
 		drName := val.(map[string]interface{})[driver]
 
 		// driver name should be a string
@@ -158,10 +193,10 @@ func (p *Plugin) GetDriver(key string) (pubsub.SubReader, error) {
 				}
 
 				// save the initialized publisher channel
-				// for the in-memory, register new publishers
 				p.publishers[configKey] = ps
+				p.brokers[configKey] = newBroker(ps, p.log)
 
-				return ps, nil
+				return p.brokers[configKey].reader(), nil
 			case p.cfgPlugin.Has(key):
 				// try global driver section after local
 				ps, err := p.constructors[drStr].PubSubFromConfig(key)
@@ -170,10 +205,10 @@ func (p *Plugin) GetDriver(key string) (pubsub.SubReader, error) {
 				}
 
 				// save the initialized publisher channel
-				// for the in-memory, register new publishers
 				p.publishers[configKey] = ps
+				p.brokers[configKey] = newBroker(ps, p.log)
 
-				return ps, nil
+				return p.brokers[configKey].reader(), nil
 			default:
 				p.log.Error("can't find local or global configuration, this section will be skipped", "local: ", configKey, "global: ", key)
 			}
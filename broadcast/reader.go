@@ -0,0 +1,183 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner/v2/bst"
+)
+
+// size of the reader messages buffer
+const readerBuffer int = 100
+
+// broker owns the driver shared by all GetDriver callers. Messages are read from the driver once and dispatched
+// to the readers subscribed to the message topic, so the readers don't steal the messages from each other.
+type broker struct {
+	driver pubsub.PubSub
+	log    logger.Logger
+
+	mu      sync.RWMutex
+	readers map[*reader]struct{}
+
+	cancel context.CancelFunc
+	// closed when the dispatching is stopped, err is the driver reading error
+	done chan struct{}
+	err  error
+}
+
+func newBroker(driver pubsub.PubSub, log logger.Logger) *broker {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &broker{
+		driver:  driver,
+		log:     log,
+		readers: make(map[*reader]struct{}),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go b.dispatch(ctx)
+
+	return b
+}
+
+func (b *broker) dispatch(ctx context.Context) {
+	defer close(b.done)
+
+	for {
+		msg, err := b.driver.Next(ctx)
+		if err != nil {
+			if !errors.Is(errors.TimeOut, err) {
+				b.log.Error("broker messages reading failed", "error", err)
+				b.err = err
+			}
+			return
+		}
+
+		if msg == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		b.mu.RLock()
+		for r := range b.readers {
+			r.deliver(msg)
+		}
+		b.mu.RUnlock()
+	}
+}
+
+// reader returns the new reader of the broker messages
+func (b *broker) reader() *reader {
+	r := &reader{
+		b:       b,
+		storage: bst.NewBST(),
+		ch:      make(chan *pubsub.Message, readerBuffer),
+		stop:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.readers[r] = struct{}{}
+	b.mu.Unlock()
+
+	return r
+}
+
+// stop stops the dispatching, the driver is stopped by the plugin
+func (b *broker) stop() {
+	b.cancel()
+	<-b.done
+}
+
+// reader is the pubsub.SubReader returned by the GetDriver. Subscriptions are made on the shared driver and tracked
+// by the reader, so only the messages of the reader topics are returned by Next.
+type reader struct {
+	b *broker
+
+	mu      sync.RWMutex
+	storage bst.Storage
+
+	ch       chan *pubsub.Message
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (r *reader) Subscribe(connectionID string, topics ...string) error {
+	r.mu.Lock()
+	for i := 0; i < len(topics); i++ {
+		r.storage.Insert(connectionID, topics[i])
+	}
+	r.mu.Unlock()
+
+	return r.b.driver.Subscribe(connectionID, topics...)
+}
+
+func (r *reader) Unsubscribe(connectionID string, topics ...string) error {
+	r.mu.Lock()
+	for i := 0; i < len(topics); i++ {
+		r.storage.Remove(connectionID, topics[i])
+	}
+	r.mu.Unlock()
+
+	return r.b.driver.Unsubscribe(connectionID, topics...)
+}
+
+func (r *reader) Connections(topic string, res map[string]struct{}) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for id := range r.storage.Get(topic) {
+		res[id] = struct{}{}
+	}
+}
+
+// Stop stops the reader, the shared driver is stopped with the plugin
+func (r *reader) Stop() {
+	r.stopOnce.Do(func() {
+		// unblocks the dispatching to this reader
+		close(r.stop)
+
+		r.b.mu.Lock()
+		delete(r.b.readers, r)
+		r.b.mu.Unlock()
+	})
+}
+
+func (r *reader) Next(ctx context.Context) (*pubsub.Message, error) {
+	const op = errors.Op("broadcast_reader_next")
+	select {
+	case msg := <-r.ch:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, errors.E(op, errors.TimeOut, ctx.Err())
+	case <-r.stop:
+		return nil, errors.E(op, errors.TimeOut, errors.Str("reader is stopped"))
+	case <-r.b.done:
+		if r.b.err != nil {
+			return nil, errors.E(op, r.b.err)
+		}
+		return nil, errors.E(op, errors.TimeOut, errors.Str("broker is stopped"))
+	}
+}
+
+// deliver sends the message to the reader if it's subscribed to the message topic. The message is dropped if the reader
+// buffer is full, so the slow reader doesn't stall the other readers of the broker.
+func (r *reader) deliver(msg *pubsub.Message) {
+	r.mu.RLock()
+	ok := r.storage.Contains(msg.Topic)
+	r.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case r.ch <- msg:
+	default:
+		r.b.log.Warn("broker reader buffer is full, message dropped", "topic", msg.Topic)
+	}
+}
@@ -0,0 +1,109 @@
+package broadcast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory/memorypubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// next returns the next message of the reader, nil - no messages
+func next(r *reader) *pubsub.Message {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	msg, err := r.Next(ctx)
+	if err != nil {
+		return nil
+	}
+
+	return msg
+}
+
+func TestBrokerReaders(t *testing.T) {
+	log := logger.NewZapAdapter(zap.NewNop())
+	driver, err := memorypubsub.NewPubSubDriver(log, "")
+	require.NoError(t, err)
+
+	b := newBroker(driver, log)
+	defer b.stop()
+
+	// e.g. websockets and the tiered storage on the same in-memory broker
+	ws, tiered := b.reader(), b.reader()
+	require.NoError(t, ws.Subscribe("conn-1", "news"))
+	require.NoError(t, tiered.Subscribe("node-1", "invalidate"))
+
+	require.NoError(t, driver.Publish(&pubsub.Message{Topic: "news", Payload: []byte("1")}))
+	require.NoError(t, driver.Publish(&pubsub.Message{Topic: "invalidate", Payload: []byte("2")}))
+
+	msg := next(ws)
+	require.NotNil(t, msg)
+	assert.Equal(t, "news", msg.Topic)
+	assert.Nil(t, next(ws))
+
+	msg = next(tiered)
+	require.NotNil(t, msg)
+	assert.Equal(t, "invalidate", msg.Topic)
+
+	// only own connections are returned
+	res := make(map[string]struct{})
+	ws.Connections("invalidate", res)
+	assert.Empty(t, res)
+
+	// stopped reader doesn't block the others
+	tiered.Stop()
+	require.NoError(t, driver.Publish(&pubsub.Message{Topic: "invalidate"}))
+	require.NoError(t, driver.Publish(&pubsub.Message{Topic: "news"}))
+	msg = next(ws)
+	require.NotNil(t, msg)
+	assert.Equal(t, "news", msg.Topic)
+
+	_, err = tiered.Next(context.Background())
+	assert.Error(t, err)
+}
+
+func TestBrokerSlowReader(t *testing.T) {
+	log := logger.NewZapAdapter(zap.NewNop())
+	driver, err := memorypubsub.NewPubSubDriver(log, "")
+	require.NoError(t, err)
+
+	b := newBroker(driver, log)
+	defer b.stop()
+
+	slow, fast := b.reader(), b.reader()
+	require.NoError(t, slow.Subscribe("conn-1", "news"))
+	require.NoError(t, fast.Subscribe("conn-2", "news"))
+
+	// the slow reader never reads, its buffer is overflowed
+	n := readerBuffer * 2
+	go func() {
+		for i := 0; i < n; i++ {
+			_ = driver.Publish(&pubsub.Message{Topic: "news"})
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		require.NotNil(t, next(fast), "message: %d", i)
+	}
+
+	// the overflowed reader is stopped without waiting for the dispatching
+	stopped := make(chan struct{})
+	go func() {
+		slow.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 5):
+		t.Fatal("reader is not stopped")
+	}
+
+	assert.Len(t, slow.ch, readerBuffer)
+}
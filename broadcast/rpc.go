@@ -1,6 +1,8 @@
 package broadcast
 
 import (
+	"strings"
+
 	"github.com/spiral/errors"
 	websocketsv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/websockets/v1beta"
 	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
@@ -35,6 +37,12 @@ func (r *rpc) Publish(in *websocketsv1.Request, out *websocketsv1.Response) erro
 				continue
 			}
 
+			if strings.HasPrefix(in.GetMessages()[i].GetTopics()[j], pubsub.ReservedTopicPrefix) {
+				r.log.Warn("message with reserved topic, skipping", "topic", in.GetMessages()[i].GetTopics()[j])
+				// topics with the reserved prefix are published only by RoadRunner
+				continue
+			}
+
 			tmp := &pubsub.Message{
 				Topic:   in.GetMessages()[i].GetTopics()[j],
 				Payload: in.GetMessages()[i].GetPayload(),
@@ -73,6 +81,12 @@ func (r *rpc) PublishAsync(in *websocketsv1.Request, out *websocketsv1.Response)
 				continue
 			}
 
+			if strings.HasPrefix(in.GetMessages()[i].GetTopics()[j], pubsub.ReservedTopicPrefix) {
+				r.log.Warn("message with reserved topic, skipping", "topic", in.GetMessages()[i].GetTopics()[j])
+				// topics with the reserved prefix are published only by RoadRunner
+				continue
+			}
+
 			tmp := &pubsub.Message{
 				Topic:   in.GetMessages()[i].GetTopics()[j],
 				Payload: in.GetMessages()[i].GetPayload(),
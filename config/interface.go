@@ -27,3 +27,9 @@ type Configurer interface {
 	// GetCommonConfig returns General section. Read-only
 	GetCommonConfig() *General
 }
+
+// Reloader is implemented by the configuration providers which are able to re-read the configuration source
+type Reloader interface {
+	// Reload re-reads the configuration
+	Reload() error
+}
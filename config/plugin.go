@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 	"github.com/spiral/errors"
@@ -13,6 +14,8 @@ import (
 const PluginName string = "config"
 
 type Viper struct {
+	// guards the viper instance replaced by the Reload
+	mu        sync.RWMutex
 	viper     *viper.Viper
 	Path      string
 	Prefix    string
@@ -28,36 +31,66 @@ type Viper struct {
 // Init config provider.
 func (v *Viper) Init() error {
 	const op = errors.Op("config_plugin_init")
-	v.viper = viper.New()
+	vp, err := v.read(op)
+	if err != nil {
+		return err
+	}
+
+	v.viper = vp
+	return nil
+}
+
+// Reload re-reads the configuration file. Values already unmarshalled by the plugins are not changed,
+// plugins should unmarshal the configuration again to apply the changes.
+func (v *Viper) Reload() error {
+	const op = errors.Op("config_plugin_reload")
+	if v.ReadInCfg != nil && v.Type != "" {
+		return errors.E(op, errors.Str("configuration provided as bytes can't be reloaded"))
+	}
+
+	vp, err := v.read(op)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.viper = vp
+	v.mu.Unlock()
+
+	return nil
+}
+
+func (v *Viper) read(op errors.Op) (*viper.Viper, error) {
+	vp := viper.New()
 	// If user provided []byte data with config, read it and ignore Path and Prefix
 	if v.ReadInCfg != nil && v.Type != "" {
-		v.viper.SetConfigType("yaml")
-		return v.viper.ReadConfig(bytes.NewBuffer(v.ReadInCfg))
+		vp.SetConfigType("yaml")
+		return vp, vp.ReadConfig(bytes.NewBuffer(v.ReadInCfg))
 	}
 
 	// read in environment variables that match
-	v.viper.AutomaticEnv()
+	vp.AutomaticEnv()
 	if v.Prefix == "" {
-		return errors.E(op, errors.Str("prefix should be set"))
+		return nil, errors.E(op, errors.Str("prefix should be set"))
 	}
 
-	v.viper.SetEnvPrefix(v.Prefix)
+	vp.SetEnvPrefix(v.Prefix)
 	if v.Path == "" {
-		return errors.E(op, errors.Str("path should be set"))
+		return nil, errors.E(op, errors.Str("path should be set"))
 	}
 
-	v.viper.SetConfigFile(v.Path)
-	v.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	vp.SetConfigFile(v.Path)
+	vp.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	err := v.viper.ReadInConfig()
+	err := vp.ReadInConfig()
 	if err != nil {
-		return errors.E(op, err)
+		return nil, errors.E(op, err)
 	}
 
 	// automatically inject ENV variables using ${ENV} pattern
-	for _, key := range v.viper.AllKeys() {
-		val := v.viper.Get(key)
-		v.viper.Set(key, parseEnv(val))
+	for _, key := range vp.AllKeys() {
+		val := vp.Get(key)
+		vp.Set(key, parseEnv(val))
 	}
 
 	// override config Flags
@@ -65,18 +98,21 @@ func (v *Viper) Init() error {
 		for _, f := range v.Flags {
 			key, val, err := parseFlag(f)
 			if err != nil {
-				return errors.E(op, err)
+				return nil, errors.E(op, err)
 			}
 
-			v.viper.Set(key, val)
+			vp.Set(key, val)
 		}
 	}
 
-	return nil
+	return vp, nil
 }
 
 // Overwrite overwrites existing config with provided values
 func (v *Viper) Overwrite(values map[string]interface{}) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
 	if len(values) != 0 {
 		for key, value := range values {
 			v.viper.Set(key, value)
@@ -89,6 +125,8 @@ func (v *Viper) Overwrite(values map[string]interface{}) error {
 // UnmarshalKey reads configuration section into configuration object.
 func (v *Viper) UnmarshalKey(name string, out interface{}) error {
 	const op = errors.Op("config_plugin_unmarshal_key")
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	err := v.viper.UnmarshalKey(name, &out)
 	if err != nil {
 		return errors.E(op, err)
@@ -98,6 +136,8 @@ func (v *Viper) UnmarshalKey(name string, out interface{}) error {
 
 func (v *Viper) Unmarshal(out interface{}) error {
 	const op = errors.Op("config_plugin_unmarshal")
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	err := v.viper.Unmarshal(&out)
 	if err != nil {
 		return errors.E(op, err)
@@ -107,11 +147,15 @@ func (v *Viper) Unmarshal(out interface{}) error {
 
 // Get raw config in a form of config section.
 func (v *Viper) Get(name string) interface{} {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return v.viper.Get(name)
 }
 
 // Has checks if config section exists.
 func (v *Viper) Has(name string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return v.viper.IsSet(name)
 }
 
@@ -16,12 +16,15 @@ require (
 	github.com/emicklei/proto v1.9.1
 	github.com/fatih/color v1.13.0
 	github.com/go-redis/redis/v8 v8.11.4
+	github.com/go-sql-driver/mysql v1.6.0
 	github.com/gobwas/ws v1.1.0
 	github.com/gofiber/fiber/v2 v2.22.0
 	github.com/golang/mock v1.6.0
 	github.com/google/uuid v1.3.0
 	github.com/json-iterator/go v1.1.12
 	github.com/klauspost/compress v1.13.6
+	github.com/lib/pq v1.10.3
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/mholt/acmez v1.0.1
 	github.com/nats-io/nats.go v1.13.0
 	github.com/newrelic/go-agent/v3 v3.15.1
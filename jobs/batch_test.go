@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	stderr "errors"
+	"testing"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/jobstest"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeBatchPusher fails the jobs with the provided indexes
+type fakeBatchPusher struct {
+	jobstest.Consumer
+
+	fail map[int]error
+}
+
+func (f *fakeBatchPusher) PushBatch(ctx context.Context, j []*job.Job) error {
+	if len(f.fail) > 0 {
+		return &jobs.BatchError{Errors: f.fail}
+	}
+
+	for i := range j {
+		_ = f.Push(ctx, j[i])
+	}
+	return nil
+}
+
+func batchPlugin() *Plugin {
+	p := &Plugin{
+		cfg: &Config{Timeout: 1},
+		log: logger.NewZapAdapter(zap.NewNop()),
+		metrics: &metrics{
+			jobsOk:  utils.Uint64(0),
+			pushOk:  utils.Uint64(0),
+			jobsErr: utils.Uint64(0),
+			pushErr: utils.Uint64(0),
+		},
+	}
+
+	p.pipelines.Store("batch", &pipeline.Pipeline{pipelineName: "batch"})
+	p.pipelines.Store("single", &pipeline.Pipeline{pipelineName: "single"})
+	return p
+}
+
+func batchJobs(pipelines ...string) []*job.Job {
+	out := make([]*job.Job, len(pipelines))
+	for i := 0; i < len(pipelines); i++ {
+		out[i] = &job.Job{Ident: pipelines[i] + "-" + string(rune('a'+i)), Options: &job.Options{Pipeline: pipelines[i]}}
+	}
+
+	return out
+}
+
+func TestPushBatchReport(t *testing.T) {
+	p := batchPlugin()
+	bp := &fakeBatchPusher{fail: map[int]error{1: errors.Str("queue is full")}}
+	p.consumers.Store("batch", bp)
+	p.consumers.Store("single", &jobstest.Consumer{})
+
+	errs := p.PushBatchReport(batchJobs("batch", "batch", "missing", "single"))
+	require.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Error(t, errs[2])
+	assert.NoError(t, errs[3])
+}
+
+func TestPushBatchStop(t *testing.T) {
+	p := batchPlugin()
+	single := &jobstest.Consumer{}
+	p.consumers.Store("batch", &fakeBatchPusher{})
+	p.consumers.Store("single", single)
+
+	err := p.PushBatch(batchJobs("batch", "missing", "single"))
+	require.Error(t, err)
+	// jobs after the failed one are not pushed
+	assert.Len(t, single.Pushed(), 0)
+
+	// the first job is pushed, the rest are reported
+	var be *jobs.BatchError
+	require.ErrorAs(t, err, &be)
+	assert.Len(t, be.Errors, 2)
+	assert.NotContains(t, be.Errors, 0)
+
+	// nothing pushed - the first error
+	err = p.PushBatch(batchJobs("missing", "single"))
+	require.Error(t, err)
+	assert.False(t, stderr.As(err, &be))
+
+	errs := p.pushBatch(batchJobs("missing", "single", "single"), true)
+	assert.Error(t, errs[0])
+	assert.ErrorIs(t, errs[1], errNotPushed)
+	assert.ErrorIs(t, errs[2], errNotPushed)
+
+	require.NoError(t, p.PushBatch(batchJobs("batch", "single", "single")))
+	assert.Len(t, single.Pushed(), 2)
+}
+
+func TestPushBatchWrongIndexes(t *testing.T) {
+	p := batchPlugin()
+	p.consumers.Store("batch", &fakeBatchPusher{fail: map[int]error{5: errors.Str("queue is full")}})
+	p.consumers.Store("single", &jobstest.Consumer{})
+
+	// out of range index - the pushed jobs are unknown, whole group is failed
+	errs := p.PushBatchReport(batchJobs("batch", "batch", "single"))
+	require.Len(t, errs, 3)
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+}
@@ -34,6 +34,12 @@ type Config struct {
 
 	// Consuming specifies names of pipelines to be consumed on service start.
 	Consume []string `mapstructure:"consume"`
+
+	// Registry persists dynamically declared pipelines, nil - disabled
+	Registry *RegistryConfig `mapstructure:"registry"`
+
+	// Events configures the lifecycle events stream, nil - disabled
+	Events *EventsConfig `mapstructure:"events"`
 }
 
 func (c *Config) InitDefaults() {
@@ -58,5 +64,13 @@ func (c *Config) InitDefaults() {
 		c.Timeout = 60
 	}
 
+	if c.Registry != nil {
+		c.Registry.InitDefaults()
+	}
+
+	if c.Events != nil {
+		c.Events.InitDefaults()
+	}
+
 	c.Pool.InitDefaults()
 }
@@ -0,0 +1,240 @@
+package driverstest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	json "github.com/json-iterator/go"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
+)
+
+const (
+	jobName   string = "driverstest/job"
+	queueSize uint64 = 1000
+	stopID    string = "driverstest-stop"
+)
+
+// meta is the job context (see the drivers' Item.Context)
+type meta struct {
+	ID       string              `json:"id"`
+	Job      string              `json:"job"`
+	Headers  map[string][]string `json:"headers"`
+	Pipeline string              `json:"pipeline"`
+}
+
+// env is a single pipeline under the test
+type env struct {
+	t        *testing.T
+	cfg      *Config
+	name     string
+	pipe     *pipeline.Pipeline
+	consumer jobs.Consumer
+	queue    priorityqueue.Queue
+	items    chan priorityqueue.Item
+	stopped  bool
+	doneCh   chan struct{}
+}
+
+func newEnv(t *testing.T, c jobs.Constructor, cfg *Config, name string) *env {
+	pipe := &pipeline.Pipeline{}
+	if cfg.Options != nil {
+		for k, v := range cfg.Options(t, name) {
+			pipe.With(k, v)
+		}
+	}
+
+	pipe.With("name", name)
+	pipe.With("driver", cfg.Driver)
+
+	e := &env{
+		t:      t,
+		cfg:    cfg,
+		name:   name,
+		pipe:   pipe,
+		queue:  priorityqueue.NewBinHeap(queueSize),
+		items:  make(chan priorityqueue.Item, queueSize),
+		doneCh: make(chan struct{}),
+	}
+
+	consumer, err := c.ConsumerFromPipeline(pipe, e.queue)
+	if err != nil {
+		t.Fatalf("consumer from the pipeline: %v", err)
+	}
+	e.consumer = consumer
+
+	err = consumer.Register(context.Background(), pipe)
+	if err != nil {
+		t.Fatalf("register the pipeline: %v", err)
+	}
+
+	go e.extract()
+
+	t.Cleanup(func() {
+		e.stop()
+		// unblock the extractor
+		e.queue.Insert(stopItem{})
+		<-e.doneCh
+	})
+
+	return e
+}
+
+// extract moves the items from the priority queue into the channel, so they might be received with the timeout
+func (e *env) extract() {
+	defer close(e.doneCh)
+
+	for {
+		it := e.queue.ExtractMin()
+		if it.ID() == stopID {
+			return
+		}
+
+		e.items <- it
+	}
+}
+
+func (e *env) run() {
+	err := e.consumer.Run(context.Background(), e.pipe)
+	if err != nil {
+		e.t.Fatalf("run the pipeline: %v", err)
+	}
+}
+
+func (e *env) stop() {
+	if e.stopped {
+		return
+	}
+
+	e.stopped = true
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	err := e.consumer.Stop(ctx)
+	if err != nil {
+		e.t.Errorf("stop the pipeline: %v", err)
+	}
+}
+
+// job returns the new job for the pipeline
+func (e *env) job(priority, delay int64, headers map[string][]string) *job.Job {
+	id := e.name + "-" + uuid.NewString()
+	return &job.Job{
+		Job:     jobName,
+		Ident:   id,
+		Payload: payload(id),
+		Headers: headers,
+		Options: &job.Options{
+			Priority: priority,
+			Pipeline: e.name,
+			Delay:    delay,
+		},
+	}
+}
+
+// push pushes the new job and returns its ID
+func (e *env) push(priority, delay int64, headers map[string][]string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	j := e.job(priority, delay, headers)
+	err := e.consumer.Push(ctx, j)
+	if err != nil {
+		e.t.Fatalf("push the job: %v", err)
+	}
+
+	return j.Ident
+}
+
+// next waits for the next job
+func (e *env) next() (priorityqueue.Item, *meta) {
+	select {
+	case it := <-e.items:
+		ctx, err := it.Context()
+		if err != nil {
+			e.t.Fatalf("job context: %v", err)
+		}
+
+		m := &meta{}
+		err = json.Unmarshal(ctx, m)
+		if err != nil {
+			e.t.Fatalf("unmarshal the job context: %v", err)
+		}
+
+		return it, m
+	case <-time.After(e.cfg.Timeout):
+		e.t.Fatalf("no job received in %s", e.cfg.Timeout)
+		return nil, nil
+	}
+}
+
+// none checks that no job is delivered during the provided duration
+func (e *env) none(d time.Duration) {
+	select {
+	case it := <-e.items:
+		e.t.Fatalf("unexpected job received: %s", it.ID())
+	case <-time.After(d):
+	}
+}
+
+func (e *env) acknowledger(it priorityqueue.Item) jobs.Acknowledger {
+	a, ok := it.(jobs.Acknowledger)
+	if !ok {
+		e.t.Fatalf("job %s is not an Acknowledger", it.ID())
+	}
+
+	return a
+}
+
+func (e *env) ack(it priorityqueue.Item) {
+	err := e.acknowledger(it).Ack()
+	if err != nil {
+		e.t.Fatalf("ack the job %s: %v", it.ID(), err)
+	}
+}
+
+func (e *env) state() *jobs.State {
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	defer cancel()
+
+	st, err := e.consumer.State(ctx)
+	if err != nil {
+		e.t.Fatalf("pipeline state: %v", err)
+	}
+
+	return st
+}
+
+// eventually waits for the pipeline state condition
+func (e *env) eventually(what string, cond func(st *jobs.State) bool) {
+	deadline := time.Now().Add(e.cfg.Timeout)
+	for {
+		st := e.state()
+		if cond(st) {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			e.t.Fatalf("state condition is not met in %s: %s, state: %+v", e.cfg.Timeout, what, st)
+		}
+
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+func payload(id string) string {
+	return `{"id":"` + id + `"}`
+}
+
+// stopItem stops the extractor, extracted before any job
+type stopItem struct{}
+
+func (stopItem) ID() string               { return stopID }
+func (stopItem) Priority() int64          { return math.MinInt64 }
+func (stopItem) Body() []byte             { return nil }
+func (stopItem) Context() ([]byte, error) { return nil, nil }
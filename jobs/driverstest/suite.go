@@ -0,0 +1,371 @@
+// Package driverstest is the conformance suite for the jobs drivers. The suite works directly with the jobs.Constructor
+// and the priority queue, so it doesn't need the jobs plugin or the PHP workers:
+//
+//	func TestConformance(t *testing.T) {
+//		p := &mydriver.Plugin{}
+//		require.NoError(t, p.Init(log, cfg))
+//
+//		driverstest.Run(t, p, &driverstest.Config{Driver: "mydriver"})
+//	}
+package driverstest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+)
+
+// tests names, might be used in the Config.Skip
+const (
+	PushConsume  string = "PushConsume"
+	Priority     string = "Priority"
+	Delay        string = "Delay"
+	PauseResume  string = "PauseResume"
+	Requeue      string = "Requeue"
+	Nack         string = "Nack"
+	State        string = "State"
+	Destroy      string = "Destroy"
+	StopInFlight string = "StopInFlight"
+	// skipped automatically if the driver is not a jobs.BatchPusher
+	Batch string = "Batch"
+)
+
+const (
+	defaultTimeout time.Duration = time.Second * 10
+	// time to make sure that the job is not delivered
+	silence time.Duration = time.Second
+)
+
+// Config configures the suite for the particular driver
+type Config struct {
+	// Driver is the driver name, used in the pipelines and compared with the State
+	Driver string
+
+	// Options returns the driver specific options of the new pipeline, values should have the types the driver reads
+	// (e.g. int for the pipe.Int options, like in the YAML configuration). The name and the driver options are set
+	// by the suite. Might be nil.
+	Options func(t *testing.T, name string) map[string]interface{}
+
+	// NackRedelivers should be set if the driver returns the negatively acknowledged jobs into the queue
+	NackRedelivers bool
+
+	// Timeout to wait for the job delivery and the State changes, default: 10s
+	Timeout time.Duration
+
+	// Skip lists the tests not supported by the driver
+	Skip []string
+}
+
+// Run runs the whole suite, every test uses the new pipeline
+func Run(t *testing.T, c jobs.Constructor, cfg *Config) {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, c jobs.Constructor, cfg *Config)
+	}{
+		{PushConsume, testPushConsume},
+		{Priority, testPriority},
+		{Delay, testDelay},
+		{PauseResume, testPauseResume},
+		{Requeue, testRequeue},
+		{Nack, testNack},
+		{State, testState},
+		{Destroy, testDestroy},
+		{StopInFlight, testStopInFlight},
+		{Batch, testBatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range cfg.Skip {
+				if s == tt.name {
+					t.Skipf("%s is not supported by the %s driver", tt.name, cfg.Driver)
+				}
+			}
+
+			tt.fn(t, c, cfg)
+		})
+	}
+}
+
+// pipelineName returns the name of the pipeline for the test
+func pipelineName(cfg *Config, test string) string {
+	return strings.ToLower(cfg.Driver + "-conformance-" + test)
+}
+
+func testPushConsume(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, PushConsume))
+	e.run()
+
+	const num = 5
+	pushed := make(map[string]struct{}, num)
+	for i := 0; i < num; i++ {
+		pushed[e.push(0, 0, map[string][]string{"test": {"1"}})] = struct{}{}
+	}
+
+	for i := 0; i < num; i++ {
+		it, meta := e.next()
+		if _, ok := pushed[meta.ID]; !ok {
+			t.Fatalf("unexpected job: %s", meta.ID)
+		}
+		delete(pushed, meta.ID)
+
+		if string(it.Body()) != payload(meta.ID) {
+			t.Errorf("job %s payload mismatch: %s", meta.ID, it.Body())
+		}
+
+		if meta.Job != jobName || meta.Pipeline != e.name {
+			t.Errorf("job %s context mismatch, job: %s, pipeline: %s", meta.ID, meta.Job, meta.Pipeline)
+		}
+
+		if h := meta.Headers["test"]; len(h) != 1 || h[0] != "1" {
+			t.Errorf("job %s headers mismatch: %v", meta.ID, meta.Headers)
+		}
+
+		e.ack(it)
+	}
+
+	e.eventually("no active jobs", func(st *jobs.State) bool {
+		return st.Active == 0
+	})
+}
+
+func testPriority(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, Priority))
+	e.run()
+
+	id := e.push(7, 0, nil)
+	it, meta := e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	if it.Priority() != 7 {
+		t.Errorf("job priority mismatch, expected: 7, actual: %d", it.Priority())
+	}
+
+	e.ack(it)
+}
+
+func testDelay(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, Delay))
+	e.run()
+
+	start := time.Now()
+	id := e.push(0, 2, nil)
+	e.none(silence)
+
+	it, meta := e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second*2 {
+		t.Errorf("delayed job delivered too early: %s", elapsed)
+	}
+
+	e.ack(it)
+}
+
+func testPauseResume(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, PauseResume))
+	e.run()
+
+	e.consumer.Pause(context.Background(), e.name)
+	e.eventually("paused", func(st *jobs.State) bool {
+		return !st.Ready
+	})
+
+	id := e.push(0, 0, nil)
+	e.none(silence)
+
+	e.consumer.Resume(context.Background(), e.name)
+	e.eventually("resumed", func(st *jobs.State) bool {
+		return st.Ready
+	})
+
+	it, meta := e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	e.ack(it)
+}
+
+func testRequeue(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, Requeue))
+	e.run()
+
+	id := e.push(0, 0, map[string][]string{"test": {"1"}})
+	it, meta := e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	headers := meta.Headers
+	headers["attempts"] = []string{"1"}
+	err := e.acknowledger(it).Requeue(headers, 0)
+	if err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+
+	it, meta = e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	if h := meta.Headers["attempts"]; len(h) != 1 || h[0] != "1" {
+		t.Errorf("requeued job headers mismatch: %v", meta.Headers)
+	}
+
+	if string(it.Body()) != payload(id) {
+		t.Errorf("requeued job payload mismatch: %s", it.Body())
+	}
+
+	e.ack(it)
+}
+
+func testNack(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, Nack))
+	e.run()
+
+	id := e.push(0, 0, nil)
+	it, _ := e.next()
+
+	err := e.acknowledger(it).Nack()
+	if err != nil {
+		t.Fatalf("nack: %v", err)
+	}
+
+	if cfg.NackRedelivers {
+		it, meta := e.next()
+		if meta.ID != id {
+			t.Fatalf("unexpected job: %s", meta.ID)
+		}
+		e.ack(it)
+	} else {
+		e.none(silence)
+	}
+
+	e.eventually("no active jobs", func(st *jobs.State) bool {
+		return st.Active == 0
+	})
+}
+
+func testState(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, State))
+
+	st := e.state()
+	if st.Pipeline != e.name || st.Driver != cfg.Driver {
+		t.Errorf("state mismatch, pipeline: %s, driver: %s", st.Pipeline, st.Driver)
+	}
+
+	if st.Ready {
+		t.Error("pipeline is ready before the run")
+	}
+
+	const num = 3
+	for i := 0; i < num; i++ {
+		e.push(0, 0, nil)
+	}
+
+	e.eventually("active jobs", func(st *jobs.State) bool {
+		return st.Active == num
+	})
+
+	e.run()
+	e.eventually("ready", func(st *jobs.State) bool {
+		return st.Ready
+	})
+
+	for i := 0; i < num; i++ {
+		it, _ := e.next()
+		e.ack(it)
+	}
+
+	e.eventually("no active jobs", func(st *jobs.State) bool {
+		return st.Active == 0
+	})
+}
+
+func testDestroy(t *testing.T, c jobs.Constructor, cfg *Config) {
+	name := pipelineName(cfg, Destroy)
+
+	e := newEnv(t, c, cfg, name)
+	e.run()
+	e.push(0, 0, nil)
+	it, _ := e.next()
+	e.ack(it)
+	e.stop()
+
+	// the pipeline with the same name might be declared again
+	e = newEnv(t, c, cfg, name)
+	e.run()
+
+	id := e.push(0, 0, nil)
+	it, meta := e.next()
+	if meta.ID != id {
+		t.Fatalf("unexpected job: %s", meta.ID)
+	}
+
+	e.ack(it)
+	e.stop()
+}
+
+func testStopInFlight(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, StopInFlight))
+	e.run()
+
+	e.push(0, 0, nil)
+	it, _ := e.next()
+
+	start := time.Now()
+	e.stop()
+	if elapsed := time.Since(start); elapsed > cfg.Timeout {
+		t.Errorf("stop with the job in flight took too long: %s", elapsed)
+	}
+
+	// late acknowledgement might fail, but should not panic
+	_ = e.acknowledger(it).Ack()
+}
+
+func testBatch(t *testing.T, c jobs.Constructor, cfg *Config) {
+	e := newEnv(t, c, cfg, pipelineName(cfg, Batch))
+	bp, ok := e.consumer.(jobs.BatchPusher)
+	if !ok {
+		t.Skipf("%s driver is not a BatchPusher", cfg.Driver)
+	}
+
+	e.run()
+
+	// delayed jobs with the same delivery time should not replace each other
+	batch := []*job.Job{e.job(0, 0, nil), e.job(0, 1, nil), e.job(0, 1, nil)}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	err := bp.PushBatch(ctx, batch)
+	cancel()
+	if err != nil {
+		t.Fatalf("push batch: %v", err)
+	}
+
+	pushed := make(map[string]struct{}, len(batch))
+	for i := 0; i < len(batch); i++ {
+		pushed[batch[i].Ident] = struct{}{}
+	}
+
+	for i := 0; i < len(batch); i++ {
+		it, meta := e.next()
+		if _, ok := pushed[meta.ID]; !ok {
+			t.Fatalf("unexpected job: %s", meta.ID)
+		}
+		delete(pushed, meta.ID)
+		e.ack(it)
+	}
+}
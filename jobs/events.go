@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+	jobsv1beta "github.com/spiral/roadrunner-plugins/v2/api/proto/jobs/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+// lifecycle events
+const (
+	eventPushed   string = "pushed"
+	eventStarted  string = "started"
+	eventAcked    string = "acked"
+	eventNacked   string = "nacked"
+	eventRequeued string = "requeued"
+)
+
+const (
+	// EventsTopic receives events from all pipelines, EventsTopic + ":" + pipeline - events from the particular pipeline
+	EventsTopic string = pubsub.ReservedTopicPrefix + "jobs"
+
+	defaultEventsBuffer int = 1000
+	// max number of the events waiting to be published into the broadcast
+	publishQueueSize int = 1000
+	// sample rate precision
+	sampleBase uint32 = 10000
+)
+
+// EventsConfig configures the jobs lifecycle events stream
+type EventsConfig struct {
+	// SampleRate is the part of the jobs (0..1] whose events are published, default: 1 (all jobs).
+	// Jobs are sampled by ID, so all events of the sampled job are published.
+	SampleRate float64 `mapstructure:"sample_rate"`
+
+	// Buffer is the number of the last events kept for the Events RPC, default: 1000
+	Buffer int `mapstructure:"buffer"`
+}
+
+func (c *EventsConfig) InitDefaults() {
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		c.SampleRate = 1
+	}
+
+	if c.Buffer <= 0 {
+		c.Buffer = defaultEventsBuffer
+	}
+}
+
+// eventStream keeps the last events in the ring buffer for the Events RPC and publishes them into the broadcast.
+// All methods are safe to call on the nil stream (events disabled).
+type eventStream struct {
+	mu   sync.Mutex
+	seq  uint64
+	ring []*jobsv1beta.Event
+	// closed and replaced on every event, wakes up the waiting readers
+	notify chan struct{}
+
+	sample uint32
+	log    logger.Logger
+
+	// nil if the broadcast plugin is not available
+	pub    broadcast.Publisher
+	pubCh  chan *jobsv1beta.Event
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newEventStream(cfg *EventsConfig, pub broadcast.Publisher, log logger.Logger) *eventStream {
+	es := &eventStream{
+		ring:   make([]*jobsv1beta.Event, cfg.Buffer),
+		notify: make(chan struct{}),
+		sample: uint32(cfg.SampleRate * float64(sampleBase)),
+		log:    log,
+		pub:    pub,
+		stopCh: make(chan struct{}),
+	}
+
+	if pub != nil {
+		es.pubCh = make(chan *jobsv1beta.Event, publishQueueSize)
+		es.wg.Add(1)
+		go es.publish()
+	}
+
+	return es
+}
+
+// sampled reports whether the events of the job should be recorded
+func (es *eventStream) sampled(id string) bool {
+	if es == nil {
+		return false
+	}
+
+	if es.sample >= sampleBase {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return h.Sum32()%sampleBase < es.sample
+}
+
+func (es *eventStream) emit(event, pipeline, driver, id, job string, duration time.Duration) {
+	if !es.sampled(id) {
+		return
+	}
+
+	ev := &jobsv1beta.Event{
+		Event:    event,
+		Pipeline: pipeline,
+		Driver:   driver,
+		Id:       id,
+		Job:      job,
+		Duration: int64(duration),
+		Time:     time.Now().UnixNano(),
+	}
+
+	es.mu.Lock()
+	es.seq++
+	ev.Seq = es.seq
+	es.ring[es.seq%uint64(len(es.ring))] = ev
+	close(es.notify)
+	es.notify = make(chan struct{})
+	es.mu.Unlock()
+
+	if es.pubCh == nil {
+		return
+	}
+
+	// slow broadcast should not block the jobs processing
+	select {
+	case es.pubCh <- ev:
+	default:
+		es.log.Warn("jobs events publish queue is full, event dropped", "event", event, "ID", id)
+	}
+}
+
+// read returns events after the requested sequence number, waiting for the new events up to the wait duration
+func (es *eventStream) read(ctx context.Context, after uint64, limit int, wait time.Duration) ([]*jobsv1beta.Event, uint64) {
+	if limit <= 0 || limit > len(es.ring) {
+		limit = len(es.ring)
+	}
+
+	es.mu.Lock()
+	if es.seq <= after && wait > 0 {
+		notify := es.notify
+		es.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-notify:
+		case <-timer.C:
+		case <-ctx.Done():
+		case <-es.stopCh:
+		}
+		timer.Stop()
+
+		es.mu.Lock()
+	}
+	defer es.mu.Unlock()
+
+	// the oldest event in the buffer
+	first := uint64(1)
+	if es.seq > uint64(len(es.ring)) {
+		first = es.seq - uint64(len(es.ring)) + 1
+	}
+
+	if after+1 > first {
+		first = after + 1
+	}
+
+	out := make([]*jobsv1beta.Event, 0, limit)
+	for s := first; s <= es.seq && len(out) < limit; s++ {
+		out = append(out, es.ring[s%uint64(len(es.ring))])
+	}
+
+	return out, es.seq
+}
+
+func (es *eventStream) publish() {
+	defer es.wg.Done()
+
+	for {
+		select {
+		case ev := <-es.pubCh:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				es.log.Error("jobs event marshal failed", "error", err)
+				continue
+			}
+
+			err = es.pub.Publish(&pubsub.Message{Topic: EventsTopic, Payload: data})
+			if err != nil {
+				es.log.Error("jobs event publish failed", "error", err)
+				continue
+			}
+
+			err = es.pub.Publish(&pubsub.Message{Topic: EventsTopic + ":" + ev.Pipeline, Payload: data})
+			if err != nil {
+				es.log.Error("jobs event publish failed", "error", err)
+			}
+		case <-es.stopCh:
+			return
+		}
+	}
+}
+
+func (es *eventStream) stop() {
+	if es == nil {
+		return
+	}
+
+	close(es.stopCh)
+	es.wg.Wait()
+}
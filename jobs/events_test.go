@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventStreamRead(t *testing.T) {
+	cfg := &EventsConfig{Buffer: 4}
+	cfg.InitDefaults()
+	es := newEventStream(cfg, nil, logger.NewZapAdapter(zap.NewNop()))
+	defer es.stop()
+
+	for i := 0; i < 6; i++ {
+		es.emit(eventPushed, "test-1", "memory", strconv.Itoa(i), "foo", 0)
+	}
+
+	// only the last 4 events are kept
+	evs, last := es.read(context.Background(), 0, 0, 0)
+	require.Len(t, evs, 4)
+	assert.Equal(t, uint64(6), last)
+	assert.Equal(t, uint64(3), evs[0].GetSeq())
+	assert.Equal(t, "2", evs[0].GetId())
+
+	evs, _ = es.read(context.Background(), 4, 1, 0)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(5), evs[0].GetSeq())
+
+	// wait for the next event
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		es.emit(eventAcked, "test-1", "memory", "6", "foo", time.Second)
+	}()
+
+	evs, last = es.read(context.Background(), 6, 10, time.Second*5)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(7), last)
+	assert.Equal(t, eventAcked, evs[0].GetEvent())
+	assert.Equal(t, int64(time.Second), evs[0].GetDuration())
+}
+
+func TestEventStreamSampling(t *testing.T) {
+	cfg := &EventsConfig{SampleRate: 0.1}
+	cfg.InitDefaults()
+	es := newEventStream(cfg, nil, logger.NewZapAdapter(zap.NewNop()))
+	defer es.stop()
+
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		id := strconv.Itoa(i)
+		if es.sampled(id) {
+			sampled++
+			// all events of the job are sampled
+			assert.True(t, es.sampled(id))
+		}
+	}
+
+	assert.InDelta(t, 1000, sampled, 200)
+
+	var disabled *eventStream
+	assert.False(t, disabled.sampled("1"))
+}
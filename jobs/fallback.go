@@ -0,0 +1,324 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
+)
+
+// pipeline options
+const (
+	// fallback is the name of the pipeline which takes pushes while the primary pipeline is failing
+	fallback string = "fallback"
+	// number of the consecutive push errors to open the circuit breaker
+	fallbackThreshold string = "fallback_threshold"
+	// seconds to wait before the next push attempt into the primary pipeline
+	fallbackCooldown string = "fallback_cooldown"
+
+	defaultFallbackThreshold int = 5
+	defaultFallbackCooldown  int = 10
+)
+
+type breakerState uint8
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	// single probe push is allowed
+	breakerHalfOpen
+)
+
+// breaker is the circuit breaker around the primary pipeline push.
+// All methods are safe to call on the nil breaker (pipeline without the fallback).
+type breaker struct {
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+
+	fallback  string
+	threshold int
+	cooldown  time.Duration
+}
+
+func newBreaker(pipe *pipeline.Pipeline) *breaker {
+	br := &breaker{
+		fallback:  pipe.String(fallback, ""),
+		threshold: pipe.Int(fallbackThreshold, defaultFallbackThreshold),
+		cooldown:  time.Second * time.Duration(pipe.Int(fallbackCooldown, defaultFallbackCooldown)),
+	}
+
+	if br.threshold <= 0 {
+		br.threshold = defaultFallbackThreshold
+	}
+
+	if br.cooldown <= 0 {
+		br.cooldown = time.Second * time.Duration(defaultFallbackCooldown)
+	}
+
+	return br
+}
+
+// allow reports whether the push into the primary pipeline should be attempted
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// probe is in progress
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.state = breakerClosed
+	b.failures = 0
+	b.mu.Unlock()
+}
+
+func (b *breaker) failure() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// delay in seconds to requeue the job waiting for the primary pipeline
+func (b *breaker) delay() int64 {
+	if b == nil {
+		return int64(defaultFallbackCooldown)
+	}
+
+	return int64(b.cooldown / time.Second)
+}
+
+// checkFallbacks validates the fallback options of the configured pipelines. Jobs are moved back to the primary pipeline
+// when they are consumed from the fallback pipeline, so the fallback pipeline should be in the consume list.
+func checkFallbacks(pipes map[string]*pipeline.Pipeline, consume map[string]struct{}) error {
+	for name, pipe := range pipes {
+		fb := pipe.String(fallback, "")
+		if fb == "" {
+			continue
+		}
+
+		if fb == name {
+			return errors.Errorf("pipeline can't be a fallback for itself: %s", name)
+		}
+
+		if _, ok := consume[fb]; !ok {
+			return errors.Errorf("fallback pipeline should be consumed, otherwise the jobs are not moved back to the primary pipeline, pipeline: %s, fallback: %s", name, fb)
+		}
+	}
+
+	return nil
+}
+
+// breaker returns the circuit breaker for the pipeline with the fallback, nil otherwise
+func (p *Plugin) breaker(ppl *pipeline.Pipeline) *breaker {
+	if ppl.String(fallback, "") == "" {
+		return nil
+	}
+
+	if br, ok := p.breakers.Load(ppl.Name()); ok {
+		return br.(*breaker)
+	}
+
+	br, _ := p.breakers.LoadOrStore(ppl.Name(), newBreaker(ppl))
+	return br.(*breaker)
+}
+
+// failover pushes the job into the primary pipeline, or into the fallback pipeline if the primary is failing.
+// Returns the pipeline which took the job.
+func (p *Plugin) failover(ppl *pipeline.Pipeline, d jobs.Consumer, br *breaker, j *job.Job) (*pipeline.Pipeline, error) {
+	if br.allow() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+		err := d.Push(ctx, j)
+		cancel()
+		if err == nil {
+			br.success()
+			return ppl, nil
+		}
+
+		br.failure()
+		p.log.Warn("job push to the primary pipeline failed, using the fallback", "error", err, "ID", j.Ident, "pipeline", ppl.Name(), "fallback", br.fallback)
+	}
+
+	return p.pushFallback(ppl, br.fallback, j)
+}
+
+func (p *Plugin) pushFallback(ppl *pipeline.Pipeline, fb string, j *job.Job) (*pipeline.Pipeline, error) {
+	const op = errors.Op("jobs_plugin_push_fallback")
+	pipe, ok := p.pipelines.Load(fb)
+	if !ok {
+		return nil, errors.E(op, errors.Errorf("no such fallback pipeline: %s, primary: %s", fb, ppl.Name()))
+	}
+
+	d, ok := p.consumers.Load(fb)
+	if !ok {
+		return nil, errors.E(op, errors.Errorf("consumer not registered for the fallback pipeline: %s", fb))
+	}
+
+	// drivers accept only jobs for their own pipeline, the primary one is kept in the header
+	cp := *j
+	opts := *j.Options
+	opts.Pipeline = fb
+	cp.Options = &opts
+	cp.Headers = make(map[string][]string, len(j.Headers)+2)
+	for k, v := range j.Headers {
+		cp.Headers[k] = v
+	}
+	cp.Headers[job.RRFallback] = []string{ppl.Name()}
+
+	// original options are restored when the job is moved back
+	data, err := json.Marshal(j.Options)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	cp.Headers[job.RRFallbackOptions] = []string{string(data)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+	defer cancel()
+
+	err = d.(jobs.Consumer).Push(ctx, &cp)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return pipe.(*pipeline.Pipeline), nil
+}
+
+// fallbackMeta is the part of the job context used to move the job back to the primary pipeline
+type fallbackMeta struct {
+	ID      string              `json:"id"`
+	Job     string              `json:"job"`
+	Headers map[string][]string `json:"headers"`
+}
+
+// moveFromFallback moves the job consumed from the fallback pipeline back to the primary pipeline.
+// While the primary pipeline is failing, the job is requeued into the fallback pipeline with the cooldown delay.
+// Returns false if the job was not pushed via the fallback and should be processed by the worker.
+func (p *Plugin) moveFromFallback(jb priorityqueue.Item, ctx []byte) bool {
+	if !bytes.Contains(ctx, []byte(job.RRFallback)) {
+		return false
+	}
+
+	meta := &fallbackMeta{}
+	err := json.Unmarshal(ctx, meta)
+	if err != nil || len(meta.Headers[job.RRFallback]) == 0 {
+		return false
+	}
+
+	acker, ok := jb.(jobs.Acknowledger)
+	if !ok {
+		return false
+	}
+
+	primary := meta.Headers[job.RRFallback][0]
+	pipe, ok := p.pipelines.Load(primary)
+	if !ok {
+		p.log.Warn("primary pipeline not found, job is processed in the fallback pipeline", "ID", meta.ID, "pipeline", primary)
+		return false
+	}
+
+	d, ok := p.consumers.Load(primary)
+	if !ok {
+		p.log.Warn("primary pipeline consumer not found, job is processed in the fallback pipeline", "ID", meta.ID, "pipeline", primary)
+		return false
+	}
+
+	ppl := pipe.(*pipeline.Pipeline)
+	br := p.breaker(ppl)
+	if !br.allow() {
+		p.requeueFallback(acker, meta, br.delay())
+		return true
+	}
+
+	headers := make(map[string][]string, len(meta.Headers))
+	for k, v := range meta.Headers {
+		if k == job.RRFallback || k == job.RRFallbackOptions {
+			continue
+		}
+		headers[k] = v
+	}
+
+	opts := &job.Options{Priority: jb.Priority()}
+	if v := meta.Headers[job.RRFallbackOptions]; len(v) > 0 {
+		errO := json.Unmarshal([]byte(v[0]), opts)
+		if errO != nil {
+			p.log.Warn("failed to restore the job options, priority is taken from the fallback pipeline", "error", errO, "ID", meta.ID)
+		}
+	}
+
+	opts.Pipeline = primary
+	// the delay has already elapsed in the fallback pipeline
+	opts.Delay = 0
+
+	ctxT, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+	err = d.(jobs.Consumer).Push(ctxT, &job.Job{
+		Job:     meta.Job,
+		Ident:   meta.ID,
+		Payload: string(jb.Body()),
+		Headers: headers,
+		Options: opts,
+	})
+	cancel()
+	if err != nil {
+		br.failure()
+		p.log.Warn("job move to the primary pipeline failed", "error", err, "ID", meta.ID, "pipeline", primary)
+		p.requeueFallback(acker, meta, br.delay())
+		return true
+	}
+
+	br.success()
+	err = acker.Ack()
+	if err != nil {
+		// job is already in the primary pipeline, might be duplicated
+		p.log.Error("fallback job acknowledge failed", "error", err, "ID", meta.ID, "pipeline", primary)
+		return true
+	}
+
+	p.log.Debug("job moved from the fallback pipeline", "ID", meta.ID, "pipeline", primary, "driver", ppl.Driver())
+	return true
+}
+
+func (p *Plugin) requeueFallback(acker jobs.Acknowledger, meta *fallbackMeta, delay int64) {
+	err := acker.Requeue(meta.Headers, delay)
+	if err != nil {
+		p.log.Error("fallback job requeue failed", "error", err, "ID", meta.ID)
+	}
+}
@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/jobstest"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBreaker(t *testing.T) {
+	br := newBreaker(&pipeline.Pipeline{fallback: "local", fallbackThreshold: 2})
+	assert.Equal(t, "local", br.fallback)
+	assert.Equal(t, int64(defaultFallbackCooldown), br.delay())
+
+	assert.True(t, br.allow())
+	br.failure()
+	assert.True(t, br.allow())
+	br.failure()
+	assert.False(t, br.allow())
+
+	// cooldown is over, only one probe is allowed
+	br.openedAt = time.Now().Add(-br.cooldown)
+	assert.True(t, br.allow())
+	assert.False(t, br.allow())
+
+	br.failure()
+	assert.False(t, br.allow())
+
+	br.openedAt = time.Now().Add(-br.cooldown)
+	assert.True(t, br.allow())
+	br.success()
+	assert.True(t, br.allow())
+
+	// nil breaker always allows the push
+	var nb *breaker
+	assert.True(t, nb.allow())
+}
+
+func TestFailover(t *testing.T) {
+	p := &Plugin{
+		cfg: &Config{Timeout: 1},
+		log: logger.NewZapAdapter(zap.NewNop()),
+	}
+
+	primary := &pipeline.Pipeline{pipelineName: "remote", fallback: "local", fallbackThreshold: 1}
+	local := &pipeline.Pipeline{pipelineName: "local", "driver": "boltdb"}
+	p.pipelines.Store("local", local)
+
+	pc := &jobstest.Consumer{}
+	pc.Fail(errors.Str("connection refused"))
+	fc := &jobstest.Consumer{}
+	p.consumers.Store("local", fc)
+
+	br := p.breaker(primary)
+	require.NotNil(t, br)
+	assert.Nil(t, p.breaker(local))
+
+	target, err := p.failover(primary, pc, br, &job.Job{Ident: "1", Options: &job.Options{Pipeline: "remote"}})
+	require.NoError(t, err)
+	assert.Equal(t, "local", target.Name())
+
+	require.Len(t, fc.Pushed(), 1)
+	assert.Equal(t, "local", fc.Pushed()[0].Options.Pipeline)
+	assert.Equal(t, []string{"remote"}, fc.Pushed()[0].Headers[job.RRFallback])
+
+	// breaker is open, primary is not touched
+	pc.Fail(nil)
+	_, err = p.failover(primary, pc, br, &job.Job{Ident: "2", Options: &job.Options{Pipeline: "remote"}})
+	require.NoError(t, err)
+	assert.Len(t, fc.Pushed(), 2)
+	assert.Len(t, pc.Pushed(), 0)
+}
+
+type fallbackItem struct {
+	testItem
+	acknowledger
+}
+
+func TestMoveFromFallback(t *testing.T) {
+	p := &Plugin{
+		cfg: &Config{Timeout: 1},
+		log: logger.NewZapAdapter(zap.NewNop()),
+	}
+
+	primary := &pipeline.Pipeline{pipelineName: "remote", fallback: "local", fallbackThreshold: 1}
+	p.pipelines.Store("remote", primary)
+	p.pipelines.Store("local", &pipeline.Pipeline{pipelineName: "local", "driver": "boltdb"})
+
+	pc := &jobstest.Consumer{}
+	fc := &jobstest.Consumer{}
+	p.consumers.Store("remote", pc)
+	p.consumers.Store("local", fc)
+
+	_, err := p.pushFallback(primary, "local", &job.Job{Ident: "1", Job: "foo", Headers: map[string][]string{"foo": {"bar"}}, Options: &job.Options{Pipeline: "remote", Priority: 3, Delay: 5}})
+	require.NoError(t, err)
+	require.Len(t, fc.Pushed(), 1)
+
+	ctx, err := json.Marshal(fc.Pushed()[0])
+	require.NoError(t, err)
+	assert.True(t, p.moveFromFallback(&fallbackItem{testItem: testItem{id: "1"}}, ctx))
+
+	// original options are restored, the delay has already elapsed
+	require.Len(t, pc.Pushed(), 1)
+	assert.Equal(t, &job.Options{Pipeline: "remote", Priority: 3}, pc.Pushed()[0].Options)
+	assert.Equal(t, map[string][]string{"foo": {"bar"}}, pc.Pushed()[0].Headers)
+}
+
+func TestCheckFallbacks(t *testing.T) {
+	pipes := map[string]*pipeline.Pipeline{
+		"remote": {fallback: "local"},
+		"local":  {"driver": "boltdb"},
+	}
+
+	assert.Error(t, checkFallbacks(pipes, map[string]struct{}{"remote": {}}))
+	assert.NoError(t, checkFallbacks(pipes, map[string]struct{}{"local": {}}))
+
+	pipes["local"] = &pipeline.Pipeline{fallback: "local"}
+	assert.Error(t, checkFallbacks(pipes, map[string]struct{}{"local": {}}))
+}
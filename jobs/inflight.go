@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"sync/atomic"
+
+	pq "github.com/spiral/roadrunner/v2/priority_queue"
+)
+
+// inflight wraps the priority queue passed to the pipeline driver and counts the jobs taken from the driver
+// but not yet processed by the listener. The reload waits for these jobs before the driver is destroyed.
+type inflight struct {
+	pq.Queue
+	n int64
+}
+
+// inflightItem is the queued item of the pipeline, done is called by the listener when the job is processed
+type inflightItem struct {
+	pq.Item
+	q *inflight
+}
+
+func (q *inflight) Insert(item pq.Item) {
+	atomic.AddInt64(&q.n, 1)
+	q.Queue.Insert(&inflightItem{Item: item, q: q})
+}
+
+func (q *inflight) count() int64 {
+	return atomic.LoadInt64(&q.n)
+}
+
+func (i *inflightItem) done() {
+	atomic.AddInt64(&i.q.n, -1)
+}
+
+// unwrap returns the driver's item and the function to call when the job is processed
+func unwrap(item pq.Item) (pq.Item, func()) {
+	if i, ok := item.(*inflightItem); ok {
+		return i.Item, i.done
+	}
+
+	return item, func() {}
+}
+
+// queueFor returns the priority queue for the pipeline driver
+func (p *Plugin) queueFor(name string) pq.Queue {
+	q := &inflight{Queue: p.queue}
+	p.inflight.Store(name, q)
+	return q
+}
+
+// inflightJobs returns the number of the not processed jobs taken from the pipeline driver
+func (p *Plugin) inflightJobs(name string) int64 {
+	q, ok := p.inflight.Load(name)
+	if !ok {
+		return 0
+	}
+
+	return q.(*inflight).count()
+}
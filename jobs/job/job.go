@@ -12,6 +12,12 @@ const (
 	RRPipeline string = "rr_pipeline"
 	RRDelay    string = "rr_delay"
 	RRPriority string = "rr_priority"
+	// RRPushedAt header contains the push time (unix nano), used for the metrics
+	RRPushedAt string = "rr_pushed_at"
+	// RRFallback header contains the primary pipeline name for the jobs pushed into the fallback pipeline
+	RRFallback string = "rr_fallback"
+	// RRFallbackOptions header contains the original job options (JSON) restored when the job is moved back to the primary pipeline
+	RRFallbackOptions string = "rr_fallback_options"
 )
 
 // Job carries information about single job.
@@ -0,0 +1,103 @@
+// Package jobstest provides the in-memory jobs.Consumer for the tests of the consumers wrappers and the jobs plugin
+// (sharded pipelines, fallback, batches). Pushed jobs are only recorded, nothing is delivered into the priority queue.
+package jobstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+)
+
+// Consumer records the pushed jobs, safe for the concurrent use
+type Consumer struct {
+	mu     sync.Mutex
+	err    error
+	pipe   *pipeline.Pipeline
+	pushed []*job.Job
+	ready  bool
+}
+
+var _ jobs.Consumer = (*Consumer)(nil)
+
+// Fail makes the Push return the err, nil - jobs are pushed again
+func (c *Consumer) Fail(err error) {
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+}
+
+// Pushed returns the pushed jobs in the push order
+func (c *Consumer) Pushed() []*job.Job {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]*job.Job, len(c.pushed))
+	copy(out, c.pushed)
+	return out
+}
+
+// Pipeline returns the registered pipeline, nil if the consumer is not registered
+func (c *Consumer) Pipeline() *pipeline.Pipeline {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pipe
+}
+
+func (c *Consumer) Push(_ context.Context, j *job.Job) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.pushed = append(c.pushed, j)
+	return nil
+}
+
+func (c *Consumer) Register(_ context.Context, p *pipeline.Pipeline) error {
+	c.mu.Lock()
+	c.pipe = p
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Consumer) Run(_ context.Context, _ *pipeline.Pipeline) error {
+	c.mu.Lock()
+	c.ready = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Consumer) Stop(_ context.Context) error {
+	return nil
+}
+
+func (c *Consumer) Pause(_ context.Context, _ string) {
+	c.mu.Lock()
+	c.ready = false
+	c.mu.Unlock()
+}
+
+func (c *Consumer) Resume(_ context.Context, _ string) {
+	c.mu.Lock()
+	c.ready = true
+	c.mu.Unlock()
+}
+
+// State returns the number of the pushed jobs as active, the queue is the pipeline queue option
+func (c *Consumer) State(_ context.Context) (*jobs.State, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := &jobs.State{Active: int64(len(c.pushed)), Ready: c.ready}
+	if c.pipe != nil {
+		st.Pipeline = c.pipe.Name()
+		st.Driver = c.pipe.Driver()
+		st.Queue = c.pipe.String("queue", "")
+	}
+	return st, nil
+}
@@ -18,7 +18,8 @@ func (p *Plugin) listener() { //nolint:gocognit
 				default:
 					start := time.Now()
 					// get prioritized JOB from the queue
-					jb := p.queue.ExtractMin()
+					// done should be called when the job is processed, the reload waits for the in-flight jobs
+					jb, done := unwrap(p.queue.ExtractMin())
 
 					// parse the context
 					// for each job, context contains:
@@ -41,26 +42,40 @@ func (p *Plugin) listener() { //nolint:gocognit
 						if errNack != nil {
 							p.log.Error("negatively acknowledge failed", "error", errNack)
 						}
+						done()
 						continue
 					}
 
+					// jobs buffered in the fallback pipeline are moved back to the primary one
+					if p.moveFromFallback(jb, ctx) {
+						done()
+						continue
+					}
+
+					// per-pipeline metrics
+					tr := p.pipelineExporter.track(jb, ctx, time.Now())
+
 					// get payload from the sync.Pool
-					exec := p.getPayload(jb.Body(), ctx)
+					exec := p.getPayload(jb.Body(), withoutPushedAt(ctx))
 
 					// protect from the pool reset
 					p.RLock()
+					execStart := time.Now()
 					resp, err := p.workersPool.Exec(exec)
+					tr.executed(time.Since(execStart))
 					p.RUnlock()
 					if err != nil {
 						atomic.AddUint64(p.metrics.jobsErr, 1)
+						tr.failed(err)
 						p.log.Error("job processed with errors", "error", err, "ID", jb.ID(), "start", start, "elapsed", time.Since(start))
-						if _, ok := jb.(jobs.Acknowledger); !ok {
+						if tr.Acknowledger == nil {
 							p.log.Error("job execute failed, job is not a Acknowledger, skipping Ack/Nack")
 							p.putPayload(exec)
+							done()
 							continue
 						}
 						// RR protocol level error, Nack the job
-						errNack := jb.(jobs.Acknowledger).Nack()
+						errNack := tr.Nack()
 						if errNack != nil {
 							p.log.Error("negatively acknowledge failed", "error", errNack)
 						}
@@ -68,23 +83,26 @@ func (p *Plugin) listener() { //nolint:gocognit
 						p.log.Error("job execute failed", "error", err)
 						p.putPayload(exec)
 						jb = nil
+						done()
 						continue
 					}
 
-					if _, ok := jb.(jobs.Acknowledger); !ok {
+					if tr.Acknowledger == nil {
 						// can't acknowledge, just continue
 						p.putPayload(exec)
+						done()
 						continue
 					}
 
 					// if response is nil or body is nil, just acknowledge the job
 					if resp == nil || resp.Body == nil {
 						p.putPayload(exec)
-						err = jb.(jobs.Acknowledger).Ack()
+						err = tr.Ack()
 						if err != nil {
 							atomic.AddUint64(p.metrics.jobsErr, 1)
 							p.log.Error("acknowledge error, job might be missed", "error", err, "ID", jb.ID(), "start", start, "elapsed", time.Since(start))
 							jb = nil
+							done()
 							continue
 						}
 
@@ -93,24 +111,27 @@ func (p *Plugin) listener() { //nolint:gocognit
 						atomic.AddUint64(p.metrics.jobsOk, 1)
 
 						jb = nil
+						done()
 						continue
 					}
 
 					// handle the response protocol
-					err = p.respHandler.Handle(resp, jb.(jobs.Acknowledger))
+					err = p.respHandler.Handle(resp, tr)
 					if err != nil {
 						atomic.AddUint64(p.metrics.jobsErr, 1)
 						p.log.Error("response handler error", "error", err, "ID", jb.ID(), "start", start, "elapsed", time.Since(start))
 						p.putPayload(exec)
-						errNack := jb.(jobs.Acknowledger).Nack()
+						errNack := tr.Nack()
 						if errNack != nil {
 							p.log.Error("negatively acknowledge failed, job might be lost", "root error", err, "error nack", errNack)
 							jb = nil
+							done()
 							continue
 						}
 
 						p.log.Error("job negatively acknowledged", "error", err)
 						jb = nil
+						done()
 						continue
 					}
 
@@ -121,6 +142,7 @@ func (p *Plugin) listener() { //nolint:gocognit
 
 					// return payload
 					p.putPayload(exec)
+					done()
 					jb = nil
 				}
 			}
@@ -1,20 +1,31 @@
 package jobs
 
 import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	json "github.com/json-iterator/go"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
 	"github.com/spiral/roadrunner-plugins/v2/informer"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
 )
 
 func (p *Plugin) MetricsCollector() []prometheus.Collector {
 	// p - implements Exporter interface (workers)
 	// other - request duration and count
-	return []prometheus.Collector{p.statsExporter}
+	return []prometheus.Collector{p.statsExporter, p.pipelineExporter}
 }
 
 const (
 	namespace = "rr_jobs"
+	// stateTimeout is the max time to get the pipeline state during the scrape
+	stateTimeout = time.Second
 )
 
 type statsExporter struct {
@@ -74,3 +85,279 @@ func (se *statsExporter) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(pushOk, prometheus.GaugeValue, float64(atomic.LoadUint64(se.pushOk)))
 	ch <- prometheus.MustNewConstMetric(pushErr, prometheus.GaugeValue, float64(atomic.LoadUint64(se.pushErr)))
 }
+
+// job outcomes
+const (
+	outcomeAck     string = "ack"
+	outcomeNack    string = "nack"
+	outcomeRequeue string = "requeue"
+	// worker exec_ttl exceeded
+	outcomeTimeout string = "timeout"
+	// no free worker during the allocate_timeout
+	outcomeExpired string = "expired"
+)
+
+var (
+	stateLabels = []string{"pipeline", "driver"}
+
+	stateActive   = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "state_active"), "Number of the active (ready to be consumed) jobs in the pipeline.", stateLabels, nil)
+	stateDelayed  = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "state_delayed"), "Number of the delayed jobs in the pipeline.", stateLabels, nil)
+	stateReserved = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "state_reserved"), "Number of the reserved (in processing) jobs in the pipeline.", stateLabels, nil)
+	stateBuried   = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "state_buried"), "Number of the buried jobs in the pipeline.", stateLabels, nil)
+	stateReady    = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "state_ready"), "Pipeline is consumed (1) or paused (0).", stateLabels, nil)
+)
+
+// pipelineExporter exports per-pipeline jobs metrics: latencies, outcomes and the drivers' state
+type pipelineExporter struct {
+	p *Plugin
+
+	queueWait *prometheus.HistogramVec
+	execution *prometheus.HistogramVec
+	latency   *prometheus.HistogramVec
+	outcomes  *prometheus.CounterVec
+}
+
+func newPipelineExporter(p *Plugin) *pipelineExporter {
+	return &pipelineExporter{
+		p: p,
+		queueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "queue_wait_seconds",
+			Help:      "Time between the job push and the start of the processing.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 16),
+		}, stateLabels),
+		execution: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "execution_duration_seconds",
+			Help:      "Job execution duration in the worker.",
+		}, stateLabels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "latency_seconds",
+			Help:      "Time between the job push and the acknowledgement.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 16),
+		}, stateLabels),
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outcome_total",
+			Help:      "Number of the processed jobs by the outcome: ack, nack, requeue, timeout, expired.",
+		}, []string{"pipeline", "driver", "outcome"}),
+	}
+}
+
+func (pe *pipelineExporter) Describe(d chan<- *prometheus.Desc) {
+	pe.queueWait.Describe(d)
+	pe.execution.Describe(d)
+	pe.latency.Describe(d)
+	pe.outcomes.Describe(d)
+
+	d <- stateActive
+	d <- stateDelayed
+	d <- stateReserved
+	d <- stateBuried
+	d <- stateReady
+}
+
+func (pe *pipelineExporter) Collect(ch chan<- prometheus.Metric) {
+	pe.queueWait.Collect(ch)
+	pe.execution.Collect(ch)
+	pe.latency.Collect(ch)
+	pe.outcomes.Collect(ch)
+
+	// gauges are derived from the drivers' state, failed driver should not break the whole scrape.
+	// States are requested concurrently with the short timeout, so a slow driver doesn't block the scrape.
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	states := make([]*jobs.State, 0, 10)
+
+	pe.p.consumers.Range(func(_, value interface{}) bool {
+		wg.Add(1)
+		go func(c jobs.Consumer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), stateTimeout)
+			st, err := c.State(ctx)
+			cancel()
+			if err != nil {
+				pe.p.log.Warn("failed to get the pipeline state for the metrics", "error", err)
+				return
+			}
+
+			mu.Lock()
+			states = append(states, st)
+			mu.Unlock()
+		}(value.(jobs.Consumer))
+		return true
+	})
+
+	wg.Wait()
+
+	for _, st := range states {
+		ready := 0.0
+		if st.Ready {
+			ready = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(stateActive, prometheus.GaugeValue, float64(st.Active), st.Pipeline, st.Driver)
+		ch <- prometheus.MustNewConstMetric(stateDelayed, prometheus.GaugeValue, float64(st.Delayed), st.Pipeline, st.Driver)
+		ch <- prometheus.MustNewConstMetric(stateReserved, prometheus.GaugeValue, float64(st.Reserved), st.Pipeline, st.Driver)
+		ch <- prometheus.MustNewConstMetric(stateBuried, prometheus.GaugeValue, float64(st.Buried), st.Pipeline, st.Driver)
+		ch <- prometheus.MustNewConstMetric(stateReady, prometheus.GaugeValue, ready, st.Pipeline, st.Driver)
+	}
+}
+
+// jobMeta is the part of the job context used by the metrics
+type jobMeta struct {
+	ID       string `json:"id"`
+	Job      string `json:"job"`
+	Pipeline string `json:"pipeline"`
+	Headers  struct {
+		PushedAt []string `json:"rr_pushed_at"`
+	} `json:"headers"`
+}
+
+// tracked wraps the job acknowledger and records the job outcome
+type tracked struct {
+	jobs.Acknowledger
+
+	pe       *pipelineExporter
+	id       string
+	job      string
+	pipeline string
+	driver   string
+	// zero if the job was pushed without the timestamp header
+	pushedAt time.Time
+	reserved time.Time
+}
+
+// track parses the job context and records the queue wait time
+func (pe *pipelineExporter) track(jb interface{}, ctx []byte, reserved time.Time) *tracked {
+	t := &tracked{pe: pe, reserved: reserved}
+	if a, ok := jb.(jobs.Acknowledger); ok {
+		t.Acknowledger = a
+	}
+
+	meta := &jobMeta{}
+	err := json.Unmarshal(ctx, meta)
+	if err != nil {
+		return t
+	}
+
+	t.id = meta.ID
+	t.job = meta.Job
+	t.pipeline = meta.Pipeline
+	if pipe, ok := pe.p.lookup(meta.Pipeline); ok {
+		t.driver = pipe.Driver()
+	}
+
+	if len(meta.Headers.PushedAt) > 0 {
+		ns, errP := strconv.ParseInt(meta.Headers.PushedAt[0], 10, 64)
+		if errP == nil {
+			t.pushedAt = time.Unix(0, ns)
+			pe.queueWait.WithLabelValues(t.pipeline, t.driver).Observe(reserved.Sub(t.pushedAt).Seconds())
+		}
+	}
+
+	var wait time.Duration
+	if !t.pushedAt.IsZero() {
+		wait = reserved.Sub(t.pushedAt)
+	}
+
+	pe.p.events.emit(eventStarted, t.pipeline, t.driver, t.id, t.job, wait)
+	return t
+}
+
+func (t *tracked) executed(elapsed time.Duration) {
+	t.pe.execution.WithLabelValues(t.pipeline, t.driver).Observe(elapsed.Seconds())
+}
+
+// failed records the worker execution error
+func (t *tracked) failed(err error) {
+	switch {
+	case errors.Is(errors.ExecTTL, err):
+		t.pe.outcomes.WithLabelValues(t.pipeline, t.driver, outcomeTimeout).Inc()
+	case errors.Is(errors.NoFreeWorkers, err):
+		t.pe.outcomes.WithLabelValues(t.pipeline, t.driver, outcomeExpired).Inc()
+	}
+}
+
+func (t *tracked) Ack() error {
+	err := t.Acknowledger.Ack()
+	if err != nil {
+		return err
+	}
+
+	t.pe.outcomes.WithLabelValues(t.pipeline, t.driver, outcomeAck).Inc()
+	t.pe.p.events.emit(eventAcked, t.pipeline, t.driver, t.id, t.job, time.Since(t.reserved))
+	if !t.pushedAt.IsZero() {
+		t.pe.latency.WithLabelValues(t.pipeline, t.driver).Observe(time.Since(t.pushedAt).Seconds())
+	}
+
+	return nil
+}
+
+func (t *tracked) Nack() error {
+	err := t.Acknowledger.Nack()
+	if err != nil {
+		return err
+	}
+
+	t.pe.outcomes.WithLabelValues(t.pipeline, t.driver, outcomeNack).Inc()
+	t.pe.p.events.emit(eventNacked, t.pipeline, t.driver, t.id, t.job, time.Since(t.reserved))
+	return nil
+}
+
+func (t *tracked) Requeue(headers map[string][]string, delay int64) error {
+	err := t.Acknowledger.Requeue(headers, delay)
+	if err != nil {
+		return err
+	}
+
+	t.pe.outcomes.WithLabelValues(t.pipeline, t.driver, outcomeRequeue).Inc()
+	t.pe.p.events.emit(eventRequeued, t.pipeline, t.driver, t.id, t.job, time.Since(t.reserved))
+	return nil
+}
+
+// setPushedAt sets the push timestamp header used for the queue wait and latency metrics
+func setPushedAt(j *job.Job, now time.Time) {
+	if j.Headers == nil {
+		j.Headers = make(map[string][]string, 1)
+	}
+
+	if _, ok := j.Headers[job.RRPushedAt]; ok {
+		return
+	}
+
+	j.Headers[job.RRPushedAt] = []string{strconv.FormatInt(now.UnixNano(), 10)}
+}
+
+// withoutPushedAt removes the push timestamp header from the job context, the header is internal and is not sent to the worker
+func withoutPushedAt(ctx []byte) []byte {
+	if !bytes.Contains(ctx, []byte(job.RRPushedAt)) {
+		return ctx
+	}
+
+	m := make(map[string]json.RawMessage)
+	err := json.Unmarshal(ctx, &m)
+	if err != nil {
+		return ctx
+	}
+
+	headers := make(map[string][]string)
+	err = json.Unmarshal(m["headers"], &headers)
+	if err != nil {
+		return ctx
+	}
+
+	delete(headers, job.RRPushedAt)
+	m["headers"], err = json.Marshal(headers)
+	if err != nil {
+		return ctx
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return ctx
+	}
+
+	return data
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/jobstest"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type acknowledger struct{}
+
+func (a *acknowledger) Ack() error                                   { return nil }
+func (a *acknowledger) Nack() error                                  { return nil }
+func (a *acknowledger) Requeue(_ map[string][]string, _ int64) error { return nil }
+func (a *acknowledger) Respond(_ []byte, _ string) error             { return nil }
+
+func TestPipelineExporterTrack(t *testing.T) {
+	p := &Plugin{}
+	p.pipelines.Store("test-1", &pipeline.Pipeline{"name": "test-1", "driver": "memory"})
+	pe := newPipelineExporter(p)
+
+	j := &job.Job{}
+	setPushedAt(j, time.Now().Add(-time.Second))
+	pushedAt := j.Headers[job.RRPushedAt][0]
+
+	// header is not overwritten on the second push
+	setPushedAt(j, time.Now())
+	assert.Equal(t, pushedAt, j.Headers[job.RRPushedAt][0])
+
+	ctx := []byte(`{"id":"1","job":"foo","pipeline":"test-1","headers":{"rr_pushed_at":["` + pushedAt + `"]}}`)
+	tr := pe.track(&acknowledger{}, ctx, time.Now())
+
+	assert.Equal(t, "memory", tr.driver)
+	ns, err := strconv.ParseInt(pushedAt, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(0, ns), tr.pushedAt)
+
+	tr.executed(time.Millisecond)
+	require.NoError(t, tr.Ack())
+	require.NoError(t, tr.Requeue(nil, 0))
+	tr.failed(errors.E(errors.ExecTTL))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(pe.outcomes.WithLabelValues("test-1", "memory", outcomeAck)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pe.outcomes.WithLabelValues("test-1", "memory", outcomeRequeue)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pe.outcomes.WithLabelValues("test-1", "memory", outcomeTimeout)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(pe.outcomes.WithLabelValues("test-1", "memory", outcomeNack)))
+	assert.Equal(t, 1, testutil.CollectAndCount(pe.queueWait))
+	assert.Equal(t, 1, testutil.CollectAndCount(pe.latency))
+
+	// the header is not sent to the worker
+	stripped := withoutPushedAt(ctx)
+	assert.NotContains(t, string(stripped), job.RRPushedAt)
+	meta := &jobMeta{}
+	require.NoError(t, json.Unmarshal(stripped, meta))
+	assert.Equal(t, "1", meta.ID)
+	assert.Equal(t, "test-1", meta.Pipeline)
+
+	noHeader := []byte(`{"id":"1","headers":{"foo":["bar"]}}`)
+	assert.Equal(t, noHeader, withoutPushedAt(noHeader))
+}
+
+type parent struct {
+	jobstest.Consumer
+	children []*pipeline.Pipeline
+}
+
+func (p *parent) Children() []*pipeline.Pipeline {
+	return p.children
+}
+
+func TestPipelineExporterTrackChild(t *testing.T) {
+	p := &Plugin{}
+	p.pipelines.Store("sharded-1", &pipeline.Pipeline{"name": "sharded-1", "driver": "sharded"})
+	c := &parent{children: []*pipeline.Pipeline{{"name": "sharded-1.a", "driver": "memory"}}}
+	p.storeChildren(c)
+	pe := newPipelineExporter(p)
+
+	tr := pe.track(&acknowledger{}, []byte(`{"id":"1","job":"foo","pipeline":"sharded-1.a"}`), time.Now())
+	assert.Equal(t, "memory", tr.driver)
+	require.NoError(t, tr.Ack())
+	assert.Equal(t, 1.0, testutil.ToFloat64(pe.outcomes.WithLabelValues("sharded-1.a", "memory", outcomeAck)))
+
+	// child pipelines are not listed
+	assert.Equal(t, []string{"sharded-1"}, p.List())
+
+	p.deleteChildren(c)
+	_, ok := p.lookup("sharded-1.a")
+	assert.False(t, ok)
+}
@@ -1,6 +1,8 @@
 package pipeline
 
 import (
+	"time"
+
 	json "github.com/json-iterator/go"
 	"github.com/spiral/roadrunner/v2/utils"
 )
@@ -86,6 +88,23 @@ func (p Pipeline) Bool(name string, d bool) bool {
 	return d
 }
 
+// Duration must return option value as time.Duration (the same format as in the configuration, e.g. "10s", "500ms")
+// or return default value.
+func (p Pipeline) Duration(name string, d time.Duration) time.Duration {
+	if value, ok := p[name]; ok {
+		switch v := value.(type) {
+		case time.Duration:
+			return v
+		case string:
+			if dur, err := time.ParseDuration(v); err == nil {
+				return dur
+			}
+		}
+	}
+
+	return d
+}
+
 // Map must return nested map value or empty config.
 // Here might be sqs attributes or tags for example
 func (p Pipeline) Map(name string, out map[string]string) error {
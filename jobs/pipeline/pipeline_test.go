@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,3 +20,13 @@ func TestPipeline_Has(t *testing.T) {
 	assert.Equal(t, true, pipe.Has("options"))
 	assert.Equal(t, false, pipe.Has("other"))
 }
+
+func TestPipeline_Duration(t *testing.T) {
+	pipe := Pipeline{"timeout": "1m30s", "duration": time.Second, "bad": "10", "int": 10}
+
+	assert.Equal(t, time.Second*90, pipe.Duration("timeout", 0))
+	assert.Equal(t, time.Second, pipe.Duration("duration", 0))
+	assert.Equal(t, time.Second*5, pipe.Duration("bad", time.Second*5))
+	assert.Equal(t, time.Second*5, pipe.Duration("int", time.Second*5))
+	assert.Equal(t, time.Second*5, pipe.Duration("other", time.Second*5))
+}
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	stderr "errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -10,6 +11,8 @@ import (
 	endure "github.com/spiral/endure/pkg/container"
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
 	"github.com/spiral/roadrunner-plugins/v2/config"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
@@ -41,6 +44,7 @@ type Plugin struct {
 
 	// Jobs plugin configuration
 	cfg         *Config `structure:"jobs"`
+	cfgPlugin   config.Configurer
 	log         logger.Logger
 	workersPool pool.Pool
 	server      server.Server
@@ -55,17 +59,39 @@ type Plugin struct {
 
 	// parent config for broken options. keys are pipelines names, values - pointers to the associated pipeline
 	pipelines sync.Map
+	// child pipelines of the jobs.Parent drivers (e.g. sharded), keys are child pipelines names
+	children sync.Map
 
 	// initial set of the pipelines to consume
 	consume map[string]struct{}
+	// serializes configuration reloads
+	reloadMu sync.Mutex
+	// guards consume, static and cfg.Pipelines replaced by the reload
+	cfgMu sync.RWMutex
+
+	// pipelines from the configuration, other pipelines are declared dynamically
+	static map[string]struct{}
+	// not processed jobs taken from the pipelines drivers, map[string]*inflight
+	inflight sync.Map
+	// dynamically declared pipelines persistence, nil if disabled
+	registry   *registry
+	kvProvider kv.StorageProvider
+
+	// circuit breakers of the pipelines with the fallback, keys are primary pipelines names
+	breakers sync.Map
+
+	// lifecycle events, nil if disabled
+	events    *eventStream
+	publisher broadcast.Publisher
 
 	// signal channel to stop the pollers
 	stopCh chan struct{}
 
 	// internal payloads pool
-	pldPool       sync.Pool
-	statsExporter *statsExporter
-	respHandler   *rh.RespHandler
+	pldPool          sync.Pool
+	statsExporter    *statsExporter
+	pipelineExporter *pipelineExporter
+	respHandler      *rh.RespHandler
 }
 
 func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Server) error {
@@ -81,12 +107,18 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 
 	p.cfg.InitDefaults()
 
+	p.cfgPlugin = cfg
 	p.server = server
 
 	p.jobConstructors = make(map[string]jobs.Constructor)
 	p.consume = make(map[string]struct{})
+	p.static = make(map[string]struct{}, len(p.cfg.Pipelines))
 	p.stopCh = make(chan struct{}, 1)
 
+	if p.cfg.Registry != nil && p.cfg.Registry.Storage != "" && p.cfg.Registry.File != "" {
+		return errors.E(op, errors.Str("only one of the registry storage or file should be set"))
+	}
+
 	p.pldPool = sync.Pool{New: func() interface{} {
 		// with nil fields
 		return &payload.Payload{}
@@ -95,6 +127,7 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 	// initial set of pipelines
 	for i := range p.cfg.Pipelines {
 		p.pipelines.Store(i, p.cfg.Pipelines[i])
+		p.static[i] = struct{}{}
 	}
 
 	if len(p.cfg.Consume) > 0 {
@@ -103,6 +136,11 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 		}
 	}
 
+	err = checkFallbacks(p.cfg.Pipelines, p.consume)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
 	// initialize priority queue
 	p.queue = pq.NewBinHeap(p.cfg.PipelineSize)
 	p.log = log
@@ -115,6 +153,7 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, server server.Se
 
 	// metrics
 	p.statsExporter = newStatsExporter(p, p.metrics.jobsOk, p.metrics.pushOk, p.metrics.jobsErr, p.metrics.pushErr)
+	p.pipelineExporter = newPipelineExporter(p)
 	p.respHandler = rh.NewResponseHandler(log)
 
 	return nil
@@ -124,12 +163,28 @@ func (p *Plugin) Serve() chan error {
 	errCh := make(chan error, 1)
 	const op = errors.Op("jobs_plugin_serve")
 
+	if p.cfg.Events != nil {
+		p.events = newEventStream(p.cfg.Events, p.publisher, p.log)
+	}
+
+	// replay dynamically declared pipelines before the configured
+	err := p.replay()
+	if err != nil {
+		errCh <- errors.E(op, err)
+		return errCh
+	}
+
 	// register initial pipelines
 	p.pipelines.Range(func(key, value interface{}) bool {
 		t := time.Now()
 		// pipeline name (ie test-local, sqs-aws, etc)
 		name := key.(string)
 
+		// replayed pipelines are already registered
+		if !p.Static(name) {
+			return true
+		}
+
 		// pipeline associated with the name
 		pipe := value.(*pipeline.Pipeline)
 		// driver for the pipeline (ie amqp, ephemeral, etc)
@@ -142,7 +197,7 @@ func (p *Plugin) Serve() chan error {
 			configKey := fmt.Sprintf("%s.%s.%s", PluginName, pipelines, name)
 
 			// init the driver
-			initializedDriver, err := p.jobConstructors[dr].ConsumerFromConfig(configKey, p.queue)
+			initializedDriver, err := p.jobConstructors[dr].ConsumerFromConfig(configKey, p.queueFor(name))
 			if err != nil {
 				errCh <- errors.E(op, err)
 				return false
@@ -157,9 +212,10 @@ func (p *Plugin) Serve() chan error {
 				errCh <- errors.E(op, errors.Errorf("pipe register failed for the driver: %s with pipe name: %s", pipe.Driver(), pipe.Name()))
 				return false
 			}
+			p.storeChildren(initializedDriver)
 
 			// if pipeline initialized to be consumed, call Run on it
-			if _, ok := p.consume[name]; ok {
+			if p.consumed(name) {
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
 				defer cancel()
 				err = initializedDriver.Run(ctx, pipe)
@@ -231,12 +287,16 @@ func (p *Plugin) Stop() error {
 	}
 	p.Unlock()
 
+	p.events.stop()
+
 	return nil
 }
 
 func (p *Plugin) Collects() []interface{} {
 	return []interface{}{
 		p.CollectMQBrokers,
+		p.CollectKVProvider,
+		p.CollectPublisher,
 	}
 }
 
@@ -244,6 +304,16 @@ func (p *Plugin) CollectMQBrokers(name endure.Named, c jobs.Constructor) {
 	p.jobConstructors[name.Name()] = c
 }
 
+// CollectKVProvider collects KV storages provider, used for the pipelines registry
+func (p *Plugin) CollectKVProvider(_ endure.Named, sp kv.StorageProvider) {
+	p.kvProvider = sp
+}
+
+// CollectPublisher collects the broadcast plugin, used for the lifecycle events
+func (p *Plugin) CollectPublisher(_ endure.Named, pub broadcast.Publisher) {
+	p.publisher = pub
+}
+
 func (p *Plugin) Workers() []*process.State {
 	p.RLock()
 	wrk := p.workersPool.Workers()
@@ -323,7 +393,7 @@ func (p *Plugin) Push(j *job.Job) error {
 	// get the pipeline for the job
 	pipe, ok := p.pipelines.Load(j.Options.Pipeline)
 	if !ok {
-		return errors.E(op, errors.Errorf("no such pipeline, requested: %s", j.Options.Pipeline))
+		return errors.E(op, &jobs.NoSuchPipelineError{Pipeline: j.Options.Pipeline})
 	}
 
 	// type conversion
@@ -339,6 +409,23 @@ func (p *Plugin) Push(j *job.Job) error {
 		j.Options.Priority = ppl.Priority()
 	}
 
+	setPushedAt(j, start)
+
+	// pipeline with the fallback
+	if br := p.breaker(ppl); br != nil {
+		target, err := p.failover(ppl, d.(jobs.Consumer), br, j)
+		if err != nil {
+			atomic.AddUint64(p.metrics.pushErr, 1)
+			p.log.Error("job push error", "error", err, "ID", j.Ident, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
+			return errors.E(op, err)
+		}
+
+		atomic.AddUint64(p.metrics.pushOk, 1)
+		p.events.emit(eventPushed, target.Name(), target.Driver(), j.Ident, j.Job, 0)
+		p.log.Debug("job pushed successfully", "ID", j.Ident, "pipeline", target.Name(), "driver", target.Driver(), "start", start, "elapsed", time.Since(start))
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
 	defer cancel()
 
@@ -350,47 +437,183 @@ func (p *Plugin) Push(j *job.Job) error {
 	}
 
 	atomic.AddUint64(p.metrics.pushOk, 1)
+	p.events.emit(eventPushed, ppl.Name(), ppl.Driver(), j.Ident, j.Job, 0)
 	p.log.Debug("job pushed successfully", "ID", j.Ident, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
 
 	return nil
 }
 
+// errNotPushed is reported for the jobs after the failed one when the batch push is stopped
+var errNotPushed = errors.Str("job is not pushed, previous job in the batch failed") //nolint:gochecknoglobals
+
+// PushBatch pushes the jobs and stops on the first failed job. Consecutive jobs for the same pipeline are pushed
+// in one call if the driver supports batches, all of them or none. If no job was pushed, the first push error is returned,
+// if only part of the jobs were pushed - *jobs.BatchError with the errors of the failed and not pushed jobs.
 func (p *Plugin) PushBatch(j []*job.Job) error {
+	errs := p.pushBatch(j, true)
+
+	be := &jobs.BatchError{Errors: make(map[int]error)}
+	for i := 0; i < len(errs); i++ {
+		if errs[i] != nil {
+			be.Errors[i] = errs[i]
+		}
+	}
+
+	switch len(be.Errors) {
+	case 0:
+		return nil
+	case len(j):
+		// nothing was pushed
+		return errs[0]
+	default:
+		return be
+	}
+}
+
+// PushBatchReport pushes all jobs and returns the per-job push errors (nil - job is pushed)
+func (p *Plugin) PushBatchReport(j []*job.Job) []error {
+	return p.pushBatch(j, false)
+}
+
+// pushBatch pushes the jobs, if stop is true - stops on the first failed job and the rest of the jobs are not pushed
+// (reported with the errNotPushed error)
+func (p *Plugin) pushBatch(j []*job.Job, stop bool) []error { //nolint:gocognit
 	const op = errors.Op("jobs_plugin_push")
 	start := time.Now()
+	errs := make([]error, len(j))
+
+	for i := 0; i < len(j); {
+		// consecutive jobs for the same pipeline are pushed together to preserve the order
+		k := i + 1
+		for ; k < len(j) && j[k].Options.Pipeline == j[i].Options.Pipeline; k++ {
+		}
 
-	for i := 0; i < len(j); i++ {
 		// get the pipeline for the job
 		pipe, ok := p.pipelines.Load(j[i].Options.Pipeline)
 		if !ok {
-			return errors.E(op, errors.Errorf("no such pipeline, requested: %s", j[i].Options.Pipeline))
+			failed(errs, i, k, errors.E(op, &jobs.NoSuchPipelineError{Pipeline: j[i].Options.Pipeline}))
+			if stop {
+				return skipped(errs, k)
+			}
+			i = k
+			continue
 		}
 
 		ppl := pipe.(*pipeline.Pipeline)
 
 		d, ok := p.consumers.Load(ppl.Name())
 		if !ok {
-			return errors.E(op, errors.Errorf("consumer not registered for the requested driver: %s", ppl.Driver()))
+			failed(errs, i, k, errors.E(op, errors.Errorf("consumer not registered for the requested driver: %s", ppl.Driver())))
+			if stop {
+				return skipped(errs, k)
+			}
+			i = k
+			continue
 		}
 
-		// if job has no priority, inherit it from the pipeline
-		if j[i].Options.Priority == 0 {
-			j[i].Options.Priority = ppl.Priority()
+		for n := i; n < k; n++ {
+			// if job has no priority, inherit it from the pipeline
+			if j[n].Options.Priority == 0 {
+				j[n].Options.Priority = ppl.Priority()
+			}
+
+			setPushedAt(j[n], start)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
-		err := d.(jobs.Consumer).Push(ctx, j[i])
-		if err != nil {
+		// jobs are pushed one by one, so every job might go to the fallback
+		if br := p.breaker(ppl); br != nil {
+			for ; i < k; i++ {
+				target, err := p.failover(ppl, d.(jobs.Consumer), br, j[i])
+				if err != nil {
+					atomic.AddUint64(p.metrics.pushErr, 1)
+					p.log.Error("job push batch error", "error", err, "ID", j[i].Ident, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
+					errs[i] = errors.E(op, err)
+					if stop {
+						return skipped(errs, i+1)
+					}
+					continue
+				}
+
+				atomic.AddUint64(p.metrics.pushOk, 1)
+				p.events.emit(eventPushed, target.Name(), target.Driver(), j[i].Ident, j[i].Job, 0)
+			}
+			continue
+		}
+
+		// driver supports batches
+		if bp, ok := d.(jobs.BatchPusher); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+			err := bp.PushBatch(ctx, j[i:k])
 			cancel()
-			atomic.AddUint64(p.metrics.pushErr, 1)
-			p.log.Error("job push batch error", "error", err, "ID", j[i].Ident, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
-			return errors.E(op, err)
+
+			var be *jobs.BatchError
+			switch {
+			case err == nil:
+			case stderr.As(err, &be) && be.Valid(k-i):
+				// only part of the jobs were pushed
+				for n, e := range be.Errors {
+					errs[i+n] = errors.E(op, e)
+				}
+			default:
+				// nothing was pushed, or the driver reported the wrong jobs indexes and the pushed jobs are unknown
+				failed(errs, i, k, errors.E(op, err))
+			}
+
+			if err != nil {
+				p.log.Error("job push batch error", "error", err, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
+			}
+
+			failedAny := false
+			for ; i < k; i++ {
+				if errs[i] != nil {
+					failedAny = true
+					atomic.AddUint64(p.metrics.pushErr, 1)
+					continue
+				}
+
+				atomic.AddUint64(p.metrics.pushOk, 1)
+				p.events.emit(eventPushed, ppl.Name(), ppl.Driver(), j[i].Ident, j[i].Job, 0)
+			}
+
+			if failedAny && stop {
+				return skipped(errs, k)
+			}
+			continue
 		}
 
-		cancel()
+		for ; i < k; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+			err := d.(jobs.Consumer).Push(ctx, j[i])
+			cancel()
+			if err != nil {
+				atomic.AddUint64(p.metrics.pushErr, 1)
+				p.log.Error("job push batch error", "error", err, "ID", j[i].Ident, "pipeline", ppl.Name(), "driver", ppl.Driver(), "start", start, "elapsed", time.Since(start))
+				errs[i] = errors.E(op, err)
+				if stop {
+					return skipped(errs, i+1)
+				}
+				continue
+			}
+
+			atomic.AddUint64(p.metrics.pushOk, 1)
+			p.events.emit(eventPushed, ppl.Name(), ppl.Driver(), j[i].Ident, j[i].Job, 0)
+		}
 	}
 
-	return nil
+	return errs
+}
+
+// failed sets the error for the jobs in the [from, to) range
+func failed(errs []error, from, to int, err error) {
+	for ; from < to; from++ {
+		errs[from] = err
+	}
+}
+
+// skipped sets the errNotPushed error for the not pushed jobs starting from the index
+func skipped(errs []error, from int) []error {
+	failed(errs, from, len(errs), errNotPushed)
+	return errs
 }
 
 func (p *Plugin) Pause(pp string) {
@@ -416,6 +639,8 @@ func (p *Plugin) Pause(pp string) {
 	defer cancel()
 	// redirect call to the underlying driver
 	d.(jobs.Consumer).Pause(ctx, ppl.Name())
+
+	p.setActive(ppl.Name(), false)
 }
 
 func (p *Plugin) Resume(pp string) {
@@ -441,10 +666,36 @@ func (p *Plugin) Resume(pp string) {
 	defer cancel()
 	// redirect call to the underlying driver
 	d.(jobs.Consumer).Resume(ctx, ppl.Name())
+
+	p.setActive(ppl.Name(), true)
 }
 
 // Declare a pipeline.
 func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
+	const op = errors.Op("jobs_plugin_declare")
+	err := p.declare(pipeline)
+	if err != nil {
+		return err
+	}
+
+	// persist only dynamic pipelines with the initialized driver
+	if p.Static(pipeline.Name()) || p.registry == nil {
+		return nil
+	}
+
+	if _, ok := p.consumers.Load(pipeline.Name()); !ok {
+		return nil
+	}
+
+	err = p.registry.store(pipeline, p.consumed(pipeline.Name()))
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (p *Plugin) declare(pipeline *pipeline.Pipeline) error {
 	const op = errors.Op("jobs_plugin_declare")
 	// driver for the pipeline (ie amqp, ephemeral, etc)
 	dr := pipeline.Driver()
@@ -456,7 +707,7 @@ func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
 	// we need here to initialize these drivers for the pipelines
 	if _, ok := p.jobConstructors[dr]; ok {
 		// init the driver from pipeline
-		initializedDriver, err := p.jobConstructors[dr].ConsumerFromPipeline(pipeline, p.queue)
+		initializedDriver, err := p.jobConstructors[dr].ConsumerFromPipeline(pipeline, p.queueFor(pipeline.Name()))
 		if err != nil {
 			return errors.E(op, err)
 		}
@@ -469,7 +720,7 @@ func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
 
 		// if pipeline initialized to be consumed, call Run on it
 		// but likely for the dynamic pipelines it should be started manually
-		if _, ok := p.consume[pipeline.Name()]; ok {
+		if p.consumed(pipeline.Name()) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
 			defer cancel()
 			err = initializedDriver.Run(ctx, pipeline)
@@ -482,6 +733,7 @@ func (p *Plugin) Declare(pipeline *pipeline.Pipeline) error {
 		p.consumers.Store(pipeline.Name(), initializedDriver)
 		// save the pipeline
 		p.pipelines.Store(pipeline.Name(), pipeline)
+		p.storeChildren(initializedDriver)
 	}
 
 	return nil
@@ -510,6 +762,9 @@ func (p *Plugin) Destroy(pp string) error {
 
 	// delete old pipeline
 	p.pipelines.LoadAndDelete(pp)
+	p.deleteChildren(d.(jobs.Consumer))
+	p.breakers.Delete(pp)
+	p.inflight.Delete(pp)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
 	err := d.(jobs.Consumer).Stop(ctx)
@@ -519,9 +774,55 @@ func (p *Plugin) Destroy(pp string) error {
 	}
 
 	cancel()
+
+	if p.registry != nil {
+		err = p.registry.remove(pp)
+		if err != nil {
+			return errors.E(op, err)
+		}
+	}
+
 	return nil
 }
 
+// storeChildren saves the child pipelines of the jobs.Parent driver
+func (p *Plugin) storeChildren(c jobs.Consumer) {
+	parent, ok := c.(jobs.Parent)
+	if !ok {
+		return
+	}
+
+	children := parent.Children()
+	for i := 0; i < len(children); i++ {
+		p.children.Store(children[i].Name(), children[i])
+	}
+}
+
+func (p *Plugin) deleteChildren(c jobs.Consumer) {
+	parent, ok := c.(jobs.Parent)
+	if !ok {
+		return
+	}
+
+	children := parent.Children()
+	for i := 0; i < len(children); i++ {
+		p.children.Delete(children[i].Name())
+	}
+}
+
+// lookup returns the declared or the child pipeline by name
+func (p *Plugin) lookup(pp string) (*pipeline.Pipeline, bool) {
+	if pipe, ok := p.pipelines.Load(pp); ok {
+		return pipe.(*pipeline.Pipeline), true
+	}
+
+	if pipe, ok := p.children.Load(pp); ok {
+		return pipe.(*pipeline.Pipeline), true
+	}
+
+	return nil, false
+}
+
 func (p *Plugin) List() []string {
 	out := make([]string, 0, 10)
 
@@ -534,6 +835,92 @@ func (p *Plugin) List() []string {
 	return out
 }
 
+// Static reports whether the pipeline is declared in the configuration
+func (p *Plugin) Static(pp string) bool {
+	p.cfgMu.RLock()
+	defer p.cfgMu.RUnlock()
+	_, ok := p.static[pp]
+	return ok
+}
+
+// consumed reports whether the pipeline is in the configured consume list
+func (p *Plugin) consumed(pp string) bool {
+	p.cfgMu.RLock()
+	defer p.cfgMu.RUnlock()
+	_, ok := p.consume[pp]
+	return ok
+}
+
+// replay declares pipelines persisted in the registry
+func (p *Plugin) replay() error {
+	const op = errors.Op("jobs_plugin_replay")
+	if p.cfg.Registry == nil {
+		return nil
+	}
+
+	var b backend
+	switch {
+	case p.cfg.Registry.Storage != "":
+		if p.kvProvider == nil {
+			return errors.E(op, errors.Str("kv plugin is not available for the pipelines registry"))
+		}
+
+		st, err := p.kvProvider.Storage(p.cfg.Registry.Storage)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		b = &kvBackend{storage: st, key: p.cfg.Registry.Key}
+	case p.cfg.Registry.File != "":
+		b = &fileBackend{path: p.cfg.Registry.File}
+	default:
+		return errors.E(op, errors.Str("pipelines registry should have a storage or a file"))
+	}
+
+	reg, err := newRegistry(b)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	p.registry = reg
+
+	for name, d := range reg.all() {
+		if p.Static(name) {
+			p.log.Warn("declared pipeline is shadowed by the configured one, skipping", "pipeline", name)
+			continue
+		}
+
+		pipe := d.Pipeline
+		err = p.declare(&pipe)
+		if err != nil {
+			p.log.Error("failed to replay the declared pipeline", "pipeline", name, "error", err)
+			continue
+		}
+
+		if p.consumed(name) || !d.Active {
+			p.log.Debug("declared pipeline replayed", "pipeline", name, "driver", pipe.Driver())
+			continue
+		}
+
+		p.Resume(name)
+		p.log.Debug("declared pipeline replayed and resumed", "pipeline", name, "driver", pipe.Driver())
+	}
+
+	return nil
+}
+
+// setActive persists the consuming state of the declared pipeline
+func (p *Plugin) setActive(pp string, active bool) {
+	if p.registry == nil {
+		return
+	}
+
+	err := p.registry.setActive(pp, active)
+	if err != nil {
+		p.log.Error("failed to persist the pipeline state", "pipeline", pp, "error", err)
+	}
+}
+
 func (p *Plugin) RPC() interface{} {
 	return &rpc{
 		log: p.log,
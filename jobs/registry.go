@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+)
+
+const defaultRegistryKey string = "rr_jobs_pipelines"
+
+// RegistryConfig configures persistence of the dynamically declared pipelines.
+// Only one of the Storage or File should be set.
+type RegistryConfig struct {
+	// Storage is the name of the KV storage (section in the kv plugin configuration)
+	Storage string `mapstructure:"storage"`
+
+	// Key in the KV storage, default: rr_jobs_pipelines
+	Key string `mapstructure:"key"`
+
+	// File is the path to the local JSON file
+	File string `mapstructure:"file"`
+}
+
+func (c *RegistryConfig) InitDefaults() {
+	if c.Key == "" {
+		c.Key = defaultRegistryKey
+	}
+}
+
+// declared is the persisted dynamically declared pipeline
+type declared struct {
+	Pipeline pipeline.Pipeline `json:"pipeline"`
+	// Active pipelines are consumed after the replay
+	Active bool `json:"active"`
+}
+
+// backend stores the whole set of the declared pipelines
+type backend interface {
+	load() ([]byte, error)
+	save(data []byte) error
+}
+
+// registry keeps dynamically declared pipelines in the KV storage or in the file,
+// so they might be replayed after the restart.
+type registry struct {
+	mu        sync.Mutex
+	backend   backend
+	pipelines map[string]*declared
+}
+
+func newRegistry(b backend) (*registry, error) {
+	const op = errors.Op("jobs_registry_load")
+	r := &registry{
+		backend:   b,
+		pipelines: make(map[string]*declared),
+	}
+
+	data, err := b.load()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if len(data) == 0 {
+		return r, nil
+	}
+
+	err = json.Unmarshal(data, &r.pipelines)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return r, nil
+}
+
+// all returns a copy of the declared pipelines
+func (r *registry) all() map[string]*declared {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]*declared, len(r.pipelines))
+	for k, v := range r.pipelines {
+		out[k] = &declared{Pipeline: v.Pipeline, Active: v.Active}
+	}
+
+	return out
+}
+
+func (r *registry) store(pipe *pipeline.Pipeline, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// copy the pipeline, original might be modified by the driver
+	cp := make(pipeline.Pipeline, len(*pipe))
+	for k, v := range *pipe {
+		cp[k] = v
+	}
+
+	r.pipelines[pipe.Name()] = &declared{Pipeline: cp, Active: active}
+	return r.flush()
+}
+
+func (r *registry) setActive(name string, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.pipelines[name]
+	if !ok || d.Active == active {
+		return nil
+	}
+
+	d.Active = active
+	return r.flush()
+}
+
+func (r *registry) remove(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.pipelines[name]; !ok {
+		return nil
+	}
+
+	delete(r.pipelines, name)
+	return r.flush()
+}
+
+func (r *registry) flush() error {
+	data, err := json.Marshal(r.pipelines)
+	if err != nil {
+		return err
+	}
+
+	return r.backend.save(data)
+}
+
+// kvBackend stores declared pipelines under the single key in the KV storage
+type kvBackend struct {
+	storage kv.Storage
+	key     string
+}
+
+func (k *kvBackend) load() ([]byte, error) {
+	has, err := k.storage.Has(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !has[k.key] {
+		return nil, nil
+	}
+
+	return k.storage.Get(k.key)
+}
+
+func (k *kvBackend) save(data []byte) error {
+	return k.storage.Set(&kvv1.Item{
+		Key:   k.key,
+		Value: data,
+	})
+}
+
+// fileBackend stores declared pipelines in the JSON file, the file is replaced atomically
+type fileBackend struct {
+	path string
+}
+
+func (f *fileBackend) load() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (f *fileBackend) save(data []byte) error {
+	err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmp := f.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
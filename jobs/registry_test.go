@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryFile(t *testing.T) {
+	b := &fileBackend{path: filepath.Join(t.TempDir(), "registry", "pipelines.json")}
+
+	r, err := newRegistry(b)
+	require.NoError(t, err)
+	assert.Empty(t, r.all())
+
+	pipe := pipeline.Pipeline{"name": "test-1", "driver": "memory", "prefetch": "100"}
+	require.NoError(t, r.store(&pipe, false))
+	require.NoError(t, r.store(&pipeline.Pipeline{"name": "test-2", "driver": "memory"}, true))
+	require.NoError(t, r.setActive("test-1", true))
+	require.NoError(t, r.remove("test-2"))
+
+	// reload from the file
+	r, err = newRegistry(b)
+	require.NoError(t, err)
+
+	all := r.all()
+	require.Len(t, all, 1)
+	assert.True(t, all["test-1"].Active)
+	assert.Equal(t, "memory", all["test-1"].Pipeline.Driver())
+	assert.Equal(t, "100", all["test-1"].Pipeline.String("prefetch", ""))
+}
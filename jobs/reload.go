@@ -0,0 +1,228 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+)
+
+// drain state polling interval
+const drainPollInterval = time.Millisecond * 100
+
+// ReloadConfig re-reads the configuration and applies the pipelines changes without restart:
+// added pipelines are registered, removed are destroyed, changed are drained and recreated.
+// Changes in the consume list resume or pause the pipelines. Other options (pool, num_pollers, etc.) require restart.
+func (p *Plugin) ReloadConfig() error {
+	const op = errors.Op("jobs_plugin_reload_config")
+	rl, ok := p.cfgPlugin.(config.Reloader)
+	if !ok {
+		return errors.E(op, errors.Str("configuration provider does not support reload"))
+	}
+
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	err := rl.Reload()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	cfg := &Config{}
+	err = p.cfgPlugin.UnmarshalKey(PluginName, &cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	cfg.InitDefaults()
+
+	consume := make(map[string]struct{}, len(cfg.Consume))
+	for i := 0; i < len(cfg.Consume); i++ {
+		consume[cfg.Consume[i]] = struct{}{}
+	}
+
+	err = checkFallbacks(cfg.Pipelines, consume)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	// pipelines registered during this reload, consume state is already applied
+	recreated := make(map[string]struct{})
+
+	// removed and changed pipelines, static and consume are modified only here (under the reloadMu),
+	// so they are read without the cfgMu
+	for name := range p.static {
+		newPipe, ok := cfg.Pipelines[name]
+		if ok && reflect.DeepEqual(p.cfg.Pipelines[name], newPipe) {
+			continue
+		}
+
+		err = p.drainAndDestroy(name)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		p.cfgMu.Lock()
+		delete(p.static, name)
+		p.cfgMu.Unlock()
+		if !ok {
+			p.log.Info("pipeline removed from the configuration and destroyed", "pipeline", name)
+		}
+	}
+
+	// added and changed pipelines
+	for name, pipe := range cfg.Pipelines {
+		if _, ok := p.static[name]; ok {
+			continue
+		}
+
+		// dynamically declared pipeline is replaced by the configured one
+		if _, ok := p.pipelines.Load(name); ok {
+			p.log.Warn("declared pipeline is replaced by the configured one", "pipeline", name)
+			err = p.drainAndDestroy(name)
+			if err != nil {
+				return errors.E(op, err)
+			}
+		}
+
+		_, consumed := consume[name]
+		err = p.initPipeline(name, pipe, consumed)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		p.cfgMu.Lock()
+		p.static[name] = struct{}{}
+		p.cfgMu.Unlock()
+		recreated[name] = struct{}{}
+		p.log.Info("pipeline registered from the configuration", "pipeline", name, "driver", pipe.Driver())
+	}
+
+	// consume list changes for the unchanged pipelines
+	for name := range p.static {
+		if _, ok := recreated[name]; ok {
+			continue
+		}
+
+		_, was := p.consume[name]
+		_, now := consume[name]
+
+		switch {
+		case now && !was:
+			p.Resume(name)
+		case was && !now:
+			p.Pause(name)
+		}
+	}
+
+	p.cfgMu.Lock()
+	p.consume = consume
+	p.cfg.Pipelines = cfg.Pipelines
+	p.cfg.Consume = cfg.Consume
+	p.cfgMu.Unlock()
+
+	return nil
+}
+
+// initPipeline initializes the driver for the pipeline from the configuration
+func (p *Plugin) initPipeline(name string, pipe *pipeline.Pipeline, consume bool) error {
+	const op = errors.Op("jobs_plugin_init_pipeline")
+	constructor, ok := p.jobConstructors[pipe.Driver()]
+	if !ok {
+		return errors.E(op, errors.Errorf("no such driver: %s, pipeline: %s", pipe.Driver(), name))
+	}
+
+	// config key for the particular sub-driver jobs.pipelines.test-local
+	configKey := fmt.Sprintf("%s.%s.%s", PluginName, pipelines, name)
+
+	initializedDriver, err := constructor.ConsumerFromConfig(configKey, p.queueFor(name))
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = initializedDriver.Register(context.Background(), pipe)
+	if err != nil {
+		return errors.E(op, errors.Errorf("pipe register failed for the driver: %s with pipe name: %s", pipe.Driver(), pipe.Name()))
+	}
+
+	if consume {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(p.cfg.Timeout))
+		err = initializedDriver.Run(ctx, pipe)
+		cancel()
+		if err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	p.consumers.Store(name, initializedDriver)
+	p.pipelines.Store(name, pipe)
+	p.storeChildren(initializedDriver)
+
+	return nil
+}
+
+// drainAndDestroy waits for the pipeline jobs and destroys the pipeline. The consumed pipeline processes its active
+// jobs first, jobs of the in-memory drivers are kept by the driver instance and would be lost with it. Then the pipeline
+// is paused and the jobs already taken from the driver (in the priority queue or in the workers) and the reserved jobs
+// are waited for, so they are acknowledged via the live driver. All steps share the same deadline (jobs.timeout).
+func (p *Plugin) drainAndDestroy(name string) error {
+	d, ok := p.consumers.Load(name)
+	if !ok {
+		return p.Destroy(name)
+	}
+
+	c := d.(jobs.Consumer)
+	deadline := time.Now().Add(time.Second * time.Duration(p.cfg.Timeout))
+
+	if st := p.drainState(c, deadline); st != nil && st.Ready {
+		p.waitDrained(c, deadline, func(st *jobs.State) bool {
+			return st.Active == 0 && p.inflightJobs(name) == 0
+		})
+	}
+
+	p.Pause(name)
+
+	st := p.waitDrained(c, deadline, func(st *jobs.State) bool {
+		return st.Reserved == 0 && p.inflightJobs(name) == 0
+	})
+
+	if st != nil && (st.Active > 0 || st.Delayed > 0) {
+		p.log.Warn("pipeline destroyed with the not processed jobs, jobs of the in-memory drivers are lost", "pipeline", name, "active", st.Active, "delayed", st.Delayed)
+	}
+
+	if n := p.inflightJobs(name); n > 0 {
+		p.log.Warn("pipeline destroyed with the in-flight jobs, they might be redelivered", "pipeline", name, "in-flight", n)
+	}
+
+	return p.Destroy(name)
+}
+
+// waitDrained polls the driver state until the condition is met or the deadline is exceeded, returns the last state
+func (p *Plugin) waitDrained(c jobs.Consumer, deadline time.Time, drained func(st *jobs.State) bool) *jobs.State {
+	for {
+		st := p.drainState(c, deadline)
+		if st == nil || drained(st) || !time.Now().Add(drainPollInterval).Before(deadline) {
+			return st
+		}
+
+		time.Sleep(drainPollInterval)
+	}
+}
+
+func (p *Plugin) drainState(c jobs.Consumer, deadline time.Time) *jobs.State {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	st, err := c.State(ctx)
+	if err != nil {
+		p.log.Warn("failed to get the pipeline state during the drain", "error", err)
+		return nil
+	}
+
+	return st
+}
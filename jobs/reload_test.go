@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	pq "github.com/spiral/roadrunner/v2/priority_queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testItem struct {
+	id    string
+	acked func()
+}
+
+func (i *testItem) ID() string               { return i.id }
+func (i *testItem) Priority() int64          { return 10 }
+func (i *testItem) Body() []byte             { return nil }
+func (i *testItem) Context() ([]byte, error) { return nil, nil }
+
+// localConsumer keeps the jobs in memory like the memory driver, jobs are moved into the priority queue only while it's consumed
+type localConsumer struct {
+	jobs.Consumer
+
+	mu     sync.Mutex
+	q      pq.Queue
+	local  []*testItem
+	ready  bool
+	active int64
+	// number of the acknowledged jobs when the consumer was stopped, -1 - not stopped
+	stoppedAt int64
+	acked     int64
+}
+
+func newLocalConsumer(q pq.Queue, n int) *localConsumer {
+	c := &localConsumer{q: q, ready: true, active: int64(n), stoppedAt: -1}
+	for i := 0; i < n; i++ {
+		c.local = append(c.local, &testItem{id: strconv.Itoa(i), acked: func() {
+			atomic.AddInt64(&c.active, -1)
+			atomic.AddInt64(&c.acked, 1)
+		}})
+	}
+
+	go func() {
+		for {
+			c.mu.Lock()
+			if c.stoppedAt >= 0 {
+				c.mu.Unlock()
+				return
+			}
+
+			if c.ready && len(c.local) > 0 {
+				c.q.Insert(c.local[0])
+				c.local = c.local[1:]
+			}
+			c.mu.Unlock()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	return c
+}
+
+func (c *localConsumer) State(_ context.Context) (*jobs.State, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &jobs.State{Pipeline: "test", Active: atomic.LoadInt64(&c.active), Ready: c.ready}, nil
+}
+
+func (c *localConsumer) Pause(_ context.Context, _ string) {
+	c.mu.Lock()
+	c.ready = false
+	c.mu.Unlock()
+}
+
+func (c *localConsumer) Stop(_ context.Context) error {
+	c.mu.Lock()
+	c.stoppedAt = atomic.LoadInt64(&c.acked)
+	c.local = nil
+	c.mu.Unlock()
+	return nil
+}
+
+func TestDrainAndDestroy(t *testing.T) {
+	p := &Plugin{
+		cfg:   &Config{Timeout: 10},
+		log:   logger.NewZapAdapter(zap.NewNop()),
+		queue: pq.NewBinHeap(1000),
+	}
+
+	p.pipelines.Store("test", &pipeline.Pipeline{pipelineName: "test", "driver": "memory"})
+	c := newLocalConsumer(p.queueFor("test"), 50)
+	p.consumers.Store("test", c)
+
+	// slow listener
+	go func() {
+		for {
+			jb, done := unwrap(p.queue.ExtractMin())
+			time.Sleep(time.Millisecond * 5)
+			jb.(*testItem).acked()
+			done()
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+	require.NoError(t, p.drainAndDestroy("test"))
+
+	// all jobs are processed before the driver is stopped
+	assert.Equal(t, int64(50), atomic.LoadInt64(&c.stoppedAt))
+	assert.Equal(t, int64(0), p.inflightJobs("test"))
+
+	_, ok := p.consumers.Load("test")
+	assert.False(t, ok)
+}
@@ -2,8 +2,12 @@ package jobs
 
 import (
 	"context"
+	stderr "errors"
+	"sort"
+	"time"
 
 	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
 	jobsv1beta "github.com/spiral/roadrunner-plugins/v2/api/proto/jobs/v1beta"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
@@ -33,7 +37,10 @@ func (r *rpc) Push(j *jobsv1beta.PushRequest, _ *jobsv1beta.Empty) error {
 	return nil
 }
 
-func (r *rpc) PushBatch(j *jobsv1beta.PushBatchRequest, _ *jobsv1beta.Empty) error {
+// PushBatch pushes the jobs and returns the push error if no job was pushed. If only part of the jobs were pushed,
+// the per-job results are returned in the response. With the report option all jobs are pushed
+// and the per-job results are always returned.
+func (r *rpc) PushBatch(j *jobsv1beta.PushBatchRequest, resp *jobsv1beta.PushBatchResponse) error {
 	const op = errors.Op("rpc_push_batch")
 
 	l := len(j.GetJobs())
@@ -46,9 +53,33 @@ func (r *rpc) PushBatch(j *jobsv1beta.PushBatchRequest, _ *jobsv1beta.Empty) err
 		batch[i] = from(j.GetJobs()[i])
 	}
 
-	err := r.p.PushBatch(batch)
-	if err != nil {
-		return errors.E(op, err)
+	var errs []error
+	if j.GetReport() {
+		errs = r.p.PushBatchReport(batch)
+	} else {
+		err := r.p.PushBatch(batch)
+		var be *jobs.BatchError
+		if !stderr.As(err, &be) {
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			return nil
+		}
+
+		// partly pushed batch, report which jobs were pushed
+		errs = make([]error, l)
+		for i, e := range be.Errors {
+			errs[i] = e
+		}
+	}
+
+	resp.Results = make([]*jobsv1beta.PushResult, l)
+	for i := 0; i < l; i++ {
+		resp.Results[i] = &jobsv1beta.PushResult{Id: batch[i].Ident}
+		if errs[i] != nil {
+			resp.Results[i].Error = errs[i].Error()
+		}
 	}
 
 	return nil
@@ -114,6 +145,54 @@ func (r *rpc) Destroy(req *jobsv1beta.Pipelines, resp *jobsv1beta.Pipelines) err
 	return nil
 }
 
+// Events returns the jobs lifecycle events after the requested sequence number, waits for the new events if there are none.
+// Clients tail the stream by passing the returned last sequence number as the next after.
+func (r *rpc) Events(req *jobsv1beta.EventsRequest, resp *jobsv1beta.Events) error {
+	const op = errors.Op("rpc_events")
+	if r.p.events == nil {
+		return errors.E(op, errors.Str("jobs events are disabled, add the events section to the jobs configuration"))
+	}
+
+	wait := time.Millisecond * time.Duration(req.GetWait())
+	// do not wait longer than the plugin timeout
+	if max := time.Second * time.Duration(r.p.cfg.Timeout); wait > max {
+		wait = max
+	}
+
+	resp.Events, resp.Last = r.p.events.read(context.Background(), req.GetAfter(), int(req.GetLimit()), wait)
+	return nil
+}
+
+// Reload applies the pipelines changes from the configuration file
+func (r *rpc) Reload(_ *jobsv1beta.Empty, _ *jobsv1beta.Empty) error {
+	const op = errors.Op("rpc_reload")
+	err := r.p.ReloadConfig()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// ListDetailed returns all pipelines with their drivers, dynamically declared pipelines are marked
+func (r *rpc) ListDetailed(_ *jobsv1beta.Empty, resp *jobsv1beta.PipelinesInfo) error {
+	r.p.pipelines.Range(func(key, value interface{}) bool {
+		name := key.(string)
+		resp.Pipelines = append(resp.Pipelines, &jobsv1beta.PipelineInfo{
+			Name:    name,
+			Driver:  value.(*pipeline.Pipeline).Driver(),
+			Dynamic: !r.p.Static(name),
+		})
+		return true
+	})
+
+	sort.Slice(resp.Pipelines, func(i, j int) bool {
+		return resp.Pipelines[i].Name < resp.Pipelines[j].Name
+	})
+
+	return nil
+}
+
 func (r *rpc) Stat(_ *jobsv1beta.Empty, resp *jobsv1beta.Stats) error {
 	const op = errors.Op("rpc_stats")
 	state, err := r.p.JobsState(context.Background())
@@ -129,7 +208,9 @@ func (r *rpc) Stat(_ *jobsv1beta.Empty, resp *jobsv1beta.Stats) error {
 			Active:   state[i].Active,
 			Delayed:  state[i].Delayed,
 			Reserved: state[i].Reserved,
+			Buried:   state[i].Buried,
 			Ready:    state[i].Ready,
+			Extra:    state[i].Extra,
 		})
 	}
 
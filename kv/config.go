@@ -1,6 +1,12 @@
 package kv
 
+// snapshotsDir is the kv section option, not a storage
+const snapshotsDir string = "snapshots_dir"
+
 // Config represents general storage configuration with keys as the user defined kv-names and values as the constructors
 type Config struct {
 	Data map[string]interface{} `mapstructure:"kv"`
+	// SnapshotsDir is the absolute path of the directory with the Export and Import files (kv.snapshots_dir),
+	// snapshots are disabled if not configured
+	SnapshotsDir string
 }
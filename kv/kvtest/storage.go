@@ -0,0 +1,323 @@
+// Package kvtest provides the in-memory kv.Storage for the tests of the storages wrappers (watch, tiered, mirror):
+//
+//	st := kvtest.NewStorage()
+//	require.NoError(t, st.Set(&kvv1.Item{Key: "a", Value: []byte("a")}))
+//	assert.Equal(t, []byte("a"), st.Value("a"))
+//
+// The storage doesn't expire the keys, timeouts are only stored and returned by the TTL.
+package kvtest
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+)
+
+// Storage is the in-memory kv.Storage and kv.Tagger, safe for the concurrent use
+type Storage struct {
+	mu      sync.Mutex
+	items   map[string]*kvv1.Item
+	err     error
+	stopped bool
+}
+
+var (
+	_ kv.Storage = (*Storage)(nil)
+	_ kv.Tagger  = (*Storage)(nil)
+)
+
+func NewStorage() *Storage {
+	return &Storage{items: make(map[string]*kvv1.Item)}
+}
+
+// Fail makes the writes return the err, nil - writes are applied again
+func (s *Storage) Fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Item returns the stored item, nil if the key doesn't exist
+func (s *Storage) Item(key string) *kvv1.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items[key]
+}
+
+// Value returns the key value, nil if the key doesn't exist
+func (s *Storage) Value(key string) []byte {
+	if it := s.Item(key); it != nil {
+		return it.Value
+	}
+
+	return nil
+}
+
+// Values returns the values of all keys
+func (s *Storage) Values() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string][]byte, len(s.items))
+	for k, it := range s.items {
+		m[k] = it.Value
+	}
+	return m
+}
+
+// Stopped reports whether the storage was stopped
+func (s *Storage) Stopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *Storage) Has(keys ...string) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string]bool)
+	for _, k := range keys {
+		if _, ok := s.items[k]; ok {
+			m[k] = true
+		}
+	}
+	return m, nil
+}
+
+func (s *Storage) Get(key string) ([]byte, error) {
+	return s.Value(key), nil
+}
+
+func (s *Storage) MGet(keys ...string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string][]byte)
+	for _, k := range keys {
+		if it, ok := s.items[k]; ok {
+			m[k] = it.Value
+		}
+	}
+	return m, nil
+}
+
+func (s *Storage) Set(items ...*kvv1.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	for _, it := range items {
+		s.items[it.Key] = it
+	}
+	return nil
+}
+
+func (s *Storage) MExpire(items ...*kvv1.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	for _, it := range items {
+		if old, ok := s.items[it.Key]; ok {
+			s.items[it.Key] = &kvv1.Item{Key: it.Key, Value: old.Value, Timeout: it.Timeout, Tags: old.Tags}
+		}
+	}
+	return nil
+}
+
+func (s *Storage) TTL(keys ...string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string]string)
+	for _, k := range keys {
+		if it, ok := s.items[k]; ok && it.Timeout != "" {
+			m[k] = it.Timeout
+		}
+	}
+	return m, nil
+}
+
+func (s *Storage) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.items = make(map[string]*kvv1.Item)
+	return nil
+}
+
+func (s *Storage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	for _, k := range keys {
+		delete(s.items, k)
+	}
+	return nil
+}
+
+func (s *Storage) Incr(key string, delta int64, timeout string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	var v int64
+	it := &kvv1.Item{Key: key, Timeout: timeout}
+	if old, ok := s.items[key]; ok {
+		var err error
+		v, err = strconv.ParseInt(string(old.Value), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("value is not an integer: %s", key)
+		}
+
+		// keep the existing TTL and tags
+		if old.Timeout != "" {
+			it.Timeout = old.Timeout
+		}
+		it.Tags = old.Tags
+	}
+
+	v += delta
+	it.Value = []byte(strconv.FormatInt(v, 10))
+	s.items[key] = it
+	return v, nil
+}
+
+func (s *Storage) Decr(key string, delta int64, timeout string) (int64, error) {
+	return s.Incr(key, -delta, timeout)
+}
+
+func (s *Storage) SetNX(item *kvv1.Item) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return false, s.err
+	}
+
+	if _, ok := s.items[item.Key]; ok {
+		return false, nil
+	}
+
+	s.items[item.Key] = item
+	return true, nil
+}
+
+func (s *Storage) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return false, s.err
+	}
+
+	old, ok := s.items[key]
+	if !ok || !bytes.Equal(old.Value, oldValue) {
+		return false, nil
+	}
+
+	s.items[key] = &kvv1.Item{Key: key, Value: newValue, Timeout: timeout, Tags: old.Tags}
+	return true, nil
+}
+
+// Keys returns the sorted keys with the prefix, the cursor is the number of the already returned keys
+func (s *Storage) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.items))
+	for k := range s.items {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Strings(keys)
+
+	from := 0
+	if cursor != "" {
+		var err error
+		from, err = strconv.Atoi(cursor)
+		if err != nil || from < 0 || from > len(keys) {
+			return nil, "", errors.Errorf("invalid cursor: %s", cursor)
+		}
+	}
+
+	keys = keys[from:]
+	if limit <= 0 || limit >= len(keys) {
+		return keys, "", nil
+	}
+
+	return keys[:limit], strconv.Itoa(from + limit), nil
+}
+
+func (s *Storage) InvalidateTags(tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	for k, it := range s.items {
+		for _, t := range it.Tags {
+			if contains(tags, t) {
+				delete(s.items, k)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Storage) Tags(keys ...string) (map[string][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string][]string)
+	for _, k := range keys {
+		if it, ok := s.items[k]; ok && len(it.Tags) > 0 {
+			m[k] = it.Tags
+		}
+	}
+	return m, nil
+}
+
+func (s *Storage) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+}
+
+func contains(tags []string, tag string) bool {
+	for i := range tags {
+		if tags[i] == tag {
+			return true
+		}
+	}
+
+	return false
+}
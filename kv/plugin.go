@@ -2,10 +2,12 @@ package kv
 
 import (
 	"fmt"
+	"path/filepath"
 
 	endure "github.com/spiral/endure/pkg/container"
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
 	"github.com/spiral/roadrunner-plugins/v2/config"
 	"github.com/spiral/roadrunner-plugins/v2/logger"
 )
@@ -26,6 +28,10 @@ type Plugin struct {
 	constructors map[string]kv.Constructor
 	// storages contains user-defined storages, such as boltdb-north, memcached-us and so on.
 	storages map[string]kv.Storage
+	// watchers contains the changes streams of the storages with the watch section
+	watchers map[string]*watchStream
+	// publisher is used to publish the storages changes, nil if the broadcast plugin is not available
+	publisher broadcast.Publisher
 	// KV configuration
 	cfg       Config
 	cfgPlugin config.Configurer
@@ -41,8 +47,25 @@ func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
 	if err != nil {
 		return errors.E(op, err)
 	}
+
+	if v, ok := p.cfg.Data[snapshotsDir]; ok {
+		dir, ok := v.(string)
+		if !ok {
+			return errors.E(op, errors.Errorf("%s should be a string", snapshotsDir))
+		}
+
+		if dir != "" {
+			p.cfg.SnapshotsDir, err = filepath.Abs(dir)
+			if err != nil {
+				return errors.E(op, err)
+			}
+		}
+
+		delete(p.cfg.Data, snapshotsDir)
+	}
 	p.constructors = make(map[string]kv.Constructor, 5)
 	p.storages = make(map[string]kv.Storage, 5)
+	p.watchers = make(map[string]*watchStream)
 	p.log = log
 	p.cfgPlugin = cfg
 	return nil
@@ -97,6 +120,12 @@ func (p *Plugin) Serve() chan error {
 					return errCh
 				}
 
+				storage, err = p.watch(k, storage)
+				if err != nil {
+					errCh <- errors.E(op, err)
+					return errCh
+				}
+
 				// save the storage
 				p.storages[k] = storage
 				// try global then
@@ -113,6 +142,12 @@ func (p *Plugin) Serve() chan error {
 					return errCh
 				}
 
+				storage, err = p.watch(k, storage)
+				if err != nil {
+					errCh <- errors.E(op, err)
+					return errCh
+				}
+
 				// save the storage
 				p.storages[k] = storage
 			default:
@@ -127,6 +162,11 @@ func (p *Plugin) Serve() chan error {
 }
 
 func (p *Plugin) Stop() error {
+	for k := range p.watchers {
+		p.watchers[k].stop()
+		delete(p.watchers, k)
+	}
+
 	// stop all attached storages
 	for k := range p.storages {
 		p.storages[k].Stop()
@@ -144,6 +184,7 @@ func (p *Plugin) Stop() error {
 func (p *Plugin) Collects() []interface{} {
 	return []interface{}{
 		p.GetAllStorageDrivers,
+		p.CollectPublisher,
 	}
 }
 
@@ -152,6 +193,57 @@ func (p *Plugin) GetAllStorageDrivers(name endure.Named, constructor kv.Construc
 	p.constructors[name.Name()] = constructor
 }
 
+// CollectPublisher collects the broadcast plugin, used for the storages changes
+func (p *Plugin) CollectPublisher(_ endure.Named, pub broadcast.Publisher) {
+	p.publisher = pub
+}
+
+// watch wraps the storage with the watch section to emit the changes
+func (p *Plugin) watch(name string, st kv.Storage) (kv.Storage, error) {
+	const op = errors.Op("kv_plugin_watch")
+	key := fmt.Sprintf("%s.%s.%s", PluginName, name, watch)
+	if !p.cfgPlugin.Has(key) {
+		return st, nil
+	}
+
+	wCfg := &WatchConfig{}
+	err := p.cfgPlugin.UnmarshalKey(key, wCfg)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	wCfg.InitDefaults()
+
+	if wCfg.Broadcast && p.publisher == nil {
+		p.log.Warn("broadcast plugin is not available, storage changes will not be published", "storage", name)
+	}
+
+	ws := newWatchStream(name, wCfg, p.publisher, p.log)
+	w, err := newWatched(st, ws)
+	if err != nil {
+		ws.stop()
+		return nil, errors.E(op, err)
+	}
+
+	if w.native {
+		p.log.Debug("storage native changes notifications are used", "storage", name)
+	}
+
+	p.watchers[name] = ws
+	return w, nil
+}
+
+// Storage returns configured storage by name, storages are available after the Serve
+func (p *Plugin) Storage(name string) (kv.Storage, error) {
+	const op = errors.Op("kv_plugin_storage")
+	st, ok := p.storages[name]
+	if !ok {
+		return nil, errors.E(op, errors.Errorf("no such storage: %s", name))
+	}
+
+	return st, nil
+}
+
 // RPC returns associated rpc service.
 func (p *Plugin) RPC() interface{} {
 	return &rpc{srv: p, log: p.log, storages: p.storages}
@@ -1,12 +1,18 @@
 package kv
 
 import (
+	"context"
+	"time"
+
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/api/kv"
 	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
 	"github.com/spiral/roadrunner-plugins/v2/logger"
 )
 
+// default number of the keys in the Keys page
+const defaultKeysLimit int = 100
+
 // Wrapper for the plugin
 type rpc struct {
 	// all available storages
@@ -178,3 +184,205 @@ func (r *rpc) Clear(in *kvv1.Request, _ *kvv1.Response) error {
 
 	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
 }
+
+// Incr atomically increments the counter, zero delta means 1
+func (r *rpc) Incr(in *kvv1.CounterRequest, out *kvv1.CounterResponse) error {
+	const op = errors.Op("rpc_incr")
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		v, err := st.Incr(in.GetKey(), counterDelta(in), in.GetTimeout())
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		out.Value = v
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// Decr atomically decrements the counter, zero delta means 1
+func (r *rpc) Decr(in *kvv1.CounterRequest, out *kvv1.CounterResponse) error {
+	const op = errors.Op("rpc_decr")
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		v, err := st.Decr(in.GetKey(), counterDelta(in), in.GetTimeout())
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		out.Value = v
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// SetNX sets the items only if the keys don't exist, response contains only the keys which were set
+func (r *rpc) SetNX(in *kvv1.Request, out *kvv1.Response) error {
+	const op = errors.Op("rpc_setnx")
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		out.Items = make([]*kvv1.Item, 0, len(in.GetItems()))
+		for i := 0; i < len(in.GetItems()); i++ {
+			applied, err := st.SetNX(in.Items[i])
+			if err != nil {
+				return errors.E(op, err)
+			}
+
+			if applied {
+				out.Items = append(out.Items, &kvv1.Item{
+					Key: in.Items[i].Key,
+				})
+			}
+		}
+
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// CompareAndSwap replaces the value, not applied swap is not an error
+func (r *rpc) CompareAndSwap(in *kvv1.CompareAndSwapRequest, out *kvv1.CompareAndSwapResponse) error {
+	const op = errors.Op("rpc_compare_and_swap")
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		applied, err := st.CompareAndSwap(in.GetKey(), in.GetOldValue(), in.GetNewValue(), in.GetTimeout())
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		out.Applied = applied
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// Keys returns the page of the keys with the prefix
+func (r *rpc) Keys(in *kvv1.KeysRequest, out *kvv1.KeysResponse) error {
+	const op = errors.Op("rpc_keys")
+
+	limit := int(in.GetLimit())
+	if limit <= 0 {
+		limit = defaultKeysLimit
+	}
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		keys, cursor, err := st.Keys(in.GetPrefix(), in.GetCursor(), limit)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		out.Keys = keys
+		out.Cursor = cursor
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// InvalidateTags deletes all keys tagged with the provided tags
+func (r *rpc) InvalidateTags(in *kvv1.TagsRequest, _ *kvv1.Response) error {
+	const op = errors.Op("rpc_invalidate_tags")
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		err := st.InvalidateTags(in.GetTags()...)
+		if err != nil {
+			return errors.E(op, err)
+		}
+
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// Watch returns the storage changes after the requested sequence number, waits for the changes up to the wait
+// milliseconds (max: 30s)
+func (r *rpc) Watch(in *kvv1.WatchRequest, out *kvv1.Events) error {
+	const op = errors.Op("rpc_watch")
+
+	ws, ok := r.srv.watchers[in.GetStorage()]
+	if !ok {
+		if _, exists := r.storages[in.GetStorage()]; exists {
+			return errors.E(op, errors.Errorf("changes notifications are disabled, add the watch section to the %s storage configuration", in.GetStorage()))
+		}
+
+		return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+	}
+
+	wait := time.Millisecond * time.Duration(in.GetWait())
+	if wait > maxWatchWait {
+		wait = maxWatchWait
+	}
+
+	out.Events, out.Last = ws.read(context.Background(), in.GetPrefix(), in.GetAfter(), int(in.GetLimit()), wait)
+	return nil
+}
+
+// Export writes the storage keys with the prefix into the JSONL file in the snapshots directory on the RoadRunner side
+func (r *rpc) Export(in *kvv1.SnapshotRequest, out *kvv1.SnapshotResponse) error {
+	const op = errors.Op("rpc_export")
+
+	file, err := snapshotPath(r.srv.cfg.SnapshotsDir, in.GetFile())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		n, errE := export(st, in.GetPrefix(), file, snapshotChunk(in))
+		if errE != nil {
+			return errors.E(op, errE)
+		}
+
+		r.log.Debug("storage exported", "storage", in.GetStorage(), "file", in.GetFile(), "keys", n)
+		out.Keys = n
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+// Import sets the keys with the prefix from the JSONL file in the snapshots directory written by the Export,
+// expired keys are skipped
+func (r *rpc) Import(in *kvv1.SnapshotRequest, out *kvv1.SnapshotResponse) error {
+	const op = errors.Op("rpc_import")
+
+	file, err := snapshotPath(r.srv.cfg.SnapshotsDir, in.GetFile())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if st, exists := r.storages[in.GetStorage()]; exists {
+		n, skipped, errI := restore(st, in.GetPrefix(), file, snapshotChunk(in))
+		if errI != nil {
+			return errors.E(op, errors.Errorf("imported keys: %d, error: %v", n, errI))
+		}
+
+		r.log.Debug("storage imported", "storage", in.GetStorage(), "file", in.GetFile(), "keys", n, "skipped", skipped)
+		out.Keys = n
+		out.Skipped = skipped
+		return nil
+	}
+
+	return errors.E(op, errors.Errorf("no such storage: %s", in.GetStorage()))
+}
+
+func snapshotChunk(in *kvv1.SnapshotRequest) int {
+	if in.GetChunk() <= 0 {
+		return defaultSnapshotChunk
+	}
+
+	return int(in.GetChunk())
+}
+
+func counterDelta(in *kvv1.CounterRequest) int64 {
+	if in.GetDelta() == 0 {
+		return 1
+	}
+
+	return in.GetDelta()
+}
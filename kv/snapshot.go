@@ -0,0 +1,240 @@
+package kv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+)
+
+// default number of the keys read or written at once
+const defaultSnapshotChunk int = 100
+
+// snapshotPath returns the path of the snapshot file relative to the snapshots directory, paths outside the directory
+// are rejected
+func snapshotPath(dir, file string) (string, error) {
+	if dir == "" {
+		return "", errors.Errorf("snapshots are disabled, %s.%s is not configured", PluginName, snapshotsDir)
+	}
+
+	if file == "" {
+		return "", errors.Str("no file provided")
+	}
+
+	if filepath.IsAbs(file) {
+		return "", errors.Errorf("file should be relative to the snapshots directory: %s", file)
+	}
+
+	path := filepath.Join(dir, file)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("file is outside the snapshots directory: %s", file)
+	}
+
+	return path, nil
+}
+
+// export writes the keys with the prefix into the JSONL file, one kvv1.Item (with the timeout and tags) per line.
+// The file is written into the temporary file first and replaced only if the export succeeded.
+func export(st kv.Storage, prefix, file string, chunk int) (int64, error) {
+	const op = errors.Op("kv_export")
+	if file == "" {
+		return 0, errors.E(op, errors.Str("no file provided"))
+	}
+
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	n, err := writeItems(st, prefix, chunk, f)
+	errC := f.Close()
+	if err == nil {
+		err = errC
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, errors.E(op, err)
+	}
+
+	err = os.Rename(tmp, file)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, errors.E(op, err)
+	}
+
+	return n, nil
+}
+
+func writeItems(st kv.Storage, prefix string, chunk int, w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+
+	var n int64
+	cursor := ""
+	for {
+		keys, next, err := st.Keys(prefix, cursor, chunk)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(keys) > 0 {
+			values, err := st.MGet(keys...)
+			if err != nil {
+				return 0, err
+			}
+
+			ttls, err := st.TTL(keys...)
+			if err != nil {
+				return 0, err
+			}
+
+			tt, err := tags(st, keys...)
+			if err != nil {
+				return 0, err
+			}
+
+			now := time.Now()
+			for i := range keys {
+				// expired or deleted after the listing
+				v, ok := values[keys[i]]
+				if !ok {
+					continue
+				}
+
+				data, err := json.Marshal(&kvv1.Item{Key: keys[i], Value: v, Timeout: kv.Timeout(ttls[keys[i]], now), Tags: tt[keys[i]]})
+				if err != nil {
+					return 0, err
+				}
+
+				_, err = bw.Write(append(data, '\n'))
+				if err != nil {
+					return 0, err
+				}
+
+				n++
+			}
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	return n, bw.Flush()
+}
+
+// tags returns the tags of the keys if the storage is a kv.Tagger, otherwise the items are exported without the tags
+func tags(st kv.Storage, keys ...string) (map[string][]string, error) {
+	t, ok := st.(kv.Tagger)
+	if !ok {
+		return map[string][]string{}, nil
+	}
+
+	return t.Tags(keys...)
+}
+
+// restore reads the items with the prefix from the JSONL file written by the export and sets them in chunks.
+// Returns the number of the imported keys and the number of the skipped expired keys.
+func restore(st kv.Storage, prefix, file string, chunk int) (int64, int64, error) {
+	const op = errors.Op("kv_import")
+	if file == "" {
+		return 0, 0, errors.E(op, errors.Str("no file provided"))
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, errors.E(op, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var n, skipped int64
+	batch := make([]*kvv1.Item, 0, chunk)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		errS := st.Set(batch...)
+		if errS != nil {
+			return errS
+		}
+
+		n += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, errR := r.ReadBytes('\n')
+		if errR != nil && errR != io.EOF {
+			return n, skipped, errors.E(op, errR)
+		}
+
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 {
+			item := &kvv1.Item{}
+			err = json.Unmarshal(data, item)
+			if err != nil {
+				return n, skipped, errors.E(op, errors.Errorf("line: %d, error: %v", line, err))
+			}
+
+			if strings.HasPrefix(item.Key, prefix) {
+				if expired(item.Timeout) {
+					skipped++
+				} else {
+					batch = append(batch, item)
+				}
+			}
+
+			if len(batch) >= chunk {
+				err = flush()
+				if err != nil {
+					return n, skipped, errors.E(op, err)
+				}
+			}
+		}
+
+		if errR == io.EOF {
+			break
+		}
+	}
+
+	err = flush()
+	if err != nil {
+		return n, skipped, errors.E(op, err)
+	}
+
+	return n, skipped, nil
+}
+
+func expired(timeout string) bool {
+	if timeout == "" {
+		return false
+	}
+
+	t, err := time.Parse(time.RFC3339, timeout)
+	if err != nil {
+		return false
+	}
+
+	return !t.After(time.Now())
+}
@@ -0,0 +1,37 @@
+package kv
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotExpired(t *testing.T) {
+	assert.False(t, expired(""))
+	assert.False(t, expired(time.Now().Add(time.Minute).Format(time.RFC3339)))
+	assert.True(t, expired(time.Now().Add(-time.Minute).Format(time.RFC3339)))
+}
+
+func TestSnapshotPath(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "var", "snapshots")
+
+	path, err := snapshotPath(dir, "cache.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache.jsonl"), path)
+
+	path, err = snapshotPath(dir, "daily/../cache.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache.jsonl"), path)
+
+	for _, file := range []string{"", ".", "..", "../cache.jsonl", "daily/../../cache.jsonl", filepath.Join(dir, "cache.jsonl")} {
+		_, err = snapshotPath(dir, file)
+		assert.Error(t, err, file)
+	}
+
+	// not configured
+	_, err = snapshotPath("", "cache.jsonl")
+	assert.Error(t, err)
+}
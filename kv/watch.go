@@ -0,0 +1,345 @@
+package kv
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+const (
+	// watch key used to detect the changes notifications configuration of the storage
+	watch string = "watch"
+	// TopicPrefix + storage + "." + key is the topic with the changes of the key
+	TopicPrefix string = PluginName + "."
+
+	defaultWatchBuffer int = 1000
+	// max number of the events waiting to be published into the broadcast
+	publishQueueSize int = 1000
+	// max wait of the Watch RPC call
+	maxWatchWait time.Duration = time.Second * 30
+)
+
+// WatchConfig configures the storage changes notifications
+type WatchConfig struct {
+	// Broadcast publishes the changes into the kv.<storage>.<key> topics
+	Broadcast bool `mapstructure:"broadcast"`
+
+	// Buffer is the number of the last changes kept for the Watch RPC, default: 1000
+	Buffer int `mapstructure:"buffer"`
+}
+
+func (c *WatchConfig) InitDefaults() {
+	if c.Buffer <= 0 {
+		c.Buffer = defaultWatchBuffer
+	}
+}
+
+// watchStream keeps the last changes of the storage in the ring buffer for the Watch RPC and publishes them into the broadcast
+type watchStream struct {
+	storage string
+
+	mu   sync.Mutex
+	seq  uint64
+	ring []*kvv1.Event
+	// closed and replaced on every event, wakes up the waiting readers
+	notify chan struct{}
+
+	log logger.Logger
+
+	// nil if the broadcast is disabled
+	pub    broadcast.Publisher
+	pubCh  chan *kvv1.Event
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWatchStream(storage string, cfg *WatchConfig, pub broadcast.Publisher, log logger.Logger) *watchStream {
+	ws := &watchStream{
+		storage: storage,
+		ring:    make([]*kvv1.Event, cfg.Buffer),
+		notify:  make(chan struct{}),
+		log:     log,
+		stopCh:  make(chan struct{}),
+	}
+
+	if cfg.Broadcast && pub != nil {
+		ws.pub = pub
+		ws.pubCh = make(chan *kvv1.Event, publishQueueSize)
+		ws.wg.Add(1)
+		go ws.publish()
+	}
+
+	return ws
+}
+
+func (ws *watchStream) emit(key, op string) {
+	ev := &kvv1.Event{
+		Storage: ws.storage,
+		Key:     key,
+		Op:      op,
+		Time:    time.Now().UnixNano(),
+	}
+
+	ws.mu.Lock()
+	ws.seq++
+	ev.Seq = ws.seq
+	ws.ring[ws.seq%uint64(len(ws.ring))] = ev
+	close(ws.notify)
+	ws.notify = make(chan struct{})
+	ws.mu.Unlock()
+
+	if ws.pubCh == nil {
+		return
+	}
+
+	// slow broadcast should not block the storage
+	select {
+	case ws.pubCh <- ev:
+	default:
+		ws.log.Warn("kv changes publish queue is full, event dropped", "storage", ws.storage, "key", key, "op", op)
+	}
+}
+
+// read returns the changes of the keys with the prefix after the requested sequence number, waiting for the new
+// changes up to the wait duration. Clear and invalidate events are returned for any prefix.
+func (ws *watchStream) read(ctx context.Context, prefix string, after uint64, limit int, wait time.Duration) ([]*kvv1.Event, uint64) {
+	if limit <= 0 || limit > len(ws.ring) {
+		limit = len(ws.ring)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		ws.mu.Lock()
+		// sequence is reset after the restart
+		if after > ws.seq {
+			after = ws.seq
+		}
+
+		// the oldest event in the buffer
+		s := uint64(1)
+		if ws.seq > uint64(len(ws.ring)) {
+			s = ws.seq - uint64(len(ws.ring)) + 1
+		}
+
+		if after+1 > s {
+			s = after + 1
+		}
+
+		out := make([]*kvv1.Event, 0)
+		for ; s <= ws.seq && len(out) < limit; s++ {
+			ev := ws.ring[s%uint64(len(ws.ring))]
+			if ev.Op == kv.OpClear || ev.Op == kv.OpInvalidate || strings.HasPrefix(ev.Key, prefix) {
+				out = append(out, ev)
+			}
+		}
+
+		last := s - 1
+		left := time.Until(deadline)
+		if len(out) > 0 || left <= 0 {
+			ws.mu.Unlock()
+			return out, last
+		}
+
+		after = last
+		notify := ws.notify
+		ws.mu.Unlock()
+
+		timer := time.NewTimer(left)
+		select {
+		case <-notify:
+		case <-timer.C:
+		case <-ctx.Done():
+			deadline = time.Now()
+		case <-ws.stopCh:
+			deadline = time.Now()
+		}
+		timer.Stop()
+	}
+}
+
+func (ws *watchStream) publish() {
+	defer ws.wg.Done()
+
+	for {
+		select {
+		case ev := <-ws.pubCh:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				ws.log.Error("kv event marshal failed", "error", err)
+				continue
+			}
+
+			err = ws.pub.Publish(&pubsub.Message{Topic: TopicPrefix + ev.Storage + "." + ev.Key, Payload: data})
+			if err != nil {
+				ws.log.Error("kv event publish failed", "error", err)
+			}
+		case <-ws.stopCh:
+			return
+		}
+	}
+}
+
+func (ws *watchStream) stop() {
+	close(ws.stopCh)
+	ws.wg.Wait()
+}
+
+// watched emits the changes made through the storage. If the storage is a kv.Notifier with the notifications
+// available, the changes are emitted by the storage itself.
+type watched struct {
+	kv.Storage
+	ws     *watchStream
+	native bool
+}
+
+func newWatched(st kv.Storage, ws *watchStream) (*watched, error) {
+	w := &watched{
+		Storage: st,
+		ws:      ws,
+	}
+
+	if n, ok := st.(kv.Notifier); ok {
+		native, err := n.Notify(ws.emit)
+		if err != nil {
+			return nil, err
+		}
+		w.native = native
+	}
+
+	return w, nil
+}
+
+func (w *watched) emit(key, op string) {
+	if w.native {
+		return
+	}
+
+	w.ws.emit(key, op)
+}
+
+func (w *watched) Set(items ...*kvv1.Item) error {
+	err := w.Storage.Set(items...)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		if items[i] != nil {
+			w.emit(items[i].Key, kv.OpSet)
+		}
+	}
+
+	return nil
+}
+
+func (w *watched) MExpire(items ...*kvv1.Item) error {
+	err := w.Storage.MExpire(items...)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		if items[i] != nil {
+			w.emit(items[i].Key, kv.OpExpire)
+		}
+	}
+
+	return nil
+}
+
+func (w *watched) Delete(keys ...string) error {
+	err := w.Storage.Delete(keys...)
+	if err != nil {
+		return err
+	}
+
+	for i := range keys {
+		w.emit(keys[i], kv.OpDelete)
+	}
+
+	return nil
+}
+
+func (w *watched) Incr(key string, delta int64, timeout string) (int64, error) {
+	v, err := w.Storage.Incr(key, delta, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	w.emit(key, kv.OpSet)
+	return v, nil
+}
+
+func (w *watched) Decr(key string, delta int64, timeout string) (int64, error) {
+	v, err := w.Storage.Decr(key, delta, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	w.emit(key, kv.OpSet)
+	return v, nil
+}
+
+func (w *watched) SetNX(item *kvv1.Item) (bool, error) {
+	applied, err := w.Storage.SetNX(item)
+	if err != nil {
+		return false, err
+	}
+
+	if applied {
+		w.emit(item.Key, kv.OpSet)
+	}
+
+	return applied, nil
+}
+
+func (w *watched) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	applied, err := w.Storage.CompareAndSwap(key, oldValue, newValue, timeout)
+	if err != nil {
+		return false, err
+	}
+
+	if applied {
+		w.emit(key, kv.OpSet)
+	}
+
+	return applied, nil
+}
+
+func (w *watched) InvalidateTags(tags ...string) error {
+	err := w.Storage.InvalidateTags(tags...)
+	if err != nil {
+		return err
+	}
+
+	// tagged keys are not known, emitted for the native notifications too
+	for i := range tags {
+		w.ws.emit(tags[i], kv.OpInvalidate)
+	}
+
+	return nil
+}
+
+// Tags returns the tags of the wrapped storage keys, see kv.Tagger
+func (w *watched) Tags(keys ...string) (map[string][]string, error) {
+	return tags(w.Storage, keys...)
+}
+
+// Clear is emitted for the native notifications too, there are no per key notifications on flush
+func (w *watched) Clear() error {
+	err := w.Storage.Clear()
+	if err != nil {
+		return err
+	}
+
+	w.ws.emit("", kv.OpClear)
+	return nil
+}
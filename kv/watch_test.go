@@ -0,0 +1,138 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/kv/kvtest"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeNotifier struct {
+	*kvtest.Storage
+	notify func(key, op string)
+}
+
+func (f *fakeNotifier) Notify(fn func(key, op string)) (bool, error) {
+	f.notify = fn
+	return true, nil
+}
+
+// fakePublisher sends the published messages into the channel
+type fakePublisher struct {
+	broadcast.Publisher
+	ch chan *pubsub.Message
+}
+
+func (f *fakePublisher) Publish(m *pubsub.Message) error {
+	f.ch <- m
+	return nil
+}
+
+func TestWatchStreamPublish(t *testing.T) {
+	cfg := &WatchConfig{Broadcast: true}
+	cfg.InitDefaults()
+	pub := &fakePublisher{ch: make(chan *pubsub.Message, 1)}
+	ws := newWatchStream("flags", cfg, pub, logger.NewZapAdapter(zap.NewNop()))
+	defer ws.stop()
+
+	ws.emit("dark_mode", kv.OpSet)
+
+	select {
+	case m := <-pub.ch:
+		assert.Equal(t, "kv.flags.dark_mode", m.Topic)
+	case <-time.After(time.Second * 5):
+		t.Fatal("event is not published")
+	}
+}
+
+func TestWatchStreamRead(t *testing.T) {
+	cfg := &WatchConfig{Buffer: 4}
+	cfg.InitDefaults()
+	ws := newWatchStream("test", cfg, nil, logger.NewZapAdapter(zap.NewNop()))
+	defer ws.stop()
+
+	for i := 0; i < 6; i++ {
+		ws.emit("user:"+strconv.Itoa(i), kv.OpSet)
+	}
+
+	// only the last 4 events are kept
+	evs, last := ws.read(context.Background(), "", 0, 0, 0)
+	require.Len(t, evs, 4)
+	assert.Equal(t, uint64(6), last)
+	assert.Equal(t, uint64(3), evs[0].GetSeq())
+	assert.Equal(t, "user:2", evs[0].GetKey())
+
+	evs, last = ws.read(context.Background(), "", 4, 1, 0)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(5), last)
+
+	// prefix filter, the last checked event is returned
+	ws.emit("flag:1", kv.OpDelete)
+	evs, last = ws.read(context.Background(), "flag:", 0, 0, 0)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(7), last)
+	assert.Equal(t, kv.OpDelete, evs[0].GetOp())
+
+	// not matching events are skipped while waiting
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		ws.emit("user:7", kv.OpSet)
+		time.Sleep(time.Millisecond * 100)
+		ws.emit("flag:2", kv.OpSet)
+	}()
+
+	evs, last = ws.read(context.Background(), "flag:", 7, 10, time.Second*5)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(9), last)
+	assert.Equal(t, "flag:2", evs[0].GetKey())
+
+	// sequence is reset after the restart
+	evs, last = ws.read(context.Background(), "", 100, 0, 0)
+	assert.Len(t, evs, 0)
+	assert.Equal(t, uint64(9), last)
+}
+
+func TestWatched(t *testing.T) {
+	cfg := &WatchConfig{}
+	cfg.InitDefaults()
+	ws := newWatchStream("test", cfg, nil, logger.NewZapAdapter(zap.NewNop()))
+	defer ws.stop()
+
+	w, err := newWatched(kvtest.NewStorage(), ws)
+	require.NoError(t, err)
+	assert.False(t, w.native)
+
+	require.NoError(t, w.Set(&kvv1.Item{Key: "a"}, &kvv1.Item{Key: "b"}))
+	require.NoError(t, w.Delete("a"))
+	require.NoError(t, w.Clear())
+
+	evs, _ := ws.read(context.Background(), "", 0, 0, 0)
+	require.Len(t, evs, 4)
+	assert.Equal(t, kv.OpSet, evs[1].GetOp())
+	assert.Equal(t, "b", evs[1].GetKey())
+	assert.Equal(t, kv.OpDelete, evs[2].GetOp())
+	assert.Equal(t, kv.OpClear, evs[3].GetOp())
+
+	// native notifications, writes are not emitted twice
+	fn := &fakeNotifier{Storage: kvtest.NewStorage()}
+	w, err = newWatched(fn, ws)
+	require.NoError(t, err)
+	assert.True(t, w.native)
+
+	require.NoError(t, w.Set(&kvv1.Item{Key: "c"}))
+	fn.notify("c", kv.OpSet)
+
+	evs, last := ws.read(context.Background(), "", 4, 0, 0)
+	require.Len(t, evs, 1)
+	assert.Equal(t, uint64(5), last)
+}
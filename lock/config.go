@@ -0,0 +1,41 @@
+package lock
+
+import (
+	"time"
+)
+
+const (
+	defaultPrefix        string        = "rr_lock:"
+	defaultTTL           time.Duration = time.Second * 30
+	defaultRetryInterval time.Duration = time.Millisecond * 100
+)
+
+// Config of the lock service
+type Config struct {
+	// Storage is the name of the kv storage with the locks. Local (in-process) locks are used if the storage is empty or
+	// uses the memory driver.
+	Storage string `mapstructure:"storage"`
+
+	// Prefix of the lock keys in the storage, default: rr_lock:
+	Prefix string `mapstructure:"prefix"`
+
+	// TTL of the lock if not provided in the request, default: 30s
+	TTL time.Duration `mapstructure:"ttl"`
+
+	// RetryInterval between the acquire attempts while waiting for the storage lock, default: 100ms
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = defaultPrefix
+	}
+
+	if c.TTL == 0 {
+		c.TTL = defaultTTL
+	}
+
+	if c.RetryInterval == 0 {
+		c.RetryInterval = defaultRetryInterval
+	}
+}
@@ -0,0 +1,158 @@
+package lock
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+)
+
+const (
+	// fenceKey is the counter of the fencing tokens, shared by all resources. The name is reserved, so the counter
+	// never collides with the lock keys.
+	fenceKey string = "__fence__"
+	// separates the owner and the fencing token in the lock value
+	separator string = "|"
+	// released lock is marked with an empty value with a short TTL, because the conditional delete is not available
+	releasedTTL time.Duration = time.Second
+)
+
+// kvLocker keeps the locks in the kv storage, the lock value is the owner and the fencing token
+type kvLocker struct {
+	storage kv.Storage
+	prefix  string
+	retry   time.Duration
+}
+
+func (k *kvLocker) acquire(ctx context.Context, resource, owner string, ttl time.Duration) (int64, bool, error) {
+	const op = errors.Op("lock_kv_acquire")
+	for {
+		// fencing tokens are increasing, but not sequential, every attempt takes a token
+		fence, err := k.storage.Incr(k.prefix+fenceKey, 1, "")
+		if err != nil {
+			return 0, false, errors.E(op, err)
+		}
+
+		value := []byte(owner + separator + strconv.FormatInt(fence, 10))
+		ok, err := k.storage.SetNX(&kvv1.Item{
+			Key:     k.key(resource),
+			Value:   value,
+			Timeout: expiresAt(ttl),
+		})
+		if err != nil {
+			return 0, false, errors.E(op, err)
+		}
+
+		if !ok {
+			// released lock
+			ok, err = k.storage.CompareAndSwap(k.key(resource), []byte{}, value, expiresAt(ttl))
+			if err != nil {
+				return 0, false, errors.E(op, err)
+			}
+		}
+
+		if ok {
+			return fence, true, nil
+		}
+
+		select {
+		case <-time.After(k.retry):
+		case <-ctx.Done():
+			return 0, false, nil
+		}
+	}
+}
+
+func (k *kvLocker) release(resource, owner string) (bool, error) {
+	const op = errors.Op("lock_kv_release")
+	value, _, ok, err := k.held(resource, owner)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	ok, err = k.storage.CompareAndSwap(k.key(resource), value, []byte{}, expiresAt(releasedTTL))
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return ok, nil
+}
+
+func (k *kvLocker) refresh(resource, owner string, ttl time.Duration) (int64, bool, error) {
+	const op = errors.Op("lock_kv_refresh")
+	value, fence, ok, err := k.held(resource, owner)
+	if err != nil {
+		return 0, false, errors.E(op, err)
+	}
+
+	if !ok {
+		return 0, false, nil
+	}
+
+	ok, err = k.storage.CompareAndSwap(k.key(resource), value, value, expiresAt(ttl))
+	if err != nil {
+		return 0, false, errors.E(op, err)
+	}
+
+	if !ok {
+		return 0, false, nil
+	}
+
+	return fence, true, nil
+}
+
+func (k *kvLocker) forceRelease(resource string) (bool, error) {
+	const op = errors.Op("lock_kv_force_release")
+	res, err := k.storage.MGet(k.key(resource))
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	err = k.storage.Delete(k.key(resource))
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	// released lock is kept with the empty value until it expires
+	return len(res[k.key(resource)]) > 0, nil
+}
+
+// held returns the lock value and the fencing token if the lock is held by the owner
+func (k *kvLocker) held(resource, owner string) ([]byte, int64, bool, error) {
+	// Get of the missing key is not consistent between the drivers
+	res, err := k.storage.MGet(k.key(resource))
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	value := res[k.key(resource)]
+
+	i := strings.LastIndex(string(value), separator)
+	if i == -1 || string(value[:i]) != owner {
+		return nil, 0, false, nil
+	}
+
+	fence, err := strconv.ParseInt(string(value[i+1:]), 10, 64)
+	if err != nil {
+		return nil, 0, false, nil
+	}
+
+	return value, fence, true, nil
+}
+
+func (k *kvLocker) key(resource string) string {
+	return k.prefix + resource
+}
+
+// expiresAt returns the RFC 3339 expiration time, rounded up to the next second
+func expiresAt(ttl time.Duration) string {
+	return time.Now().Add(ttl).Truncate(time.Second).Add(time.Second).Format(time.RFC3339)
+}
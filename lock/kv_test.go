@@ -0,0 +1,40 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spiral/roadrunner-plugins/v2/kv/kvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVForceReleaseReleased(t *testing.T) {
+	k := &kvLocker{storage: kvtest.NewStorage(), prefix: "lock:", retry: time.Millisecond}
+
+	_, ok, err := k.acquire(context.Background(), "res", "owner-1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = k.release("res", "owner-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// released lock is not held by anyone
+	ok, err = k.release("res", "owner-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = k.forceRelease("res")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = k.acquire(context.Background(), "res", "owner-2", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = k.forceRelease("res")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
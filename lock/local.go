@@ -0,0 +1,120 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// localLock is the lock held in the process memory
+type localLock struct {
+	owner   string
+	fence   int64
+	expires time.Time
+	timer   *time.Timer
+	// closed on release or expiration, wakes up the waiters
+	released chan struct{}
+}
+
+// local is the in-process locker, waiters are notified on release without polling
+type local struct {
+	mu    sync.Mutex
+	locks map[string]*localLock
+	// fencing tokens are increasing for all resources
+	fence int64
+}
+
+func newLocal() *local {
+	return &local{
+		locks: make(map[string]*localLock),
+	}
+}
+
+func (l *local) acquire(ctx context.Context, resource, owner string, ttl time.Duration) (int64, bool, error) {
+	for {
+		l.mu.Lock()
+		cur, ok := l.locks[resource]
+		if !ok {
+			l.fence++
+			lk := &localLock{
+				owner:    owner,
+				fence:    l.fence,
+				expires:  time.Now().Add(ttl),
+				released: make(chan struct{}),
+			}
+			lk.timer = time.AfterFunc(ttl, func() {
+				l.expire(resource, lk)
+			})
+			l.locks[resource] = lk
+			l.mu.Unlock()
+
+			return lk.fence, true, nil
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-cur.released:
+		case <-ctx.Done():
+			return 0, false, nil
+		}
+	}
+}
+
+func (l *local) release(resource, owner string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cur, ok := l.locks[resource]
+	if !ok || cur.owner != owner {
+		return false, nil
+	}
+
+	l.remove(resource, cur)
+	return true, nil
+}
+
+func (l *local) refresh(resource, owner string, ttl time.Duration) (int64, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cur, ok := l.locks[resource]
+	if !ok || cur.owner != owner {
+		return 0, false, nil
+	}
+
+	cur.expires = time.Now().Add(ttl)
+	cur.timer.Reset(ttl)
+	return cur.fence, true, nil
+}
+
+func (l *local) forceRelease(resource string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cur, ok := l.locks[resource]
+	if !ok {
+		return false, nil
+	}
+
+	l.remove(resource, cur)
+	return true, nil
+}
+
+// expire removes the lock on the TTL timer, the lock might be refreshed or released concurrently
+func (l *local) expire(resource string, lk *localLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locks[resource] != lk || time.Now().Before(lk.expires) {
+		return
+	}
+
+	l.remove(resource, lk)
+}
+
+// remove should be called under the mutex
+func (l *local) remove(resource string, lk *localLock) {
+	lk.timer.Stop()
+	delete(l.locks, resource)
+	close(lk.released)
+}
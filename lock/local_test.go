@@ -0,0 +1,86 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	l := newLocal()
+	ctx := context.Background()
+
+	fence, ok, err := l.acquire(ctx, "res", "owner-1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// single attempt
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	_, ok, err = l.acquire(done, "res", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = l.release("res", "owner-2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	refreshed, ok, err := l.refresh("res", "owner-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, fence, refreshed)
+
+	// waiter is notified on release
+	acquired := make(chan int64, 1)
+	go func() {
+		wctx, wcancel := context.WithTimeout(ctx, time.Second*5)
+		defer wcancel()
+		f, ok, _ := l.acquire(wctx, "res", "owner-2", time.Minute)
+		if ok {
+			acquired <- f
+		}
+		close(acquired)
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+	ok, err = l.release("res", "owner-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	next := <-acquired
+	assert.Greater(t, next, fence)
+
+	ok, err = l.forceRelease("res")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// lost lock
+	_, ok, err = l.refresh("res", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLocalExpire(t *testing.T) {
+	l := newLocal()
+	ctx := context.Background()
+
+	_, ok, err := l.acquire(ctx, "res", "owner-1", time.Millisecond*100)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	wctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	start := time.Now()
+	_, ok, err = l.acquire(wctx, "res", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Less(t, time.Since(start), time.Second)
+
+	ok, err = l.release("res", "owner-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
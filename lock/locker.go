@@ -0,0 +1,18 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// locker is the locks backend
+type locker interface {
+	// acquire tries to acquire the lock until the context is done, returns the fencing token
+	acquire(ctx context.Context, resource, owner string, ttl time.Duration) (int64, bool, error)
+	// release releases the lock held by the owner
+	release(resource, owner string) (bool, error)
+	// refresh prolongs the lock held by the owner, returns the fencing token
+	refresh(resource, owner string, ttl time.Duration) (int64, bool, error)
+	// forceRelease releases the lock regardless of the owner
+	forceRelease(resource string) (bool, error)
+}
@@ -0,0 +1,113 @@
+package lock
+
+import (
+	"sync"
+
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	kvPlugin "github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+)
+
+const PluginName string = "lock"
+
+// Plugin is the distributed lock service on top of the kv storages
+type Plugin struct {
+	cfg        *Config
+	cfgPlugin  config.Configurer
+	log        logger.Logger
+	kvProvider kv.StorageProvider
+
+	// locker is resolved on the first call, kv storages are available after the kv plugin Serve
+	mu     sync.Mutex
+	locker locker
+}
+
+func (p *Plugin) Init(cfg config.Configurer, log logger.Logger) error {
+	const op = errors.Op("lock_plugin_init")
+	if !cfg.Has(PluginName) {
+		return errors.E(op, errors.Disabled)
+	}
+
+	err := cfg.UnmarshalKey(PluginName, &p.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if p.cfg == nil {
+		p.cfg = &Config{}
+	}
+
+	p.cfg.InitDefaults()
+	p.cfgPlugin = cfg
+	p.log = log
+
+	return nil
+}
+
+func (p *Plugin) Collects() []interface{} {
+	return []interface{}{
+		p.CollectKVProvider,
+	}
+}
+
+// CollectKVProvider collects KV storages provider
+func (p *Plugin) CollectKVProvider(_ endure.Named, sp kv.StorageProvider) {
+	p.kvProvider = sp
+}
+
+// getLocker returns the locker for the configured storage
+func (p *Plugin) getLocker() (locker, error) {
+	const op = errors.Op("lock_plugin_locker")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.locker != nil {
+		return p.locker, nil
+	}
+
+	if p.cfg.Storage == "" {
+		p.locker = newLocal()
+		return p.locker, nil
+	}
+
+	if p.kvProvider == nil {
+		return nil, errors.E(op, errors.Str("kv plugin is not available for the locks storage"))
+	}
+
+	st, err := p.kvProvider.Storage(p.cfg.Storage)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	// memory storage is local anyway, fast path without polling. The storage might be wrapped (watch), so the driver is
+	// detected by the storage configuration
+	if p.cfgPlugin.Get(kvPlugin.PluginName+"."+p.cfg.Storage+".driver") == memory.PluginName {
+		p.log.Debug("memory storage is used for the locks, using local locks", "storage", p.cfg.Storage)
+		p.locker = newLocal()
+		return p.locker, nil
+	}
+
+	p.locker = &kvLocker{
+		storage: st,
+		prefix:  p.cfg.Prefix,
+		retry:   p.cfg.RetryInterval,
+	}
+
+	return p.locker, nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Available interface implementation
+func (p *Plugin) Available() {}
+
+// RPC returns associated rpc service.
+func (p *Plugin) RPC() interface{} {
+	return &rpc{p: p, log: p.log}
+}
@@ -0,0 +1,146 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spiral/errors"
+	lockv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/lock/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+type rpc struct {
+	p   *Plugin
+	log logger.Logger
+}
+
+// Acquire acquires the lock, waits for the lock up to the wait milliseconds. Owner token is generated if not provided.
+func (r *rpc) Acquire(in *lockv1.Request, out *lockv1.Response) error {
+	const op = errors.Op("lock_rpc_acquire")
+	err := checkResource(in.GetResource())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	l, err := r.p.getLocker()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	owner := in.GetOwner()
+	if owner == "" {
+		owner = uuid.NewString()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(in.GetWait()))
+	defer cancel()
+
+	fence, ok, err := l.acquire(ctx, in.GetResource(), owner, r.ttl(in))
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Ok = ok
+	if ok {
+		out.Owner = owner
+		out.Fence = fence
+		r.log.Debug("lock acquired", "resource", in.GetResource(), "owner", owner, "fence", fence)
+	}
+
+	return nil
+}
+
+// Release releases the lock held by the owner, not held lock is not an error
+func (r *rpc) Release(in *lockv1.Request, out *lockv1.Response) error {
+	const op = errors.Op("lock_rpc_release")
+	if in.GetOwner() == "" {
+		return errors.E(op, errors.Str("no owner provided"))
+	}
+
+	err := checkResource(in.GetResource())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	l, err := r.p.getLocker()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Ok, err = l.release(in.GetResource(), in.GetOwner())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Owner = in.GetOwner()
+	return nil
+}
+
+// Refresh prolongs the lock held by the owner, false means that the lock is lost
+func (r *rpc) Refresh(in *lockv1.Request, out *lockv1.Response) error {
+	const op = errors.Op("lock_rpc_refresh")
+	if in.GetOwner() == "" {
+		return errors.E(op, errors.Str("no owner provided"))
+	}
+
+	err := checkResource(in.GetResource())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	l, err := r.p.getLocker()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Fence, out.Ok, err = l.refresh(in.GetResource(), in.GetOwner(), r.ttl(in))
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Owner = in.GetOwner()
+	return nil
+}
+
+// ForceRelease releases the lock regardless of the owner
+func (r *rpc) ForceRelease(in *lockv1.Request, out *lockv1.Response) error {
+	const op = errors.Op("lock_rpc_force_release")
+	err := checkResource(in.GetResource())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	l, err := r.p.getLocker()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	out.Ok, err = l.forceRelease(in.GetResource())
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	r.log.Debug("lock force released", "resource", in.GetResource(), "released", out.Ok)
+	return nil
+}
+
+// checkResource rejects the empty and reserved resource names
+func checkResource(resource string) error {
+	switch resource {
+	case "":
+		return errors.Str("no resource provided")
+	case fenceKey:
+		return errors.Errorf("resource name is reserved: %s", resource)
+	default:
+		return nil
+	}
+}
+
+func (r *rpc) ttl(in *lockv1.Request) time.Duration {
+	if in.GetTtl() <= 0 {
+		return r.p.cfg.TTL
+	}
+
+	return time.Second * time.Duration(in.GetTtl())
+}
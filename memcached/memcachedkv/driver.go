@@ -1,6 +1,8 @@
 package memcachedkv
 
 import (
+	"bytes"
+	"strconv"
 	"strings"
 	"time"
 
@@ -142,6 +144,13 @@ func (d *driver) Set(items ...*kvv1.Item) error {
 		return errors.E(op, errors.NoKeys)
 	}
 
+	// memcached has no sets for the tags index
+	for i := range items {
+		if items[i] != nil && len(items[i].Tags) > 0 {
+			return errors.E(op, errors.Str("tags are not supported by the memcached driver"))
+		}
+	}
+
 	for i := range items {
 		if items[i] == nil {
 			return errors.E(op, errors.EmptyItem)
@@ -253,3 +262,166 @@ func (d *driver) Clear() error {
 func (d *driver) Stop() {
 	// not implemented https://github.com/bradfitz/gomemcache/issues/51
 }
+
+// Incr increments the counter, the missing key is added with the delta value and the timeout.
+// Memcached doesn't expose the TTL, so the timeout is set only when the key is created.
+func (d *driver) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("memcached_plugin_incr")
+	return d.incr(op, key, delta, timeout)
+}
+
+// Decr decrements the counter, memcached counters are unsigned, so the value is floored at 0
+func (d *driver) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("memcached_plugin_decr")
+	return d.incr(op, key, -delta, timeout)
+}
+
+func (d *driver) incr(op errors.Op, key string, delta int64, timeout string) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, errors.E(op, errors.EmptyKey)
+	}
+
+	var exp int32
+	if timeout != "" {
+		t, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return 0, errors.E(op, err)
+		}
+		exp = int32(t.Unix())
+	}
+
+	// two attempts: the key might be added concurrently between the miss and the add
+	for i := 0; i < 2; i++ {
+		v, err := d.update(key, delta)
+		if err == nil {
+			return int64(v), nil
+		}
+
+		if err != memcache.ErrCacheMiss {
+			return 0, errors.E(op, err)
+		}
+
+		initial := delta
+		if initial < 0 {
+			initial = 0
+		}
+
+		err = d.client.Add(&memcache.Item{
+			Key:        key,
+			Value:      []byte(strconv.FormatInt(initial, 10)),
+			Expiration: exp,
+		})
+		if err == nil {
+			return initial, nil
+		}
+
+		if err != memcache.ErrNotStored {
+			return 0, errors.E(op, err)
+		}
+	}
+
+	return 0, errors.E(op, errors.Errorf("failed to update the counter: %s", key))
+}
+
+func (d *driver) update(key string, delta int64) (uint64, error) {
+	if delta < 0 {
+		return d.client.Decrement(key, uint64(-delta))
+	}
+
+	return d.client.Increment(key, uint64(delta))
+}
+
+// SetNX uses the memcached add command
+func (d *driver) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("memcached_plugin_setnx")
+	if item == nil {
+		return false, errors.E(op, errors.EmptyItem)
+	}
+
+	if len(item.Tags) > 0 {
+		return false, errors.E(op, errors.Str("tags are not supported by the memcached driver"))
+	}
+
+	if strings.TrimSpace(item.Key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	memcachedItem := &memcache.Item{
+		Key:   item.Key,
+		Value: item.Value,
+	}
+
+	if item.Timeout != "" {
+		t, err := time.Parse(time.RFC3339, item.Timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+		memcachedItem.Expiration = int32(t.Unix())
+	}
+
+	err := d.client.Add(memcachedItem)
+	if err != nil {
+		if err == memcache.ErrNotStored {
+			return false, nil
+		}
+		return false, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap uses the memcached gets/cas commands
+func (d *driver) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("memcached_plugin_compare_and_swap")
+	if strings.TrimSpace(key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	var exp int32
+	if timeout != "" {
+		t, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+		exp = int32(t.Unix())
+	}
+
+	// Get returns the item with the cas id
+	item, err := d.client.Get(key)
+	if err != nil {
+		if err == memcache.ErrCacheMiss {
+			return false, nil
+		}
+		return false, errors.E(op, err)
+	}
+
+	if !bytes.Equal(item.Value, oldValue) {
+		return false, nil
+	}
+
+	item.Value = newValue
+	item.Expiration = exp
+
+	err = d.client.CompareAndSwap(item)
+	if err != nil {
+		// modified or deleted after the Get
+		if err == memcache.ErrCASConflict || err == memcache.ErrNotStored || err == memcache.ErrCacheMiss {
+			return false, nil
+		}
+		return false, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+// Keys is not supported, memcached can't list the keys
+func (d *driver) Keys(_ string, _ string, _ int) ([]string, string, error) {
+	const op = errors.Op("memcached_plugin_keys")
+	return nil, "", errors.E(op, errors.Str("keys listing is not supported by the memcached driver"))
+}
+
+// InvalidateTags is not supported, see Set
+func (d *driver) InvalidateTags(_ ...string) error {
+	const op = errors.Op("memcached_plugin_invalidate_tags")
+	return errors.E(op, errors.Str("tags are not supported by the memcached driver"))
+}
@@ -2,6 +2,7 @@ package memoryjobs
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -16,12 +17,28 @@ import (
 )
 
 const (
-	prefetch      string = "prefetch"
-	goroutinesMax uint64 = 1000
+	prefetch         string = "prefetch"
+	persistPath      string = "persist_path"
+	snapshotInterval string = "snapshot_interval"
 )
 
 type Config struct {
 	Prefetch uint64 `mapstructure:"prefetch"`
+	// PersistPath is the path to the journal file. Pushed jobs are written into the journal and restored on start.
+	// Empty - persistence disabled.
+	PersistPath string `mapstructure:"persist_path"`
+	// SnapshotInterval is the interval to compact the journal, default - 1m
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.Prefetch == 0 {
+		c.Prefetch = 100_000
+	}
+
+	if c.SnapshotInterval == 0 {
+		c.SnapshotInterval = time.Minute
+	}
 }
 
 type consumer struct {
@@ -31,57 +48,93 @@ type consumer struct {
 	pq            priorityqueue.Queue
 	localPrefetch chan *Item
 
-	// time.sleep goroutines max number
-	goroutines uint64
+	// delayed jobs, waiting to be moved into the localPrefetch
+	dq *delayQueue
+	// write-ahead log, nil if persistence is disabled
+	journal *journal
 
 	delayed *int64
 	active  *int64
 
 	listeners uint32
 	stopCh    chan struct{}
+
+	// stops scheduler and snapshot goroutines
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
 }
 
 func FromConfig(configKey string, log logger.Logger, cfg config.Configurer, pq priorityqueue.Queue) (*consumer, error) {
 	const op = errors.Op("new_ephemeral_pipeline")
 
-	jb := &consumer{
-		log:        log,
-		pq:         pq,
-		goroutines: 0,
-		active:     utils.Int64(0),
-		delayed:    utils.Int64(0),
-		stopCh:     make(chan struct{}),
-	}
-
-	err := cfg.UnmarshalKey(configKey, &jb.cfg)
+	var conf *Config
+	err := cfg.UnmarshalKey(configKey, &conf)
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
 
-	if jb.cfg == nil {
+	if conf == nil {
 		return nil, errors.E(op, errors.Errorf("config not found by provided key: %s", configKey))
 	}
 
-	if jb.cfg.Prefetch == 0 {
-		jb.cfg.Prefetch = 100_000
-	}
+	conf.InitDefaults()
 
-	// initialize a local queue
-	jb.localPrefetch = make(chan *Item, jb.cfg.Prefetch)
+	jb, err := newConsumer(conf, log, pq)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
 
 	return jb, nil
 }
 
 func FromPipeline(pipeline *pipeline.Pipeline, log logger.Logger, pq priorityqueue.Queue) (*consumer, error) {
-	return &consumer{
-		log:           log,
-		pq:            pq,
-		localPrefetch: make(chan *Item, pipeline.Int(prefetch, 100_000)),
-		goroutines:    0,
-		active:        utils.Int64(0),
-		delayed:       utils.Int64(0),
-		stopCh:        make(chan struct{}),
-	}, nil
+	const op = errors.Op("new_ephemeral_pipeline")
+
+	conf := &Config{
+		Prefetch:         uint64(pipeline.Int(prefetch, 100_000)),
+		PersistPath:      pipeline.String(persistPath, ""),
+		SnapshotInterval: pipeline.Duration(snapshotInterval, time.Minute),
+	}
+
+	conf.InitDefaults()
+
+	jb, err := newConsumer(conf, log, pq)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return jb, nil
+}
+
+func newConsumer(conf *Config, log logger.Logger, pq priorityqueue.Queue) (*consumer, error) {
+	jb := &consumer{
+		cfg:     conf,
+		log:     log,
+		pq:      pq,
+		dq:      newDelayQueue(),
+		active:  utils.Int64(0),
+		delayed: utils.Int64(0),
+		stopCh:  make(chan struct{}),
+		closeCh: make(chan struct{}),
+		// initialize a local queue
+		localPrefetch: make(chan *Item, conf.Prefetch),
+	}
+
+	if conf.PersistPath != "" {
+		err := jb.restore()
+		if err != nil {
+			return nil, err
+		}
+
+		jb.wg.Add(1)
+		go jb.snapshot()
+	}
+
+	jb.wg.Add(1)
+	go jb.schedule()
+
+	return jb, nil
 }
 
 func (c *consumer) Push(ctx context.Context, jb *job.Job) error {
@@ -188,6 +241,12 @@ func (c *consumer) Stop(_ context.Context) error {
 		break
 	}
 
+	// stop the scheduler before draining the local queue
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+	})
+	c.wg.Wait()
+
 	for i := 0; i < len(c.localPrefetch); i++ {
 		// drain all jobs from the channel
 		<-c.localPrefetch
@@ -195,35 +254,38 @@ func (c *consumer) Stop(_ context.Context) error {
 
 	c.localPrefetch = nil
 
+	// not acknowledged jobs stay in the journal and will be restored on the next start
+	if c.journal != nil {
+		err := c.journal.close()
+		if err != nil {
+			c.log.Error("memory jobs journal close", "error", err)
+		}
+	}
+
 	c.log.Debug("pipeline stopped", "driver", pipe.Driver(), "pipeline", pipe.Name(), "start", time.Now(), "elapsed", time.Since(start))
 	return nil
 }
 
 func (c *consumer) handleItem(ctx context.Context, msg *Item) error {
 	const op = errors.Op("ephemeral_handle_request")
-	// handle timeouts
-	// theoretically, some bad user may send millions requests with a delay and produce a billion (for example)
-	// goroutines here. We should limit goroutines here.
-	if msg.Options.Delay > 0 {
-		// if we have 1000 goroutines waiting on the delay - reject 1001
-		if atomic.LoadUint64(&c.goroutines) >= goroutinesMax {
-			return errors.E(op, errors.Str("max concurrency number reached"))
-		}
 
-		go func(jj *Item) {
-			atomic.AddUint64(&c.goroutines, 1)
-			atomic.AddInt64(c.delayed, 1)
+	at := time.Now().Add(msg.Options.DelayDuration())
 
-			time.Sleep(jj.Options.DelayDuration())
+	// write the job into the journal before accepting it
+	if c.journal != nil {
+		seq, err := c.journal.put(msg, at)
+		if err != nil {
+			return errors.E(op, err)
+		}
 
-			select {
-			case c.localPrefetch <- jj:
-				atomic.AddUint64(&c.goroutines, ^uint64(0))
-			default:
-				c.log.Warn("can't push job", "error", "local queue closed or full")
-			}
-		}(msg)
+		msg.Options.seq = seq
+		msg.Options.journal = c.journal
+	}
 
+	// delayed jobs are kept in the heap and moved into the local queue by the scheduler goroutine
+	if msg.Options.Delay > 0 {
+		atomic.AddInt64(c.delayed, 1)
+		c.dq.push(msg, at)
 		return nil
 	}
 
@@ -235,10 +297,59 @@ func (c *consumer) handleItem(ctx context.Context, msg *Item) error {
 	case c.localPrefetch <- msg:
 		return nil
 	case <-ctx.Done():
+		_ = c.journal.remove(msg.Options.seq)
 		return errors.E(op, errors.Errorf("local pipeline is full, consider to increase prefetch number, current limit: %d, context error: %v", c.cfg.Prefetch, ctx.Err()))
 	}
 }
 
+// restore opens the journal and schedules not acknowledged jobs with their remaining delays
+func (c *consumer) restore() error {
+	j, restored, err := openJournal(c.cfg.PersistPath)
+	if err != nil {
+		return err
+	}
+
+	c.journal = j
+
+	for i := 0; i < len(restored); i++ {
+		item := restored[i].Item
+		item.Options.seq = restored[i].Seq
+		item.Options.journal = j
+
+		if item.Options.Delay > 0 {
+			atomic.AddInt64(c.delayed, 1)
+		} else {
+			atomic.AddInt64(c.active, 1)
+		}
+
+		// ready jobs are also moved through the scheduler, the local queue might be smaller than the journal
+		c.dq.push(item, time.Unix(0, restored[i].At))
+	}
+
+	c.log.Info("jobs restored from the journal", "path", c.cfg.PersistPath, "count", len(restored))
+	return nil
+}
+
+// snapshot periodically compacts the journal
+func (c *consumer) snapshot() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cfg.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := c.journal.compact()
+			if err != nil {
+				c.log.Error("memory jobs journal compaction", "error", err)
+			}
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
 func (c *consumer) consume() {
 	go func() {
 		// redirect
@@ -0,0 +1,112 @@
+package memoryjobs
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+type delayedItem struct {
+	at   time.Time
+	item *Item
+}
+
+// delayHeap is a min-heap of the delayed jobs ordered by the delivery time
+type delayHeap []*delayedItem
+
+func (h delayHeap) Len() int            { return len(h) }
+func (h delayHeap) Less(i, j int) bool  { return h[i].at.Before(h[j].at) }
+func (h delayHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *delayHeap) Push(x interface{}) { *h = append(*h, x.(*delayedItem)) }
+func (h *delayHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
+
+// delayQueue holds delayed jobs, a single scheduler goroutine moves them into the local queue when they are ready,
+// instead of the goroutine per delayed job.
+type delayQueue struct {
+	mu    sync.Mutex
+	items delayHeap
+	// notifies the scheduler about the new head of the queue
+	wakeCh chan struct{}
+}
+
+func newDelayQueue() *delayQueue {
+	return &delayQueue{
+		items:  make(delayHeap, 0, 16),
+		wakeCh: make(chan struct{}, 1),
+	}
+}
+
+func (d *delayQueue) push(item *Item, at time.Time) {
+	d.mu.Lock()
+	heap.Push(&d.items, &delayedItem{at: at, item: item})
+	head := d.items[0].item == item
+	d.mu.Unlock()
+
+	if head {
+		select {
+		case d.wakeCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// next pops the ready job, or returns the time to wait for the next one (0 - queue is empty)
+func (d *delayQueue) next(now time.Time) (*Item, time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.items) == 0 {
+		return nil, 0
+	}
+
+	if d.items[0].at.After(now) {
+		return nil, d.items[0].at.Sub(now)
+	}
+
+	return heap.Pop(&d.items).(*delayedItem).item, 0
+}
+
+// schedule moves ready jobs from the delay queue into the local queue
+func (c *consumer) schedule() {
+	defer c.wg.Done()
+
+	for {
+		item, wait := c.dq.next(time.Now())
+		if item != nil {
+			select {
+			case c.localPrefetch <- item:
+			case <-c.closeCh:
+				return
+			}
+			continue
+		}
+
+		var timer *time.Timer
+		var tc <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			tc = timer.C
+		}
+
+		select {
+		case <-tc:
+		case <-c.dq.wakeCh:
+		case <-c.closeCh:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -43,6 +43,9 @@ type Options struct {
 	requeueFn func(context.Context, *Item) error
 	active    *int64
 	delayed   *int64
+	// journal sequence number, 0 if persistence is disabled
+	seq     uint64
+	journal *journal
 }
 
 // DelayDuration returns delay duration in a form of time.Duration.
@@ -83,12 +86,12 @@ func (i *Item) Context() ([]byte, error) {
 
 func (i *Item) Ack() error {
 	i.atomicallyReduceCount()
-	return nil
+	return i.Options.journal.remove(i.Options.seq)
 }
 
 func (i *Item) Nack() error {
 	i.atomicallyReduceCount()
-	return nil
+	return i.Options.journal.remove(i.Options.seq)
 }
 
 func (i *Item) Requeue(headers map[string][]string, delay int64) error {
@@ -98,12 +101,14 @@ func (i *Item) Requeue(headers map[string][]string, delay int64) error {
 
 	i.atomicallyReduceCount()
 
+	// requeued job gets a new journal record, the old one is removed only after that
+	seq := i.Options.seq
 	err := i.Options.requeueFn(context.Background(), i)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return i.Options.journal.remove(seq)
 }
 
 // Respond for the in-memory is no-op
@@ -0,0 +1,248 @@
+package memoryjobs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+)
+
+const (
+	opPut uint8 = 1
+	opDel uint8 = 2
+)
+
+// record is a single journal entry, one JSON object per line
+type record struct {
+	Op  uint8  `json:"op"`
+	Seq uint64 `json:"seq"`
+	// At is the time (unix nano) when the job should be delivered
+	At   int64 `json:"at,omitempty"`
+	Item *Item `json:"item,omitempty"`
+}
+
+// journal is the append-only write-ahead log of the pushed and acknowledged jobs.
+// The log is periodically compacted (snapshotted): only the live jobs are rewritten into a fresh file.
+type journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	seq  uint64
+	// encoded live jobs, not yet acknowledged
+	live map[uint64][]byte
+}
+
+// openJournal opens (or creates) the journal and replays it. Replayed live records are returned sorted by the push order.
+func openJournal(path string) (*journal, []*record, error) {
+	const op = errors.Op("memory_jobs_open_journal")
+
+	j := &journal{
+		path: path,
+		live: make(map[uint64][]byte),
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	err = j.replay()
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	// rewrite the log with the live records only
+	err = j.compact()
+	if err != nil {
+		return nil, nil, errors.E(op, err)
+	}
+
+	restored := make([]*record, 0, len(j.live))
+	for _, data := range j.live {
+		r := &record{}
+		err = json.Unmarshal(data, r)
+		if err != nil {
+			return nil, nil, errors.E(op, err)
+		}
+		restored = append(restored, r)
+	}
+
+	sort.Slice(restored, func(i, k int) bool {
+		return restored[i].Seq < restored[k].Seq
+	})
+
+	return j, restored, nil
+}
+
+func (j *journal) replay() error {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	// jobs payloads might be large
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		r := &record{}
+		err = json.Unmarshal(scanner.Bytes(), r)
+		if err != nil {
+			// the last line might be partially written during the crash, skip it
+			continue
+		}
+
+		if r.Seq > j.seq {
+			j.seq = r.Seq
+		}
+
+		switch r.Op {
+		case opPut:
+			if r.Item == nil || r.Item.Options == nil {
+				continue
+			}
+			j.live[r.Seq] = append([]byte(nil), scanner.Bytes()...)
+		case opDel:
+			delete(j.live, r.Seq)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// put writes the job into the log and returns its sequence number
+func (j *journal) put(item *Item, at time.Time) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	r := &record{
+		Op:   opPut,
+		Seq:  j.seq,
+		At:   at.UnixNano(),
+		Item: item,
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return 0, err
+	}
+
+	err = j.write(data)
+	if err != nil {
+		return 0, err
+	}
+
+	j.live[r.Seq] = data
+	return r.Seq, nil
+}
+
+// remove marks the job as acknowledged, nil journal is a no-op
+func (j *journal) remove(seq uint64) error {
+	if j == nil || seq == 0 {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.live[seq]; !ok {
+		return nil
+	}
+
+	delete(j.live, seq)
+
+	data, err := json.Marshal(&record{Op: opDel, Seq: seq})
+	if err != nil {
+		return err
+	}
+
+	return j.write(data)
+}
+
+func (j *journal) write(data []byte) error {
+	if j.file == nil {
+		return errors.Str("journal closed")
+	}
+
+	line := make([]byte, len(data)+1)
+	copy(line, data)
+	line[len(data)] = '\n'
+
+	// single write per record, so the record is either fully written or is the last broken line
+	_, err := j.file.Write(line)
+	return err
+}
+
+// compact writes live records into the temporary file and atomically replaces the log with it
+func (j *journal) compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, data := range j.live {
+		_, _ = w.Write(data)
+		_ = w.WriteByte('\n')
+	}
+
+	err = w.Flush()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, j.path)
+	if err != nil {
+		return err
+	}
+
+	if j.file != nil {
+		_ = j.file.Close()
+	}
+
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+func (j *journal) close() error {
+	err := j.compact()
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err = j.file.Close()
+	j.file = nil
+	return err
+}
@@ -0,0 +1,97 @@
+package memoryjobs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem(id string, delay int64) *Item {
+	return &Item{
+		Job:     "test",
+		Ident:   id,
+		Payload: "payload",
+		Headers: map[string][]string{"foo": {"bar"}},
+		Options: &Options{
+			Priority: 1,
+			Pipeline: "test-1",
+			Delay:    delay,
+		},
+	}
+}
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.wal")
+
+	j, restored, err := openJournal(path)
+	require.NoError(t, err)
+	assert.Len(t, restored, 0)
+
+	now := time.Now()
+	seq1, err := j.put(testItem("1", 0), now)
+	require.NoError(t, err)
+	seq2, err := j.put(testItem("2", 100), now.Add(time.Second*100))
+	require.NoError(t, err)
+	seq3, err := j.put(testItem("3", 0), now)
+	require.NoError(t, err)
+
+	require.NoError(t, j.remove(seq1))
+	// simulate crash: the file is not compacted, the last record is partially written
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":2,"se`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	j2, restored, err := openJournal(path)
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+
+	assert.Equal(t, seq2, restored[0].Seq)
+	assert.Equal(t, "2", restored[0].Item.Ident)
+	assert.Equal(t, int64(100), restored[0].Item.Options.Delay)
+	assert.Equal(t, now.Add(time.Second*100).UnixNano(), restored[0].At)
+	assert.Equal(t, seq3, restored[1].Seq)
+	assert.Equal(t, []string{"bar"}, restored[1].Item.Headers["foo"])
+
+	// sequence continues after the replay
+	seq4, err := j2.put(testItem("4", 0), now)
+	require.NoError(t, err)
+	assert.Greater(t, seq4, seq3)
+
+	require.NoError(t, j2.remove(seq2))
+	require.NoError(t, j2.remove(seq3))
+	require.NoError(t, j2.remove(seq4))
+	require.NoError(t, j2.close())
+
+	_, restored, err = openJournal(path)
+	require.NoError(t, err)
+	assert.Len(t, restored, 0)
+}
+
+func TestDelayQueueOrder(t *testing.T) {
+	dq := newDelayQueue()
+	now := time.Now()
+
+	dq.push(testItem("3", 3), now.Add(time.Second*3))
+	dq.push(testItem("1", 1), now.Add(time.Second))
+	dq.push(testItem("2", 2), now.Add(time.Second*2))
+
+	item, wait := dq.next(now)
+	assert.Nil(t, item)
+	assert.Equal(t, time.Second, wait)
+
+	for _, id := range []string{"1", "2", "3"} {
+		item, _ = dq.next(now.Add(time.Second * 5))
+		require.NotNil(t, item)
+		assert.Equal(t, id, item.ID())
+	}
+
+	item, wait = dq.next(now)
+	assert.Nil(t, item)
+	assert.Equal(t, time.Duration(0), wait)
+}
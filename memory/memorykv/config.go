@@ -4,6 +4,15 @@ package memorykv
 type Config struct {
 	// Interval for the check
 	Interval int
+
+	// MaxItems is the max number of the keys, 0 - unlimited
+	MaxItems int `mapstructure:"max_items"`
+
+	// MaxMemory is the approximate max size of the keys and values in MB, 0 - unlimited
+	MaxMemory int `mapstructure:"max_memory"`
+
+	// EvictionPolicy used when the limits are reached: lru or lfu, default: lru
+	EvictionPolicy string `mapstructure:"eviction_policy"`
 }
 
 // InitDefaults by default driver is turned off
@@ -11,4 +20,12 @@ func (c *Config) InitDefaults() {
 	if c.Interval == 0 {
 		c.Interval = 60 // seconds
 	}
+
+	if c.EvictionPolicy == "" {
+		c.EvictionPolicy = LRU
+	}
+}
+
+func (c *Config) bounded() bool {
+	return c.MaxItems > 0 || c.MaxMemory > 0
 }
@@ -0,0 +1,206 @@
+package memorykv
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// eviction policies
+const (
+	// LRU evicts the least recently used key
+	LRU string = "lru"
+	// LFU evicts the least frequently used key, the least recently used one among the keys with the same frequency
+	LFU string = "lfu"
+)
+
+// entryOverhead is the approximate memory used by the key besides the key and the value
+const entryOverhead int64 = 64
+
+type entry struct {
+	key  string
+	size int64
+
+	// lru
+	elem *list.Element
+
+	// lfu
+	freq  uint64
+	seq   uint64
+	index int
+}
+
+// evictor tracks the keys usage and the size of the bounded storage
+type evictor struct {
+	policy    string
+	maxItems  int
+	maxMemory int64
+
+	entries map[string]*entry
+	size    int64
+	// front is the most recently used
+	lru *list.List
+	lfu lfuHeap
+	seq uint64
+
+	evictions uint64
+}
+
+func newEvictor(policy string, maxItems int, maxMemory int64) *evictor {
+	return &evictor{
+		policy:    policy,
+		maxItems:  maxItems,
+		maxMemory: maxMemory,
+		entries:   make(map[string]*entry),
+		lru:       list.New(),
+	}
+}
+
+// add adds or updates the key and returns the keys to evict
+func (e *evictor) add(key string, size int64) []string {
+	size += int64(len(key)) + entryOverhead
+	if en, ok := e.entries[key]; ok {
+		e.size += size - en.size
+		en.size = size
+		e.touch(key)
+	} else {
+		en = &entry{key: key, size: size}
+		e.entries[key] = en
+		e.size += size
+
+		switch e.policy {
+		case LFU:
+			e.seq++
+			en.freq = 1
+			en.seq = e.seq
+			heap.Push(&e.lfu, en)
+		default:
+			en.elem = e.lru.PushFront(en)
+		}
+	}
+
+	var victims []string
+	for len(e.entries) > 1 && e.over() {
+		victim := e.victim(key)
+		if victim == "" {
+			break
+		}
+
+		e.remove(victim)
+		e.evictions++
+		victims = append(victims, victim)
+	}
+
+	return victims
+}
+
+// touch marks the key as used
+func (e *evictor) touch(key string) {
+	en, ok := e.entries[key]
+	if !ok {
+		return
+	}
+
+	switch e.policy {
+	case LFU:
+		e.seq++
+		en.freq++
+		en.seq = e.seq
+		heap.Fix(&e.lfu, en.index)
+	default:
+		e.lru.MoveToFront(en.elem)
+	}
+}
+
+func (e *evictor) remove(key string) {
+	en, ok := e.entries[key]
+	if !ok {
+		return
+	}
+
+	switch e.policy {
+	case LFU:
+		heap.Remove(&e.lfu, en.index)
+	default:
+		e.lru.Remove(en.elem)
+	}
+
+	e.size -= en.size
+	delete(e.entries, key)
+}
+
+func (e *evictor) reset() {
+	e.entries = make(map[string]*entry)
+	e.size = 0
+	e.lru.Init()
+	e.lfu = e.lfu[:0]
+}
+
+func (e *evictor) over() bool {
+	return (e.maxItems > 0 && len(e.entries) > e.maxItems) || (e.maxMemory > 0 && e.size > e.maxMemory)
+}
+
+// victim returns the key to evict, the just added key is evicted only if it's the only one
+func (e *evictor) victim(added string) string {
+	switch e.policy {
+	case LFU:
+		if len(e.lfu) == 0 {
+			return ""
+		}
+
+		if e.lfu[0].key != added {
+			return e.lfu[0].key
+		}
+
+		// the just added key has the lowest frequency, evict the next one
+		if len(e.lfu) > 1 {
+			next := 1
+			if len(e.lfu) > 2 && e.lfu.Less(2, 1) {
+				next = 2
+			}
+			return e.lfu[next].key
+		}
+
+		return ""
+	default:
+		back := e.lru.Back()
+		if back == nil {
+			return ""
+		}
+
+		return back.Value.(*entry).key
+	}
+}
+
+// lfuHeap is the min heap by the frequency and the last access
+type lfuHeap []*entry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq == h[j].freq {
+		return h[i].seq < h[j].seq
+	}
+
+	return h[i].freq < h[j].freq
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	en := x.(*entry)
+	en.index = len(*h)
+	*h = append(*h, en)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	en := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return en
+}
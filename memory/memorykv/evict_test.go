@@ -0,0 +1,66 @@
+package memorykv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictorLRU(t *testing.T) {
+	e := newEvictor(LRU, 2, 0)
+
+	assert.Empty(t, e.add("a", 1))
+	assert.Empty(t, e.add("b", 1))
+	e.touch("a")
+
+	// b is the least recently used
+	assert.Equal(t, []string{"b"}, e.add("c", 1))
+	// update doesn't evict
+	assert.Empty(t, e.add("a", 2))
+	assert.Equal(t, []string{"c"}, e.add("d", 1))
+
+	e.remove("a")
+	assert.Empty(t, e.add("e", 1))
+	assert.Len(t, e.entries, 2)
+	assert.Equal(t, uint64(2), e.evictions)
+}
+
+func TestEvictorLFU(t *testing.T) {
+	e := newEvictor(LFU, 3, 0)
+
+	assert.Empty(t, e.add("a", 1))
+	assert.Empty(t, e.add("b", 1))
+	assert.Empty(t, e.add("c", 1))
+	e.touch("a")
+	e.touch("a")
+	e.touch("c")
+
+	// b is the least frequently used, the new key is not evicted
+	assert.Equal(t, []string{"b"}, e.add("d", 1))
+	// d and c are used once, c was accessed later
+	assert.Equal(t, []string{"d"}, e.add("e", 1))
+	assert.Equal(t, []string{"e"}, e.add("f", 1))
+
+	_, ok := e.entries["a"]
+	assert.True(t, ok)
+}
+
+func TestEvictorMemory(t *testing.T) {
+	e := newEvictor(LRU, 0, 3*(entryOverhead+11))
+
+	assert.Empty(t, e.add("a", 10))
+	assert.Empty(t, e.add("b", 10))
+	assert.Empty(t, e.add("c", 10))
+	assert.Equal(t, 3*(entryOverhead+11), e.size)
+
+	// the bigger value evicts two keys
+	assert.Equal(t, []string{"a", "b"}, e.add("d", 60))
+
+	// the key bigger than the limit is kept if it's the only one
+	assert.Equal(t, []string{"c", "d"}, e.add("e", 1000))
+	assert.Len(t, e.entries, 1)
+
+	e.reset()
+	assert.Empty(t, e.entries)
+	assert.Equal(t, int64(0), e.size)
+}
@@ -1,8 +1,12 @@
 package memorykv
 
 import (
+	"bytes"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spiral/errors"
@@ -13,7 +17,17 @@ import (
 
 type Driver struct {
 	clearMu sync.RWMutex
-	heap    sync.Map
+	// rmwMu serializes the read-modify-write operations (counters and conditional writes)
+	rmwMu sync.Mutex
+	heap  sync.Map
+	// tags index, tag -> keys
+	tagsMu sync.Mutex
+	tags   map[string]map[string]struct{}
+	// evict is nil if the storage has no limits, all writes are serialized by the evictMu otherwise
+	evictMu sync.Mutex
+	evict   *evictor
+	hits    uint64
+	misses  uint64
 	// stop is used to stop keys GC and close boltdb connection
 	stop chan struct{}
 	log  logger.Logger
@@ -26,6 +40,7 @@ func NewInMemoryDriver(key string, log logger.Logger, cfgPlugin config.Configure
 	d := &Driver{
 		stop: make(chan struct{}),
 		log:  log,
+		tags: make(map[string]map[string]struct{}),
 	}
 
 	err := cfgPlugin.UnmarshalKey(key, &d.cfg)
@@ -39,6 +54,14 @@ func NewInMemoryDriver(key string, log logger.Logger, cfgPlugin config.Configure
 
 	d.cfg.InitDefaults()
 
+	if d.cfg.bounded() {
+		if d.cfg.EvictionPolicy != LRU && d.cfg.EvictionPolicy != LFU {
+			return nil, errors.E(op, errors.Errorf("unknown eviction policy: %s, supported: lru, lfu", d.cfg.EvictionPolicy))
+		}
+
+		d.evict = newEvictor(d.cfg.EvictionPolicy, d.cfg.MaxItems, int64(d.cfg.MaxMemory)*1024*1024)
+	}
+
 	go d.gc()
 
 	return d, nil
@@ -73,10 +96,14 @@ func (d *Driver) Get(key string) ([]byte, error) {
 	}
 
 	if data, exist := d.heap.Load(key); exist {
+		atomic.AddUint64(&d.hits, 1)
+		d.touch(key)
 		// here might be a panic
 		// but data only could be a string, see Set function
 		return data.(*kvv1.Item).Value, nil
 	}
+
+	atomic.AddUint64(&d.misses, 1)
 	return nil, nil
 }
 
@@ -98,8 +125,13 @@ func (d *Driver) MGet(keys ...string) (map[string][]byte, error) {
 
 	for i := range keys {
 		if value, ok := d.heap.Load(keys[i]); ok {
+			atomic.AddUint64(&d.hits, 1)
+			d.touch(keys[i])
 			m[keys[i]] = value.(*kvv1.Item).Value
+			continue
 		}
+
+		atomic.AddUint64(&d.misses, 1)
 	}
 
 	return m, nil
@@ -124,7 +156,7 @@ func (d *Driver) Set(items ...*kvv1.Item) error {
 			}
 		}
 
-		d.heap.Store(items[i].Key, items[i])
+		d.store(items[i])
 	}
 	return nil
 }
@@ -142,7 +174,7 @@ func (d *Driver) MExpire(items ...*kvv1.Item) error {
 		}
 
 		// if key exist, overwrite it value
-		if pItem, ok := d.heap.LoadAndDelete(items[i].Key); ok {
+		if pItem, ok := d.heap.Load(items[i].Key); ok {
 			// check that time is correct
 			_, err := time.Parse(time.RFC3339, items[i].Timeout)
 			if err != nil {
@@ -152,10 +184,11 @@ func (d *Driver) MExpire(items ...*kvv1.Item) error {
 			// guess that t is in the future
 			// in memory is just FOR TESTING PURPOSES
 			// LOGIC ISN'T IDEAL
-			d.heap.Store(items[i].Key, &kvv1.Item{
+			d.store(&kvv1.Item{
 				Key:     items[i].Key,
 				Value:   tmp.Value,
 				Timeout: items[i].Timeout,
+				Tags:    tmp.Tags,
 			})
 		}
 	}
@@ -187,6 +220,22 @@ func (d *Driver) TTL(keys ...string) (map[string]string, error) {
 	return m, nil
 }
 
+// Tags returns the tags of the tagged keys
+func (d *Driver) Tags(keys ...string) (map[string][]string, error) {
+	const op = errors.Op("in_memory_plugin_tags")
+	if keys == nil {
+		return nil, errors.E(op, errors.NoKeys)
+	}
+
+	m := make(map[string][]string, len(keys))
+	for i := range keys {
+		if item, ok := d.load(keys[i]); ok && len(item.Tags) > 0 {
+			m[keys[i]] = item.Tags
+		}
+	}
+	return m, nil
+}
+
 func (d *Driver) Delete(keys ...string) error {
 	const op = errors.Op("in_memory_plugin_delete")
 	if keys == nil {
@@ -202,8 +251,126 @@ func (d *Driver) Delete(keys ...string) error {
 	}
 
 	for i := range keys {
-		d.heap.Delete(keys[i])
+		d.remove(keys[i])
+	}
+	return nil
+}
+
+func (d *Driver) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("in_memory_plugin_incr")
+	return d.incr(op, key, delta, timeout)
+}
+
+func (d *Driver) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("in_memory_plugin_decr")
+	return d.incr(op, key, -delta, timeout)
+}
+
+// SetNX sets the item if the key doesn't exist or is expired, but not yet removed by the GC
+func (d *Driver) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("in_memory_plugin_setnx")
+	if item == nil {
+		return false, errors.E(op, errors.EmptyItem)
+	}
+
+	if strings.TrimSpace(item.Key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	if item.Timeout != "" {
+		_, err := time.Parse(time.RFC3339, item.Timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+	}
+
+	d.rmwMu.Lock()
+	defer d.rmwMu.Unlock()
+
+	if _, ok := d.load(item.Key); ok {
+		return false, nil
+	}
+
+	d.store(item)
+	return true, nil
+}
+
+func (d *Driver) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("in_memory_plugin_compare_and_swap")
+	if strings.TrimSpace(key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	if timeout != "" {
+		_, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+	}
+
+	d.rmwMu.Lock()
+	defer d.rmwMu.Unlock()
+
+	item, ok := d.load(key)
+	if !ok || !bytes.Equal(item.Value, oldValue) {
+		return false, nil
+	}
+
+	d.store(&kvv1.Item{
+		Key:     key,
+		Value:   newValue,
+		Timeout: timeout,
+		Tags:    item.Tags,
+	})
+
+	return true, nil
+}
+
+// Keys lists the keys in the sorted order, the cursor is the last key of the page
+func (d *Driver) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	const op = errors.Op("in_memory_plugin_keys")
+	if limit <= 0 {
+		return nil, "", errors.E(op, errors.Str("limit should be positive"))
+	}
+
+	// sync.Map is not ordered, collect all the keys after the cursor
+	keys := make([]string, 0, limit)
+	d.heap.Range(func(key, _ interface{}) bool {
+		k := key.(string)
+		if !strings.HasPrefix(k, prefix) || (cursor != "" && k <= cursor) {
+			return true
+		}
+
+		if _, ok := d.load(k); ok {
+			keys = append(keys, k)
+		}
+		return true
+	})
+
+	sort.Strings(keys)
+	if len(keys) <= limit {
+		return keys, "", nil
+	}
+
+	return keys[:limit], keys[limit-1], nil
+}
+
+// InvalidateTags deletes the tagged keys
+func (d *Driver) InvalidateTags(tags ...string) error {
+	const op = errors.Op("in_memory_plugin_invalidate_tags")
+	if tags == nil {
+		return errors.E(op, errors.Str("no tags provided"))
+	}
+
+	d.tagsMu.Lock()
+	defer d.tagsMu.Unlock()
+
+	for i := range tags {
+		for k := range d.tags[tags[i]] {
+			d.delete(k)
+		}
 	}
+
 	return nil
 }
 
@@ -212,6 +379,16 @@ func (d *Driver) Clear() error {
 	d.heap = sync.Map{}
 	d.clearMu.Unlock()
 
+	if d.evict != nil {
+		d.evictMu.Lock()
+		d.evict.reset()
+		d.evictMu.Unlock()
+	}
+
+	d.tagsMu.Lock()
+	d.tags = make(map[string]map[string]struct{})
+	d.tagsMu.Unlock()
+
 	return nil
 }
 
@@ -221,6 +398,148 @@ func (d *Driver) Stop() {
 
 // ================================== PRIVATE ======================================
 
+// store stores the item, replaces the key tags in the tags index and evicts the keys if the storage limits are reached
+func (d *Driver) store(item *kvv1.Item) {
+	d.tagsMu.Lock()
+	defer d.tagsMu.Unlock()
+
+	d.untag(item.Key)
+	d.tag(item)
+
+	if d.evict == nil {
+		d.heap.Store(item.Key, item)
+		return
+	}
+
+	d.evictMu.Lock()
+	defer d.evictMu.Unlock()
+
+	d.heap.Store(item.Key, item)
+	victims := d.evict.add(item.Key, int64(len(item.Value)+len(item.Timeout)))
+	for i := range victims {
+		d.untag(victims[i])
+		d.heap.Delete(victims[i])
+	}
+}
+
+func (d *Driver) remove(key string) {
+	d.tagsMu.Lock()
+	d.delete(key)
+	d.tagsMu.Unlock()
+}
+
+// delete removes the key and its tags from the tags index, tagsMu should be held
+func (d *Driver) delete(key string) {
+	d.untag(key)
+
+	if d.evict == nil {
+		d.heap.Delete(key)
+		return
+	}
+
+	d.evictMu.Lock()
+	d.heap.Delete(key)
+	d.evict.remove(key)
+	d.evictMu.Unlock()
+}
+
+// touch marks the key as used for the eviction policy
+func (d *Driver) touch(key string) {
+	if d.evict == nil {
+		return
+	}
+
+	d.evictMu.Lock()
+	d.evict.touch(key)
+	d.evictMu.Unlock()
+}
+
+// tag adds the item key to the tags index, tagsMu should be held
+func (d *Driver) tag(item *kvv1.Item) {
+	for _, t := range item.Tags {
+		if _, ok := d.tags[t]; !ok {
+			d.tags[t] = make(map[string]struct{})
+		}
+		d.tags[t][item.Key] = struct{}{}
+	}
+}
+
+// untag removes the stored key from the tags index, tagsMu should be held
+func (d *Driver) untag(key string) {
+	data, ok := d.heap.Load(key)
+	if !ok {
+		return
+	}
+
+	for _, t := range data.(*kvv1.Item).Tags {
+		delete(d.tags[t], key)
+		if len(d.tags[t]) == 0 {
+			delete(d.tags, t)
+		}
+	}
+}
+
+// load returns the item if it's not expired
+func (d *Driver) load(key string) (*kvv1.Item, bool) {
+	data, ok := d.heap.Load(key)
+	if !ok {
+		return nil, false
+	}
+
+	item := data.(*kvv1.Item)
+	if item.Timeout != "" {
+		t, err := time.Parse(time.RFC3339, item.Timeout)
+		if err == nil && time.Now().After(t) {
+			return nil, false
+		}
+	}
+
+	return item, true
+}
+
+func (d *Driver) incr(op errors.Op, key string, delta int64, timeout string) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, errors.E(op, errors.EmptyKey)
+	}
+
+	if timeout != "" {
+		_, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return 0, errors.E(op, err)
+		}
+	}
+
+	d.rmwMu.Lock()
+	defer d.rmwMu.Unlock()
+
+	// expired, but not yet removed by the GC key is started from the delta with the new TTL
+	var v int64
+	var tags []string
+	if item, ok := d.load(key); ok {
+		var err error
+		v, err = strconv.ParseInt(string(item.Value), 10, 64)
+		if err != nil {
+			return 0, errors.E(op, errors.Errorf("value is not an integer: %s", key))
+		}
+
+		// keep the existing TTL and tags
+		if item.Timeout != "" {
+			timeout = item.Timeout
+		}
+		tags = item.Tags
+	}
+
+	v += delta
+	d.store(&kvv1.Item{
+		Key:     key,
+		Value:   []byte(strconv.FormatInt(v, 10)),
+		Timeout: timeout,
+		Tags:    tags,
+	})
+
+	return v, nil
+}
+
 func (d *Driver) gc() {
 	ticker := time.NewTicker(time.Duration(d.cfg.Interval) * time.Second)
 	defer ticker.Stop()
@@ -246,7 +565,7 @@ func (d *Driver) gc() {
 
 				if now.After(t) {
 					d.log.Debug("key deleted", "key", key)
-					d.heap.Delete(key)
+					d.remove(key.(string))
 				}
 				return true
 			})
@@ -255,3 +574,41 @@ func (d *Driver) gc() {
 		}
 	}
 }
+
+// Stats is the storage usage
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Items     int
+	// Memory is the approximate size of the keys and values in bytes
+	Memory int64
+}
+
+// Stats returns the storage usage, the unbounded storage is scanned to count the items
+func (d *Driver) Stats() *Stats {
+	st := &Stats{
+		Hits:   atomic.LoadUint64(&d.hits),
+		Misses: atomic.LoadUint64(&d.misses),
+	}
+
+	if d.evict != nil {
+		d.evictMu.Lock()
+		st.Evictions = d.evict.evictions
+		st.Items = len(d.evict.entries)
+		st.Memory = d.evict.size
+		d.evictMu.Unlock()
+		return st
+	}
+
+	d.clearMu.RLock()
+	d.heap.Range(func(key, value interface{}) bool {
+		v := value.(*kvv1.Item)
+		st.Items++
+		st.Memory += int64(len(v.Key)+len(v.Value)+len(v.Timeout)) + entryOverhead
+		return true
+	})
+	d.clearMu.RUnlock()
+
+	return st
+}
@@ -0,0 +1,95 @@
+package memorykv
+
+import (
+	"testing"
+	"time"
+
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDriver creates the driver without the GC loop, so the expired keys are never removed
+func newTestDriver() *Driver {
+	return &Driver{
+		cfg:  &Config{},
+		stop: make(chan struct{}),
+		tags: make(map[string]map[string]struct{}),
+	}
+}
+
+func TestIncrExpired(t *testing.T) {
+	d := newTestDriver()
+
+	past := time.Now().Add(-time.Second).Format(time.RFC3339)
+	require.NoError(t, d.Set(&kvv1.Item{Key: "counter", Value: []byte("10"), Timeout: past}))
+
+	// expired, but not removed key is started from the delta with the new TTL
+	tt := time.Now().Add(time.Minute).Format(time.RFC3339)
+	v, err := d.Incr("counter", 2, tt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	ttl, err := d.TTL("counter")
+	require.NoError(t, err)
+	assert.Equal(t, tt, ttl["counter"])
+
+	// live key keeps the TTL
+	v, err = d.Decr("counter", 1, time.Now().Add(time.Hour).Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	ttl, err = d.TTL("counter")
+	require.NoError(t, err)
+	assert.Equal(t, tt, ttl["counter"])
+}
+
+func TestTagsIndex(t *testing.T) {
+	d := newTestDriver()
+
+	past := time.Now().Add(-time.Second).Format(time.RFC3339)
+	require.NoError(t, d.Set(
+		&kvv1.Item{Key: "a", Value: []byte("a"), Tags: []string{"t1", "t2"}},
+		&kvv1.Item{Key: "b", Value: []byte("b"), Tags: []string{"t1"}},
+		&kvv1.Item{Key: "c", Value: []byte("c"), Tags: []string{"t2"}},
+		&kvv1.Item{Key: "d", Value: []byte("d"), Tags: []string{"t4"}, Timeout: past},
+	))
+
+	// re-set without the tags, delete and expiration remove the keys from the index
+	require.NoError(t, d.Set(&kvv1.Item{Key: "a", Value: []byte("a2")}))
+	require.NoError(t, d.Delete("c"))
+	d.remove("d")
+	assert.Equal(t, map[string]map[string]struct{}{"t1": {"b": {}}}, d.tags)
+
+	tags, err := d.Tags("a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"b": {"t1"}}, tags)
+
+	require.NoError(t, d.InvalidateTags("t1", "t2"))
+	m, err := d.Has("a", "b")
+	require.NoError(t, err)
+	assert.True(t, m["a"])
+	assert.False(t, m["b"])
+	assert.Empty(t, d.tags)
+
+	// counters and CAS keep the tags
+	require.NoError(t, d.Set(&kvv1.Item{Key: "n", Value: []byte("1"), Tags: []string{"t3"}}))
+	_, err = d.Incr("n", 1, "")
+	require.NoError(t, err)
+	ok, err := d.CompareAndSwap("n", []byte("2"), []byte("3"), "")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, d.InvalidateTags("t3"))
+	m, err = d.Has("n")
+	require.NoError(t, err)
+	assert.False(t, m["n"])
+}
+
+func TestTagsEviction(t *testing.T) {
+	d := newTestDriver()
+	d.evict = newEvictor(LRU, 1, 0)
+
+	require.NoError(t, d.Set(&kvv1.Item{Key: "a", Value: []byte("a"), Tags: []string{"t1"}}))
+	require.NoError(t, d.Set(&kvv1.Item{Key: "b", Value: []byte("b")}))
+	assert.Empty(t, d.tags)
+}
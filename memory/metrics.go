@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spiral/roadrunner-plugins/v2/memory/memorykv"
+)
+
+const (
+	namespace = "rr_kv_memory"
+)
+
+var (
+	kvHits      = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "hits"), "Number of the keys found in the in-memory storage.", []string{"storage"}, nil)
+	kvMisses    = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "misses"), "Number of the keys not found in the in-memory storage.", []string{"storage"}, nil)
+	kvEvictions = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "evictions"), "Number of the keys evicted from the in-memory storage.", []string{"storage"}, nil)
+	kvItems     = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "items"), "Number of the keys in the in-memory storage.", []string{"storage"}, nil)
+	kvMemory    = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "memory_bytes"), "Approximate size of the keys and values in the in-memory storage.", []string{"storage"}, nil)
+)
+
+func (p *Plugin) MetricsCollector() []prometheus.Collector {
+	return []prometheus.Collector{&kvExporter{storages: &p.storages}}
+}
+
+// kvExporter exports the in-memory KV storages usage
+type kvExporter struct {
+	// storage name -> *memorykv.Driver
+	storages *sync.Map
+}
+
+func (e *kvExporter) Describe(d chan<- *prometheus.Desc) {
+	d <- kvHits
+	d <- kvMisses
+	d <- kvEvictions
+	d <- kvItems
+	d <- kvMemory
+}
+
+func (e *kvExporter) Collect(ch chan<- prometheus.Metric) {
+	e.storages.Range(func(key, value interface{}) bool {
+		name := key.(string)
+		st := value.(*memorykv.Driver).Stats()
+
+		ch <- prometheus.MustNewConstMetric(kvHits, prometheus.CounterValue, float64(st.Hits), name)
+		ch <- prometheus.MustNewConstMetric(kvMisses, prometheus.CounterValue, float64(st.Misses), name)
+		ch <- prometheus.MustNewConstMetric(kvEvictions, prometheus.CounterValue, float64(st.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(kvItems, prometheus.GaugeValue, float64(st.Items), name)
+		ch <- prometheus.MustNewConstMetric(kvMemory, prometheus.GaugeValue, float64(st.Memory), name)
+		return true
+	})
+}
+
+// storageName returns the storage name from the local (kv.<name>.config) or the global (<name>) configuration key
+func storageName(key string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(key, "kv."), ".config")
+}
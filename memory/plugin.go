@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"sync"
+
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
 	"github.com/spiral/roadrunner-plugins/v2/api/kv"
@@ -19,6 +21,8 @@ const PluginName string = "memory"
 type Plugin struct {
 	log logger.Logger
 	cfg config.Configurer
+	// kv storages for the metrics, name -> *memorykv.Driver
+	storages sync.Map
 }
 
 func (p *Plugin) Init(log logger.Logger, cfg config.Configurer) error {
@@ -45,6 +49,8 @@ func (p *Plugin) KvFromConfig(key string) (kv.Storage, error) {
 	if err != nil {
 		return nil, errors.E(op, err)
 	}
+
+	p.storages.Store(storageName(key), st)
 	return st, nil
 }
 
@@ -0,0 +1,46 @@
+package mirrorkv
+
+const (
+	primary     string = "primary"
+	secondaries string = "secondaries"
+
+	// Sync - writes are mirrored before the response, the secondary errors are returned
+	Sync string = "sync"
+	// Async - writes are mirrored in the background in the same order, the secondary errors are logged
+	Async string = "async"
+
+	defaultBuffer int = 1000
+)
+
+// Config is the mirror storage configuration
+type Config struct {
+	// Primary is the storage configuration (driver and config sections) used for the reads and writes
+	Primary *Target `mapstructure:"primary"`
+
+	// Secondaries are the storages configurations (key - secondary name) the writes are mirrored into
+	Secondaries map[string]*Target `mapstructure:"secondaries"`
+
+	// Mode is the mirroring mode: sync or async, default: async
+	Mode string `mapstructure:"mode"`
+
+	// Compare is the name of the secondary to compare the reads with, mismatches are logged. Empty - no comparison.
+	Compare string `mapstructure:"compare"`
+
+	// Buffer is the max number of the pending async operations per secondary, default: 1000
+	Buffer int `mapstructure:"buffer"`
+}
+
+// Target is the storage configuration, the driver config is in the config section
+type Target struct {
+	Driver string `mapstructure:"driver"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.Mode == "" {
+		c.Mode = Async
+	}
+
+	if c.Buffer <= 0 {
+		c.Buffer = defaultBuffer
+	}
+}
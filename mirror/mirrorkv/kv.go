@@ -0,0 +1,532 @@
+package mirrorkv
+
+import (
+	"bytes"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+const (
+	pluginName string = "mirror"
+	// number of the key locks, see keyLock
+	keyLocks int = 64
+)
+
+// operation is the write replayed on the secondary
+type operation struct {
+	name string
+	fn   func(st kv.Storage) error
+}
+
+type secondary struct {
+	name string
+	st   kv.Storage
+	// async operations, nil in the sync mode
+	ops  chan *operation
+	done chan struct{}
+}
+
+// Storage reads from the primary storage, writes go to the primary first and then are mirrored into the secondaries.
+// Conditional writes (SetNX, CompareAndSwap) are mirrored as Set only if they were applied on the primary.
+type Storage struct {
+	log     logger.Logger
+	mode    string
+	primary kv.Storage
+	// sorted by name
+	secondaries []*secondary
+	// compare is the secondary to compare the reads with, might be nil
+	compare    *secondary
+	mismatches uint64
+
+	// guards the async queues close
+	mu      sync.RWMutex
+	stopped bool
+
+	// serialize the primary write and the mirroring of the read-modify-write operations on the same key,
+	// so the values are mirrored in the primary order
+	keys [keyLocks]sync.Mutex
+}
+
+// FromConfig creates the mirror storage, storages are initialized from the configKey.primary.config and
+// configKey.secondaries.<name>.config sections
+func FromConfig(configKey string, constructors map[string]kv.Constructor, log logger.Logger, cfg config.Configurer) (*Storage, error) {
+	const op = errors.Op("mirror_storage_from_config")
+
+	var conf *Config
+	err := cfg.UnmarshalKey(configKey, &conf)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if conf == nil {
+		return nil, errors.E(op, errors.Errorf("config not found by provided key: %s", configKey))
+	}
+
+	conf.InitDefaults()
+
+	if conf.Mode != Sync && conf.Mode != Async {
+		return nil, errors.E(op, errors.Errorf("unknown mode: %s, supported: sync, async", conf.Mode))
+	}
+
+	if len(conf.Secondaries) == 0 {
+		return nil, errors.E(op, errors.Str("no secondaries configured"))
+	}
+
+	if _, ok := conf.Secondaries[conf.Compare]; conf.Compare != "" && !ok {
+		return nil, errors.E(op, errors.Errorf("no such secondary to compare the reads with: %s", conf.Compare))
+	}
+
+	s := &Storage{
+		log:  log,
+		mode: conf.Mode,
+	}
+
+	s.primary, err = target(configKey+"."+primary, conf.Primary, constructors)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	names := make([]string, 0, len(conf.Secondaries))
+	for name := range conf.Secondaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		st, errT := target(configKey+"."+secondaries+"."+name, conf.Secondaries[name], constructors)
+		if errT != nil {
+			s.stopStorages()
+			return nil, errors.E(op, errT)
+		}
+
+		sec := &secondary{name: name, st: st}
+		s.secondaries = append(s.secondaries, sec)
+		if name == conf.Compare {
+			s.compare = sec
+		}
+	}
+
+	if s.mode == Async {
+		for _, sec := range s.secondaries {
+			sec.ops = make(chan *operation, conf.Buffer)
+			sec.done = make(chan struct{})
+			go s.replay(sec)
+		}
+	}
+
+	return s, nil
+}
+
+func target(key string, t *Target, constructors map[string]kv.Constructor) (kv.Storage, error) {
+	if t == nil || t.Driver == "" {
+		return nil, errors.Errorf("could not find mandatory driver field in the %s section", key)
+	}
+
+	if t.Driver == pluginName {
+		return nil, errors.Errorf("nested %s storages are not supported, section: %s", pluginName, key)
+	}
+
+	c, ok := constructors[t.Driver]
+	if !ok {
+		return nil, errors.Errorf("no constructors registered for the %s section, requested: %s", key, t.Driver)
+	}
+
+	return c.KvFromConfig(key + ".config")
+}
+
+// replay applies the async operations on the secondary until the queue is closed
+func (s *Storage) replay(sec *secondary) {
+	defer close(sec.done)
+
+	for o := range sec.ops {
+		err := o.fn(sec.st)
+		if err != nil {
+			s.log.Error("mirror operation failed", "error", err, "operation", o.name, "secondary", sec.name)
+		}
+	}
+}
+
+// mirror applies the operation on all secondaries, the first error is returned in the sync mode
+func (s *Storage) mirror(name string, fn func(st kv.Storage) error) error {
+	if s.mode == Sync {
+		var first error
+		for _, sec := range s.secondaries {
+			err := fn(sec.st)
+			if err != nil {
+				s.log.Error("mirror operation failed", "error", err, "operation", name, "secondary", sec.name)
+				if first == nil {
+					first = errors.Errorf("secondary: %s, error: %v", sec.name, err)
+				}
+			}
+		}
+
+		return first
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.stopped {
+		return nil
+	}
+
+	for _, sec := range s.secondaries {
+		s.enqueue(sec, &operation{name: name, fn: fn})
+	}
+
+	return nil
+}
+
+func (s *Storage) enqueue(sec *secondary, o *operation) {
+	select {
+	case sec.ops <- o:
+	default:
+		s.log.Warn("mirror queue is full, operation dropped", "operation", o.name, "secondary", sec.name)
+	}
+}
+
+// compareReads compares the values read from the primary with the compare secondary
+func (s *Storage) compareReads(values map[string][]byte, keys ...string) {
+	if s.compare == nil {
+		return
+	}
+
+	fn := func(st kv.Storage) error {
+		m, err := st.MGet(keys...)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			pv, pok := values[k]
+			sv, sok := m[k]
+			if pok != sok || !bytes.Equal(pv, sv) {
+				total := atomic.AddUint64(&s.mismatches, 1)
+				s.log.Warn("mirror read mismatch", "key", k, "secondary", s.compare.name, "primary_found", pok, "secondary_found", sok, "total", total)
+			}
+		}
+
+		return nil
+	}
+
+	if s.mode == Sync {
+		err := fn(s.compare.st)
+		if err != nil {
+			s.log.Error("mirror compare failed", "error", err, "secondary", s.compare.name)
+		}
+		return
+	}
+
+	// async compare is queued after the pending writes, so the lag is not reported as a mismatch
+	s.mu.RLock()
+	if !s.stopped {
+		s.enqueue(s.compare, &operation{name: "compare", fn: fn})
+	}
+	s.mu.RUnlock()
+}
+
+func (s *Storage) Has(keys ...string) (map[string]bool, error) {
+	return s.primary.Has(keys...)
+}
+
+func (s *Storage) Get(key string) ([]byte, error) {
+	const op = errors.Op("mirror_storage_get")
+	v, err := s.primary.Get(key)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if s.compare != nil {
+		values := make(map[string][]byte, 1)
+		if v != nil {
+			values[key] = v
+		}
+		s.compareReads(values, key)
+	}
+
+	return v, nil
+}
+
+func (s *Storage) MGet(keys ...string) (map[string][]byte, error) {
+	const op = errors.Op("mirror_storage_mget")
+	m, err := s.primary.MGet(keys...)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	s.compareReads(m, keys...)
+	return m, nil
+}
+
+func (s *Storage) Set(items ...*kvv1.Item) error {
+	const op = errors.Op("mirror_storage_set")
+	err := s.primary.Set(items...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.mirror("set", func(st kv.Storage) error {
+		return st.Set(items...)
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) MExpire(items ...*kvv1.Item) error {
+	const op = errors.Op("mirror_storage_mexpire")
+	err := s.primary.MExpire(items...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.mirror("mexpire", func(st kv.Storage) error {
+		return st.MExpire(items...)
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TTL(keys ...string) (map[string]string, error) {
+	return s.primary.TTL(keys...)
+}
+
+// Tags returns the tags of the primary storage keys, empty if the primary storage doesn't return the tags
+func (s *Storage) Tags(keys ...string) (map[string][]string, error) {
+	if t, ok := s.primary.(kv.Tagger); ok {
+		return t.Tags(keys...)
+	}
+
+	return map[string][]string{}, nil
+}
+
+func (s *Storage) Clear() error {
+	const op = errors.Op("mirror_storage_clear")
+	err := s.primary.Clear()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.mirror("clear", func(st kv.Storage) error {
+		return st.Clear()
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Delete(keys ...string) error {
+	const op = errors.Op("mirror_storage_delete")
+	err := s.primary.Delete(keys...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.mirror("delete", func(st kv.Storage) error {
+		return st.Delete(keys...)
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("mirror_storage_incr")
+	mu := s.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	v, err := s.primary.Incr(key, delta, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	err = s.mirrorCounter("incr", key, v, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+func (s *Storage) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("mirror_storage_decr")
+	mu := s.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	v, err := s.primary.Decr(key, delta, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	err = s.mirrorCounter("decr", key, v, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+// mirrorCounter mirrors the counter as Set of the value returned by the primary with the primary TTL and tags, so
+// the secondaries don't drift when the delta is replayed on the diverged or expired value. The timeout of the call is
+// used if the primary doesn't return the TTL (e.g. memcached).
+func (s *Storage) mirrorCounter(name, key string, v int64, timeout string) error {
+	ttl, err := s.primary.TTL(key)
+	if err == nil {
+		timeout = kv.Timeout(ttl[key], time.Now())
+	}
+
+	item := &kvv1.Item{
+		Key:     key,
+		Value:   []byte(strconv.FormatInt(v, 10)),
+		Timeout: timeout,
+		Tags:    s.tags(key),
+	}
+
+	return s.mirror(name, func(st kv.Storage) error {
+		return st.Set(item)
+	})
+}
+
+// tags returns the key tags if the primary is a kv.Tagger, so the mirrored Set keeps the key in the secondaries tags
+func (s *Storage) tags(key string) []string {
+	t, ok := s.primary.(kv.Tagger)
+	if !ok {
+		return nil
+	}
+
+	tags, err := t.Tags(key)
+	if err != nil {
+		s.log.Warn("mirror tags reading failed, the key is mirrored without the tags", "error", err, "key", key)
+		return nil
+	}
+
+	return tags[key]
+}
+
+// keyLock returns the lock of the key, see Storage.keys
+func (s *Storage) keyLock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &s.keys[h.Sum32()%uint32(keyLocks)]
+}
+
+func (s *Storage) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("mirror_storage_setnx")
+	ok, err := s.primary.SetNX(item)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	err = s.mirror("setnx", func(st kv.Storage) error {
+		return st.Set(item)
+	})
+	if err != nil {
+		return true, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+func (s *Storage) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("mirror_storage_cas")
+	mu := s.keyLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	ok, err := s.primary.CompareAndSwap(key, oldValue, newValue, timeout)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	item := &kvv1.Item{Key: key, Value: newValue, Timeout: timeout, Tags: s.tags(key)}
+	err = s.mirror("cas", func(st kv.Storage) error {
+		return st.Set(item)
+	})
+	if err != nil {
+		return true, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+func (s *Storage) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	return s.primary.Keys(prefix, cursor, limit)
+}
+
+func (s *Storage) InvalidateTags(tags ...string) error {
+	const op = errors.Op("mirror_storage_invalidate_tags")
+	err := s.primary.InvalidateTags(tags...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.mirror("invalidate_tags", func(st kv.Storage) error {
+		return st.InvalidateTags(tags...)
+	})
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// Stop waits for the pending async operations and stops the storages
+func (s *Storage) Stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+
+	s.stopped = true
+	for _, sec := range s.secondaries {
+		if sec.ops != nil {
+			close(sec.ops)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sec := range s.secondaries {
+		if sec.done != nil {
+			<-sec.done
+		}
+	}
+
+	s.stopStorages()
+}
+
+func (s *Storage) stopStorages() {
+	s.primary.Stop()
+	for _, sec := range s.secondaries {
+		sec.st.Stop()
+	}
+}
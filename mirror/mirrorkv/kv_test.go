@@ -0,0 +1,193 @@
+package mirrorkv
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spiral/errors"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/kv/kvtest"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestStorage(mode string, secondaries ...*kvtest.Storage) (*Storage, *kvtest.Storage) {
+	p := kvtest.NewStorage()
+	s := &Storage{
+		log:     logger.NewZapAdapter(zap.NewNop()),
+		mode:    mode,
+		primary: p,
+	}
+
+	for i, st := range secondaries {
+		sec := &secondary{name: string(rune('a' + i)), st: st}
+		if mode == Async {
+			sec.ops = make(chan *operation, 1000)
+			sec.done = make(chan struct{})
+			go s.replay(sec)
+		}
+		s.secondaries = append(s.secondaries, sec)
+	}
+
+	return s, p
+}
+
+func TestMirrorSync(t *testing.T) {
+	sa, sb := kvtest.NewStorage(), kvtest.NewStorage()
+	s, p := newTestStorage(Sync, sa, sb)
+
+	require.NoError(t, s.Set(&kvv1.Item{Key: "a", Value: []byte("1")}))
+	assert.Equal(t, []byte("1"), p.Value("a"))
+	assert.Equal(t, []byte("1"), sa.Value("a"))
+	assert.Equal(t, []byte("1"), sb.Value("a"))
+
+	// not applied on the primary
+	require.NoError(t, sa.Delete("a"))
+	ok, err := s.SetNX(&kvv1.Item{Key: "a", Value: []byte("2")})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, sa.Value("a"))
+
+	// secondary errors are returned, other secondaries are written
+	sa.Fail(errors.Str("connection refused"))
+	err = s.Set(&kvv1.Item{Key: "b", Value: []byte("1")})
+	assert.Error(t, err)
+	assert.Equal(t, []byte("1"), p.Value("b"))
+	assert.Equal(t, []byte("1"), sb.Value("b"))
+
+	s.Stop()
+	assert.True(t, p.Stopped())
+	assert.True(t, sa.Stopped())
+}
+
+func TestMirrorAsync(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, p := newTestStorage(Async, sa)
+
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Set(&kvv1.Item{Key: k, Value: []byte(k)}))
+	}
+	require.NoError(t, s.Delete("b"))
+
+	// pending operations are applied before the stop
+	s.Stop()
+	assert.Equal(t, p.Values(), sa.Values())
+	assert.Len(t, sa.Values(), 2)
+
+	// writes after the stop are not mirrored
+	require.NoError(t, s.Set(&kvv1.Item{Key: "d", Value: []byte("d")}))
+	assert.Nil(t, sa.Value("d"))
+
+	// second stop is no-op
+	s.Stop()
+}
+
+func TestMirrorCompare(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, p := newTestStorage(Sync, sa)
+	s.compare = s.secondaries[0]
+
+	require.NoError(t, s.Set(&kvv1.Item{Key: "a", Value: []byte("1")}))
+	require.NoError(t, p.Set(&kvv1.Item{Key: "b", Value: []byte("1")}))
+
+	_, err := s.MGet("a")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), atomic.LoadUint64(&s.mismatches))
+
+	// missing in the secondary
+	_, err = s.MGet("a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&s.mismatches))
+
+	// another value
+	require.NoError(t, sa.Set(&kvv1.Item{Key: "a", Value: []byte("2")}))
+	_, err = s.MGet("a")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&s.mismatches))
+}
+
+func TestMirrorIncr(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, p := newTestStorage(Sync, sa)
+
+	tm := time.Now().Add(time.Hour).Format(time.RFC3339)
+	v, err := s.Incr("c", 5, tm)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+	assert.Equal(t, []byte("5"), sa.Value("c"))
+	assert.Equal(t, tm, sa.Item("c").Timeout)
+
+	// diverged secondary gets the primary value, not the replayed delta
+	require.NoError(t, sa.Set(&kvv1.Item{Key: "c", Value: []byte("100")}))
+	v, err = s.Decr("c", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+	assert.Equal(t, p.Value("c"), sa.Value("c"))
+	assert.Equal(t, tm, sa.Item("c").Timeout)
+}
+
+// noTTL is the primary without the TTL support, e.g. memcached
+type noTTL struct {
+	*kvtest.Storage
+}
+
+func (n *noTTL) TTL(_ ...string) (map[string]string, error) {
+	return nil, errors.Str("not supported")
+}
+
+func TestMirrorIncrNoTTL(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, _ := newTestStorage(Sync, sa)
+	s.primary = &noTTL{Storage: kvtest.NewStorage()}
+
+	tm := time.Now().Add(time.Hour).Format(time.RFC3339)
+	v, err := s.Incr("c", 1, tm)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+	assert.Equal(t, []byte("1"), sa.Value("c"))
+	assert.Equal(t, tm, sa.Item("c").Timeout)
+}
+
+func TestMirrorKeepTags(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, _ := newTestStorage(Sync, sa)
+
+	require.NoError(t, s.Set(&kvv1.Item{Key: "c", Value: []byte("1"), Tags: []string{"t"}}))
+	_, err := s.Incr("c", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t"}, sa.Item("c").Tags)
+
+	ok, err := s.CompareAndSwap("c", []byte("2"), []byte("3"), "")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"t"}, sa.Item("c").Tags)
+
+	// the key is invalidated on the secondary too
+	require.NoError(t, s.InvalidateTags("t"))
+	assert.Nil(t, sa.Value("c"))
+}
+
+func TestMirrorAsyncCountersOrder(t *testing.T) {
+	sa := kvtest.NewStorage()
+	s, p := newTestStorage(Async, sa)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := s.Incr("c", 1, "")
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Stop()
+	assert.Equal(t, []byte("500"), p.Value("c"))
+	assert.Equal(t, p.Value("c"), sa.Value("c"))
+}
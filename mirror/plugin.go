@@ -0,0 +1,54 @@
+package mirror
+
+import (
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/mirror/mirrorkv"
+)
+
+const PluginName string = "mirror"
+
+// Plugin is a virtual KV driver, which writes into the primary storage and mirrors the writes into the secondary
+// storages of any driver, used to migrate between the KV backends.
+type Plugin struct {
+	log logger.Logger
+	cfg config.Configurer
+	// drivers for the primary and secondary storages
+	constructors map[string]kv.Constructor
+}
+
+func (p *Plugin) Init(log logger.Logger, cfg config.Configurer) error {
+	p.log = log
+	p.cfg = cfg
+	p.constructors = make(map[string]kv.Constructor)
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+func (p *Plugin) Collects() []interface{} {
+	return []interface{}{
+		p.CollectConstructors,
+	}
+}
+
+// CollectConstructors collects KV drivers used for the primary and secondary storages
+func (p *Plugin) CollectConstructors(name endure.Named, c kv.Constructor) {
+	// nested mirrors are not supported
+	if name.Name() == PluginName {
+		return
+	}
+
+	p.constructors[name.Name()] = c
+}
+
+// KvFromConfig creates new mirror storage from the configuration
+func (p *Plugin) KvFromConfig(key string) (kv.Storage, error) {
+	return mirrorkv.FromConfig(key, p.constructors, p.log, p.cfg)
+}
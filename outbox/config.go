@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"time"
+)
+
+const (
+	defaultTable     string = "rr_jobs_outbox"
+	defaultBatchSize int    = 100
+	defaultPriority  int64  = 10
+)
+
+// Config of the transactional outbox producer
+type Config struct {
+	// Driver is the database/sql driver name: sqlite3, postgres or mysql
+	Driver string `mapstructure:"driver"`
+
+	// DSN is the data source name passed to the driver
+	DSN string `mapstructure:"dsn"`
+
+	// Table with the outbox records, default: rr_jobs_outbox
+	Table string `mapstructure:"table"`
+
+	// Interval between the polls, default: 1s
+	Interval time.Duration `mapstructure:"interval"`
+
+	// BatchSize is the max number of records dispatched per poll, default: 100
+	BatchSize int `mapstructure:"batch_size"`
+
+	// Pipeline is used for the records with an empty pipeline column
+	Pipeline string `mapstructure:"pipeline"`
+
+	// DeleteDispatched deletes dispatched records instead of setting the dispatched_at column
+	DeleteDispatched bool `mapstructure:"delete_dispatched"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.Table == "" {
+		c.Table = defaultTable
+	}
+
+	if c.Interval == 0 {
+		c.Interval = time.Second
+	}
+
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultBatchSize
+	}
+}
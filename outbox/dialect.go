@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"strconv"
+
+	"github.com/spiral/errors"
+)
+
+// dialect contains database specific parts of the queries
+type dialect struct {
+	// placeholder returns the n-th (starting from 1) query parameter placeholder
+	placeholder func(n int) string
+	// lock is appended to the select query to skip the records locked by other instances
+	lock string
+}
+
+func dialectFor(driver string) (*dialect, error) {
+	switch driver {
+	case "sqlite3":
+		if !sqliteSupported {
+			return nil, errors.Str("sqlite3 outbox driver requires the cgo build (CGO_ENABLED=1)")
+		}
+		// sqlite locks the whole database on write
+		return &dialect{placeholder: question}, nil
+	case "mysql":
+		return &dialect{placeholder: question, lock: " FOR UPDATE SKIP LOCKED"}, nil
+	case "postgres":
+		return &dialect{placeholder: dollar, lock: " FOR UPDATE SKIP LOCKED"}, nil
+	default:
+		return nil, errors.Errorf("unsupported outbox driver: %s, supported: sqlite3, postgres, mysql", driver)
+	}
+}
+
+func question(_ int) string {
+	return "?"
+}
+
+func dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
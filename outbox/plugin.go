@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql" //nolint:revive
+	_ "github.com/lib/pq"              //nolint:revive
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+const PluginName string = "outbox"
+
+// Plugin polls the outbox table, which is written by the application in the same transaction with the business data,
+// and pushes the records into the jobs pipelines.
+type Plugin struct {
+	cfg     *Config
+	dialect *dialect
+	log     logger.Logger
+	pusher  jobs.Pusher
+
+	db     *sql.DB
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (p *Plugin) Init(cfg config.Configurer, log logger.Logger, pusher jobs.Pusher) error {
+	const op = errors.Op("outbox_plugin_init")
+	if !cfg.Has(PluginName) {
+		return errors.E(op, errors.Disabled)
+	}
+
+	err := cfg.UnmarshalKey(PluginName, &p.cfg)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	if p.cfg == nil {
+		p.cfg = &Config{}
+	}
+
+	p.cfg.InitDefaults()
+
+	if p.cfg.DSN == "" {
+		return errors.E(op, errors.Str("dsn should not be empty"))
+	}
+
+	p.dialect, err = dialectFor(p.cfg.Driver)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	p.log = log
+	p.pusher = pusher
+	p.stopCh = make(chan struct{})
+
+	return nil
+}
+
+func (p *Plugin) Serve() chan error {
+	const op = errors.Op("outbox_plugin_serve")
+	errCh := make(chan error, 1)
+
+	var err error
+	p.db, err = sql.Open(p.cfg.Driver, p.cfg.DSN)
+	if err != nil {
+		errCh <- errors.E(op, err)
+		return errCh
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	err = p.db.PingContext(ctx)
+	if err != nil {
+		errCh <- errors.E(op, err)
+		return errCh
+	}
+
+	pl := newPoller(p.cfg, p.db, p.dialect, p.pusher, p.log)
+
+	p.wg.Add(1)
+	go p.listen(pl)
+
+	return errCh
+}
+
+func (p *Plugin) Stop() error {
+	close(p.stopCh)
+	p.wg.Wait()
+
+	if p.db != nil {
+		return p.db.Close()
+	}
+
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) listen(pl *poller) {
+	defer p.wg.Done()
+
+	tt := time.NewTicker(p.cfg.Interval)
+	defer tt.Stop()
+
+	for {
+		select {
+		case <-tt.C:
+			// drain the backlog without waiting for the next tick
+			for {
+				start := time.Now()
+				n, err := pl.poll(context.Background())
+				if err != nil {
+					p.log.Error("outbox poll failed", "error", err)
+					break
+				}
+
+				if n > 0 {
+					p.log.Debug("outbox jobs dispatched", "count", n, "start", start, "elapsed", time.Since(start))
+				}
+
+				if n < p.cfg.BatchSize {
+					break
+				}
+
+				select {
+				case <-p.stopCh:
+					return
+				default:
+				}
+			}
+		case <-p.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// emptySection is the configuration with the empty outbox section, UnmarshalKey leaves the config untouched
+type emptySection struct {
+	config.Configurer
+}
+
+func (e *emptySection) Has(name string) bool {
+	return name == PluginName
+}
+
+func (e *emptySection) UnmarshalKey(_ string, _ interface{}) error {
+	return nil
+}
+
+func TestInitEmptySection(t *testing.T) {
+	p := &Plugin{}
+	err := p.Init(&emptySection{}, logger.NewZapAdapter(zap.NewNop()), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dsn should not be empty")
+}
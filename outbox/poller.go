@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+// record is a single row of the outbox table
+type record struct {
+	id       int64
+	job      string
+	pipeline sql.NullString
+	payload  string
+	headers  sql.NullString
+	priority sql.NullInt64
+	delay    sql.NullInt64
+}
+
+// poller moves records from the outbox table into the jobs pipelines
+type poller struct {
+	cfg    *Config
+	db     *sql.DB
+	pusher jobs.Pusher
+	log    logger.Logger
+
+	selectQuery string
+	markQuery   string
+	failQuery   string
+}
+
+func newPoller(cfg *Config, db *sql.DB, d *dialect, pusher jobs.Pusher, log logger.Logger) *poller {
+	p := &poller{
+		cfg:    cfg,
+		db:     db,
+		pusher: pusher,
+		log:    log,
+	}
+
+	p.selectQuery = "SELECT id, job, pipeline, payload, headers, priority, delay FROM " + cfg.Table +
+		" WHERE dispatched_at IS NULL AND dispatch_error IS NULL ORDER BY id LIMIT " + d.placeholder(1) + d.lock
+
+	if cfg.DeleteDispatched {
+		p.markQuery = "DELETE FROM " + cfg.Table + " WHERE id = " + d.placeholder(1)
+	} else {
+		p.markQuery = "UPDATE " + cfg.Table + " SET dispatched_at = " + d.placeholder(1) + " WHERE id = " + d.placeholder(2)
+	}
+
+	// malformed records and records of the not declared pipelines are moved aside, so they don't block the rest of the outbox
+	p.failQuery = "UPDATE " + cfg.Table + " SET dispatch_error = " + d.placeholder(1) + " WHERE id = " + d.placeholder(2)
+
+	return p
+}
+
+// poll dispatches the next batch of records and returns the number of the dispatched records.
+// Records are marked as dispatched in the same transaction only after the jobs driver confirmed the push,
+// the transaction is committed after the whole batch, so a failed commit leads to the redelivery of the batch.
+// Records which can't be converted into the job or pushed into the not declared pipeline are marked with
+// the dispatch_error and skipped, other push errors stop the batch.
+func (p *poller) poll(ctx context.Context) (int, error) {
+	const op = errors.Op("outbox_poll")
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	records, err := p.fetch(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, errors.E(op, err)
+	}
+
+	dispatched := 0
+	for i := 0; i < len(records); i++ {
+		j, errJ := p.toJob(records[i])
+		if errJ != nil {
+			p.log.Error("failed to convert the outbox record, record skipped", "id", records[i].id, "error", errJ)
+			_, err = tx.ExecContext(ctx, p.failQuery, errJ.Error(), records[i].id)
+			if err != nil {
+				_ = tx.Rollback()
+				return 0, errors.E(op, err)
+			}
+
+			continue
+		}
+
+		errJ = p.pusher.Push(j)
+		if errJ != nil {
+			// the push is never succeeded until the pipeline is declared
+			if jobs.IsNoSuchPipeline(errJ) {
+				p.log.Error("outbox job pipeline is not declared, record skipped", "id", records[i].id, "pipeline", j.Options.Pipeline, "error", errJ)
+				_, err = tx.ExecContext(ctx, p.failQuery, errJ.Error(), records[i].id)
+				if err != nil {
+					_ = tx.Rollback()
+					return 0, errors.E(op, err)
+				}
+
+				continue
+			}
+
+			// keep the order, the rest of the batch is retried on the next poll
+			p.log.Error("failed to push the outbox job", "id", records[i].id, "pipeline", j.Options.Pipeline, "error", errJ)
+			break
+		}
+
+		if p.cfg.DeleteDispatched {
+			_, err = tx.ExecContext(ctx, p.markQuery, records[i].id)
+		} else {
+			_, err = tx.ExecContext(ctx, p.markQuery, time.Now().Unix(), records[i].id)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, errors.E(op, err)
+		}
+
+		dispatched++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return dispatched, nil
+}
+
+func (p *poller) fetch(ctx context.Context, tx *sql.Tx) ([]*record, error) {
+	rows, err := tx.QueryContext(ctx, p.selectQuery, p.cfg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	records := make([]*record, 0, p.cfg.BatchSize)
+	for rows.Next() {
+		r := &record{}
+		err = rows.Scan(&r.id, &r.job, &r.pipeline, &r.payload, &r.headers, &r.priority, &r.delay)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
+
+func (p *poller) toJob(r *record) (*job.Job, error) {
+	j := &job.Job{
+		Job: r.job,
+		// row id is stable between the redeliveries, so the consumer might deduplicate jobs
+		Ident:   strconv.FormatInt(r.id, 10),
+		Payload: r.payload,
+		Headers: make(map[string][]string),
+		Options: &job.Options{
+			Priority: defaultPriority,
+			Pipeline: p.cfg.Pipeline,
+		},
+	}
+
+	if r.pipeline.Valid && r.pipeline.String != "" {
+		j.Options.Pipeline = r.pipeline.String
+	}
+
+	if r.priority.Valid {
+		j.Options.Priority = r.priority.Int64
+	}
+
+	if r.delay.Valid {
+		j.Options.Delay = r.delay.Int64
+	}
+
+	if r.headers.Valid && r.headers.String != "" {
+		err := json.Unmarshal([]byte(r.headers.String), &j.Headers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if j.Options.Pipeline == "" {
+		return nil, errors.Str("no pipeline for the outbox record")
+	}
+
+	return j, nil
+}
@@ -0,0 +1,195 @@
+//go:build cgo
+// +build cgo
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const schema = `CREATE TABLE rr_jobs_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job TEXT NOT NULL,
+	pipeline TEXT,
+	payload TEXT NOT NULL,
+	headers TEXT,
+	priority INTEGER,
+	delay INTEGER,
+	dispatched_at INTEGER,
+	dispatch_error TEXT
+)`
+
+type pusher struct {
+	pushed []*job.Job
+	// push fails for the job with this name
+	fail string
+	// pipeline which is not declared
+	undeclared string
+}
+
+func (p *pusher) Push(j *job.Job) error {
+	if j.Job == p.fail {
+		return errors.Str("push failed")
+	}
+
+	if j.Options.Pipeline == p.undeclared {
+		return errors.E(errors.Op("jobs_plugin_push"), &jobs.NoSuchPipelineError{Pipeline: j.Options.Pipeline})
+	}
+
+	p.pushed = append(p.pushed, j)
+	return nil
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func insert(t *testing.T, db *sql.DB, name, pipeline, headers string) {
+	_, err := db.Exec("INSERT INTO rr_jobs_outbox (job, pipeline, payload, headers, priority, delay) VALUES (?, ?, ?, ?, ?, ?)",
+		name, sql.NullString{String: pipeline, Valid: pipeline != ""}, `{"foo":"bar"}`, sql.NullString{String: headers, Valid: headers != ""}, 5, 0)
+	require.NoError(t, err)
+}
+
+func newTestPoller(t *testing.T, db *sql.DB, cfg *Config, p *pusher) *poller {
+	cfg.InitDefaults()
+	d, err := dialectFor("sqlite3")
+	require.NoError(t, err)
+
+	return newPoller(cfg, db, d, p, logger.NewZapAdapter(zap.NewNop()))
+}
+
+func TestPollDispatch(t *testing.T) {
+	db := openTestDB(t)
+	insert(t, db, "job-1", "test-1", `{"foo":["bar"]}`)
+	insert(t, db, "job-2", "", "")
+	insert(t, db, "job-3", "test-2", "")
+
+	p := &pusher{}
+	pl := newTestPoller(t, db, &Config{BatchSize: 2, Pipeline: "default"}, p)
+
+	n, err := pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	require.Len(t, p.pushed, 3)
+	assert.Equal(t, "1", p.pushed[0].Ident)
+	assert.Equal(t, "test-1", p.pushed[0].Options.Pipeline)
+	assert.Equal(t, []string{"bar"}, p.pushed[0].Headers["foo"])
+	assert.Equal(t, int64(5), p.pushed[0].Options.Priority)
+	assert.Equal(t, "default", p.pushed[1].Options.Pipeline)
+	assert.Equal(t, "test-2", p.pushed[2].Options.Pipeline)
+
+	var pending int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM rr_jobs_outbox WHERE dispatched_at IS NULL").Scan(&pending))
+	assert.Equal(t, 0, pending)
+}
+
+func TestPollFailedPush(t *testing.T) {
+	db := openTestDB(t)
+	insert(t, db, "job-1", "test-1", "")
+	insert(t, db, "job-2", "test-1", "")
+	insert(t, db, "job-3", "test-1", "")
+
+	p := &pusher{fail: "job-2"}
+	pl := newTestPoller(t, db, &Config{DeleteDispatched: true}, p)
+
+	// order is kept, the batch stops at the failed job
+	n, err := pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	p.fail = ""
+	n, err = pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.Len(t, p.pushed, 3)
+	assert.Equal(t, "job-2", p.pushed[1].Job)
+
+	var rows int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM rr_jobs_outbox").Scan(&rows))
+	assert.Equal(t, 0, rows)
+}
+
+func TestPollMalformed(t *testing.T) {
+	db := openTestDB(t)
+	insert(t, db, "job-1", "test-1", "{")
+	insert(t, db, "job-2", "", "")
+	insert(t, db, "job-3", "test-1", "")
+	insert(t, db, "job-4", "test-1", "")
+
+	p := &pusher{}
+	// no default pipeline, so the second record is malformed too
+	pl := newTestPoller(t, db, &Config{BatchSize: 3}, p)
+
+	n, err := pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// malformed records are not selected again
+	n, err = pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, p.pushed, 2)
+	assert.Equal(t, "job-3", p.pushed[0].Job)
+	assert.Equal(t, "job-4", p.pushed[1].Job)
+
+	var failed int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM rr_jobs_outbox WHERE dispatch_error IS NOT NULL AND dispatched_at IS NULL").Scan(&failed))
+	assert.Equal(t, 2, failed)
+}
+
+func TestPollNoSuchPipeline(t *testing.T) {
+	db := openTestDB(t)
+	insert(t, db, "job-1", "test-1", "")
+	insert(t, db, "job-2", "typo", "")
+	insert(t, db, "job-3", "test-1", "")
+
+	p := &pusher{undeclared: "typo"}
+	pl := newTestPoller(t, db, &Config{}, p)
+
+	// the record of the not declared pipeline doesn't block the outbox
+	n, err := pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.Len(t, p.pushed, 2)
+	assert.Equal(t, "job-3", p.pushed[1].Job)
+
+	var dispatchErr string
+	require.NoError(t, db.QueryRow("SELECT dispatch_error FROM rr_jobs_outbox WHERE job = 'job-2'").Scan(&dispatchErr))
+	assert.Contains(t, dispatchErr, "no such pipeline, requested: typo")
+
+	n, err = pl.poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,11 @@
+//go:build cgo
+// +build cgo
+
+package outbox
+
+import (
+	_ "github.com/mattn/go-sqlite3" //nolint:revive
+)
+
+// sqliteSupported is false for the CGO_ENABLED=0 builds, go-sqlite3 is a cgo package
+const sqliteSupported = true
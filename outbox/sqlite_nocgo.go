@@ -0,0 +1,7 @@
+//go:build !cgo
+// +build !cgo
+
+package outbox
+
+// sqliteSupported is false for the CGO_ENABLED=0 builds, go-sqlite3 is a cgo package
+const sqliteSupported = false
@@ -2,11 +2,14 @@ package kv
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
 	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
 	"github.com/spiral/roadrunner-plugins/v2/config"
 	"github.com/spiral/roadrunner-plugins/v2/logger"
@@ -17,6 +20,8 @@ type driver struct {
 	universalClient redis.UniversalClient
 	log             logger.Logger
 	cfg             *Config
+	// keyspace notifications subscription, nil if not used
+	pubsub *redis.PubSub
 }
 
 func NewRedisDriver(log logger.Logger, key string, cfgPlugin config.Configurer) (*driver, error) {
@@ -167,6 +172,19 @@ func (d *driver) Set(items ...*kvv1.Item) error {
 				return err
 			}
 		}
+
+		// tags index, the previous tags of the key are replaced
+		if d.cluster() {
+			if len(item.Tags) > 0 {
+				return errors.E(op, errors.Str("tags are not supported in the redis cluster mode"))
+			}
+			continue
+		}
+
+		err := d.tag(item)
+		if err != nil {
+			return errors.E(op, err)
+		}
 	}
 	return nil
 }
@@ -185,7 +203,18 @@ func (d *driver) Delete(keys ...string) error {
 			return errors.E(op, errors.EmptyKey)
 		}
 	}
-	return d.universalClient.Del(context.Background(), keys...).Err()
+
+	if d.cluster() {
+		return d.universalClient.Del(context.Background(), keys...).Err()
+	}
+
+	// keys are removed from the tags index
+	err := deleteScript.Run(context.Background(), d.universalClient, keys, tagPrefix, keyTagsPrefix).Err()
+	if err != nil && err != redis.Nil {
+		return errors.E(op, err)
+	}
+
+	return nil
 }
 
 // MExpire https://redis.io/commands/expire
@@ -206,6 +235,15 @@ func (d *driver) MExpire(items ...*kvv1.Item) error {
 			return err
 		}
 
+		if !d.cluster() {
+			// the TTL of the key tags is updated as well
+			err = expireScript.Run(context.Background(), d.universalClient, []string{item.Key}, ttlMs(item.Timeout), tagPrefix, keyTagsPrefix).Err()
+			if err != nil && err != redis.Nil {
+				return errors.E(op, err)
+			}
+			continue
+		}
+
 		// t guessed to be in future
 		// for Redis we use t.Sub, it will result in seconds, like 4.2s
 		d.universalClient.Expire(context.Background(), item.Key, t.Sub(now))
@@ -253,6 +291,254 @@ func (d *driver) Clear() error {
 }
 
 func (d *driver) Stop() {
+	if d.pubsub != nil {
+		_ = d.pubsub.Close()
+	}
+
 	// close the connection
 	_ = d.universalClient.Close()
 }
+
+// incrScript increments the key and sets the expiration time (unix seconds) only if the key has no TTL yet
+var incrScript = redis.NewScript(`
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if ARGV[2] ~= '' and redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIREAT', KEYS[1], ARGV[2])
+end
+return v
+`)
+
+// Incr https://redis.io/commands/incrby
+// timeout in RFC3339, set only if the key has no TTL
+func (d *driver) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("redis_driver_incr")
+	return d.incr(op, key, delta, timeout)
+}
+
+// Decr https://redis.io/commands/incrby with the negative delta
+func (d *driver) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("redis_driver_decr")
+	return d.incr(op, key, -delta, timeout)
+}
+
+func (d *driver) incr(op errors.Op, key string, delta int64, timeout string) (int64, error) {
+	if strings.TrimSpace(key) == "" {
+		return 0, errors.E(op, errors.EmptyKey)
+	}
+
+	var at string
+	if timeout != "" {
+		t, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return 0, errors.E(op, err)
+		}
+		at = strconv.FormatInt(t.Unix(), 10)
+	}
+
+	script, args := incrScript, []interface{}{delta, at}
+	if !d.cluster() {
+		script, args = incrTagsScript, append(args, ttlMs(timeout), tagPrefix, keyTagsPrefix)
+	}
+
+	v, err := script.Run(context.Background(), d.universalClient, []string{key}, args...).Int64()
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+// SetNX https://redis.io/commands/setnx
+func (d *driver) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("redis_driver_setnx")
+	if item == nil {
+		return false, errors.E(op, errors.EmptyItem)
+	}
+
+	if strings.TrimSpace(item.Key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	var exp time.Duration
+	if item.Timeout != "" {
+		t, err := time.Parse(time.RFC3339, item.Timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+		exp = time.Until(t)
+	}
+
+	ok, err := d.universalClient.SetNX(context.Background(), item.Key, item.Value, exp).Result()
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	if d.cluster() {
+		if len(item.Tags) > 0 {
+			return true, errors.E(op, errors.Str("tags are not supported in the redis cluster mode"))
+		}
+		return true, nil
+	}
+
+	err = d.tag(item)
+	if err != nil {
+		return true, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+// casScript replaces the value if the current one is equal to ARGV[1] and sets the expiration time (unix seconds)
+var casScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+if ARGV[3] ~= '' then
+	redis.call('EXPIREAT', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// CompareAndSwap replaces the value with the Lua script
+func (d *driver) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("redis_driver_compare_and_swap")
+	if strings.TrimSpace(key) == "" {
+		return false, errors.E(op, errors.EmptyKey)
+	}
+
+	var at string
+	if timeout != "" {
+		t, err := time.Parse(time.RFC3339, timeout)
+		if err != nil {
+			return false, errors.E(op, err)
+		}
+		at = strconv.FormatInt(t.Unix(), 10)
+	}
+
+	script, args := casScript, []interface{}{oldValue, newValue, at}
+	if !d.cluster() {
+		script, args = casTagsScript, append(args, ttlMs(timeout), tagPrefix, keyTagsPrefix)
+	}
+
+	v, err := script.Run(context.Background(), d.universalClient, []string{key}, args...).Int()
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return v == 1, nil
+}
+
+// globEscaper escapes the MATCH pattern special characters in the prefix
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// Keys https://redis.io/commands/scan
+// cursor is the SCAN cursor, the page might contain less or more keys than the limit, and the keys might be duplicated
+// between the pages. Not supported in the cluster mode.
+func (d *driver) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	const op = errors.Op("redis_driver_keys")
+	if limit <= 0 {
+		return nil, "", errors.E(op, errors.Str("limit should be positive"))
+	}
+
+	if d.cluster() {
+		return nil, "", errors.E(op, errors.Str("keys listing is not supported in the redis cluster mode"))
+	}
+
+	var c uint64
+	if cursor != "" {
+		var err error
+		c, err = strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, "", errors.E(op, errors.Errorf("invalid cursor: %s", cursor))
+		}
+	}
+
+	keys, next, err := d.universalClient.Scan(context.Background(), c, globEscaper.Replace(prefix)+"*", int64(limit)).Result()
+	if err != nil {
+		return nil, "", errors.E(op, err)
+	}
+
+	// tags index is not the part of the storage keys
+	n := 0
+	for _, k := range keys {
+		if !tagsIndex(k) {
+			keys[n] = k
+			n++
+		}
+	}
+	keys = keys[:n]
+
+	if next == 0 {
+		return keys, "", nil
+	}
+
+	return keys, strconv.FormatUint(next, 10), nil
+}
+
+// Notify subscribes to the keyspace notifications https://redis.io/topics/notifications, notifications should be
+// enabled in the redis configuration (notify-keyspace-events with the K flag and the events). Not supported in the
+// cluster mode.
+func (d *driver) Notify(fn func(key, op string)) (bool, error) {
+	const op = errors.Op("redis_driver_notify")
+	if d.cluster() {
+		return false, nil
+	}
+
+	res, err := d.universalClient.ConfigGet(context.Background(), "notify-keyspace-events").Result()
+	if err != nil {
+		// CONFIG command might be disabled
+		d.log.Warn("failed to check the keyspace notifications configuration", "error", err)
+		return false, nil
+	}
+
+	if len(res) != 2 {
+		return false, nil
+	}
+
+	flags, _ := res[1].(string)
+	if !strings.Contains(flags, "K") || !strings.ContainsAny(flags, "A$g") {
+		d.log.Debug("keyspace notifications are disabled", "notify-keyspace-events", flags)
+		return false, nil
+	}
+
+	prefix := fmt.Sprintf("__keyspace@%d__:", d.cfg.DB)
+	d.pubsub = d.universalClient.PSubscribe(context.Background(), prefix+"*")
+	// wait for the subscription confirmation
+	_, err = d.pubsub.Receive(context.Background())
+	if err != nil {
+		_ = d.pubsub.Close()
+		d.pubsub = nil
+		return false, errors.E(op, err)
+	}
+
+	ch := d.pubsub.Channel()
+	go func() {
+		for msg := range ch {
+			key := strings.TrimPrefix(msg.Channel, prefix)
+			if tagsIndex(key) {
+				continue
+			}
+
+			fn(key, keyspaceOp(msg.Payload))
+		}
+	}()
+
+	return true, nil
+}
+
+// keyspaceOp converts the keyspace event into the kv change operation
+func keyspaceOp(event string) string {
+	switch event {
+	case "del", "expired", "evicted":
+		return kv.OpDelete
+	case "expire", "persist":
+		return kv.OpExpire
+	default:
+		return kv.OpSet
+	}
+}
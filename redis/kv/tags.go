@@ -0,0 +1,240 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/spiral/errors"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+)
+
+const (
+	// tagPrefix is the prefix of the tags index sets
+	tagPrefix string = "rr_tags:"
+	// keyTagsPrefix is the prefix of the sets with the tags of every tagged key, the index is updated on overwrite and delete
+	keyTagsPrefix string = "rr_key_tags:"
+)
+
+// tagsLua contains the functions shared by the tags scripts. untag removes the key from all its tags, expire sets
+// the TTL (milliseconds, empty - no TTL) of the key tags set and extends the TTL of the tag sets.
+// The tags index keys are not declared in KEYS, so the scripts are not supported in the cluster mode.
+const tagsLua string = `
+local function untag(key, tp, ktp)
+	local kt = ktp .. key
+	for _, t in ipairs(redis.call('SMEMBERS', kt)) do
+		redis.call('SREM', tp .. t, key)
+	end
+	redis.call('DEL', kt)
+end
+
+local function expire(key, tp, ktp, ms)
+	local kt = ktp .. key
+	for _, t in ipairs(redis.call('SMEMBERS', kt)) do
+		local ts = tp .. t
+		if ms == '' then
+			redis.call('PERSIST', ts)
+		else
+			local ttl = redis.call('PTTL', ts)
+			if ttl >= 0 and ttl < tonumber(ms) then
+				redis.call('PEXPIRE', ts, ms)
+			end
+		end
+	end
+	if ms == '' then
+		redis.call('PERSIST', kt)
+	else
+		redis.call('PEXPIRE', kt, ms)
+	end
+end
+`
+
+// tagScript replaces the key tags. The tag set lives as long as the longest living key, so the sets of the expired keys
+// are removed by redis.
+// KEYS[1] - key, ARGV[1] - TTL in milliseconds (empty - no TTL), ARGV[2] - tag prefix, ARGV[3] - key tags prefix,
+// ARGV[4...] - tags
+var tagScript = redis.NewScript(tagsLua + `
+untag(KEYS[1], ARGV[2], ARGV[3])
+for i = 4, #ARGV do
+	local ts = ARGV[2] .. ARGV[i]
+	local ttl = redis.call('PTTL', ts)
+	redis.call('SADD', ts, KEYS[1])
+	redis.call('SADD', ARGV[3] .. KEYS[1], ARGV[i])
+	if ARGV[1] == '' then
+		redis.call('PERSIST', ts)
+	elseif ttl == -2 or (ttl >= 0 and ttl < tonumber(ARGV[1])) then
+		redis.call('PEXPIRE', ts, ARGV[1])
+	end
+end
+if #ARGV >= 4 and ARGV[1] ~= '' then
+	redis.call('PEXPIRE', ARGV[3] .. KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// deleteScript deletes the keys and removes them from the tags index
+// KEYS - keys, ARGV[1] - tag prefix, ARGV[2] - key tags prefix
+var deleteScript = redis.NewScript(tagsLua + `
+for _, key in ipairs(KEYS) do
+	untag(key, ARGV[1], ARGV[2])
+	redis.call('DEL', key)
+end
+return #KEYS
+`)
+
+// expireScript sets the key TTL and updates the TTL of its tags
+// KEYS[1] - key, ARGV[1] - TTL in milliseconds, ARGV[2] - tag prefix, ARGV[3] - key tags prefix
+var expireScript = redis.NewScript(tagsLua + `
+if redis.call('PEXPIRE', KEYS[1], ARGV[1]) == 1 then
+	expire(KEYS[1], ARGV[2], ARGV[3], ARGV[1])
+end
+return 1
+`)
+
+// incrTagsScript is the incrScript which updates the TTL of the key tags
+// ARGV[3] - TTL in milliseconds, ARGV[4] - tag prefix, ARGV[5] - key tags prefix
+var incrTagsScript = redis.NewScript(tagsLua + `
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if ARGV[2] ~= '' and redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIREAT', KEYS[1], ARGV[2])
+	expire(KEYS[1], ARGV[4], ARGV[5], ARGV[3])
+end
+return v
+`)
+
+// casTagsScript is the casScript which updates the TTL of the key tags
+// ARGV[4] - TTL in milliseconds (empty - no TTL), ARGV[5] - tag prefix, ARGV[6] - key tags prefix
+var casTagsScript = redis.NewScript(tagsLua + `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+if ARGV[3] ~= '' then
+	redis.call('EXPIREAT', KEYS[1], ARGV[3])
+end
+expire(KEYS[1], ARGV[5], ARGV[6], ARGV[4])
+return 1
+`)
+
+// invalidateScript deletes the keys of the tag set and the set itself. Members of the expired keys are skipped, the key
+// is deleted only if it's still tagged.
+// KEYS[1] - tag set, ARGV[1] - tag, ARGV[2] - tag prefix, ARGV[3] - key tags prefix
+var invalidateScript = redis.NewScript(tagsLua + `
+local n = 0
+for _, key in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	if redis.call('SISMEMBER', ARGV[3] .. key, ARGV[1]) == 1 then
+		untag(key, ARGV[2], ARGV[3])
+		redis.call('DEL', key)
+		n = n + 1
+	end
+end
+redis.call('DEL', KEYS[1])
+return n
+`)
+
+// InvalidateTags deletes the tagged keys, every tag is invalidated atomically. Not supported in the cluster mode.
+func (d *driver) InvalidateTags(tags ...string) error {
+	const op = errors.Op("redis_driver_invalidate_tags")
+	if tags == nil {
+		return errors.E(op, errors.Str("no tags provided"))
+	}
+
+	if d.cluster() {
+		return errors.E(op, errors.Str("tags are not supported in the redis cluster mode"))
+	}
+
+	for _, tag := range tags {
+		err := invalidateScript.Run(context.Background(), d.universalClient, []string{tagPrefix + tag}, tag, tagPrefix, keyTagsPrefix).Err()
+		if err != nil && err != redis.Nil {
+			return errors.E(op, err)
+		}
+	}
+
+	return nil
+}
+
+// Tags returns the tags of the tagged keys. Not supported in the cluster mode.
+func (d *driver) Tags(keys ...string) (map[string][]string, error) {
+	const op = errors.Op("redis_driver_tags")
+	if keys == nil {
+		return nil, errors.E(op, errors.NoKeys)
+	}
+
+	if d.cluster() {
+		return nil, errors.E(op, errors.Str("tags are not supported in the redis cluster mode"))
+	}
+
+	pipe := d.universalClient.Pipeline()
+	cmds := make([]*redis.StringSliceCmd, len(keys))
+	for i := range keys {
+		cmds[i] = pipe.SMembers(context.Background(), keyTagsPrefix+keys[i])
+	}
+
+	_, err := pipe.Exec(context.Background())
+	if err != nil && err != redis.Nil {
+		return nil, errors.E(op, err)
+	}
+
+	m := make(map[string][]string, len(keys))
+	for i := range cmds {
+		tags, errC := cmds[i].Result()
+		if errC != nil && errC != redis.Nil {
+			return nil, errors.E(op, errC)
+		}
+
+		if len(tags) > 0 {
+			m[keys[i]] = tags
+		}
+	}
+
+	return m, nil
+}
+
+// tag replaces the item key tags in the tags index
+func (d *driver) tag(item *kvv1.Item) error {
+	args := make([]interface{}, 0, len(item.Tags)+3)
+	args = append(args, ttlMs(item.Timeout), tagPrefix, keyTagsPrefix)
+	for _, t := range item.Tags {
+		args = append(args, t)
+	}
+
+	err := tagScript.Run(context.Background(), d.universalClient, []string{item.Key}, args...).Err()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	return nil
+}
+
+// cluster reports whether the client is connected to the redis cluster
+func (d *driver) cluster() bool {
+	_, ok := d.universalClient.(*redis.ClusterClient)
+	return ok
+}
+
+// tagsIndex reports whether the key belongs to the tags index
+func tagsIndex(key string) bool {
+	return strings.HasPrefix(key, tagPrefix) || strings.HasPrefix(key, keyTagsPrefix)
+}
+
+// ttlMs converts the RFC 3339 timeout into the TTL in milliseconds for the tags scripts, empty - no TTL.
+// Expired timeout is converted into the minimal TTL.
+func ttlMs(timeout string) string {
+	if timeout == "" {
+		return ""
+	}
+
+	t, err := time.Parse(time.RFC3339, timeout)
+	if err != nil {
+		return ""
+	}
+
+	ms := time.Until(t).Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+
+	return strconv.FormatInt(ms, 10)
+}
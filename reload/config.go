@@ -33,6 +33,9 @@ type ServiceConfig struct {
 
 	// Ignore is set of files which would not be watched
 	Ignore []string
+
+	// ReloadConfig reloads the service configuration instead of the workers reset, service should implement the ConfigReloader
+	ReloadConfig bool `mapstructure:"reload_config"`
 }
 
 // InitDefaults sets missing values to their default values.
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	endure "github.com/spiral/endure/pkg/container"
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/config"
 	"github.com/spiral/roadrunner-plugins/v2/logger"
@@ -15,13 +16,19 @@ import (
 const PluginName string = "reload"
 const thresholdChanBuffer uint = 1000
 
+// ConfigReloader is implemented by the plugins which are able to apply the configuration changes without restart
+type ConfigReloader interface {
+	ReloadConfig() error
+}
+
 type Plugin struct {
-	cfg      *Config
-	log      logger.Logger
-	watcher  *Watcher
-	services map[string]interface{}
-	res      *resetter.Plugin
-	stopc    chan struct{}
+	cfg       *Config
+	log       logger.Logger
+	watcher   *Watcher
+	services  map[string]interface{}
+	res       *resetter.Plugin
+	reloaders map[string]ConfigReloader
+	stopc     chan struct{}
 }
 
 // Init controller service
@@ -43,6 +50,7 @@ func (s *Plugin) Init(cfg config.Configurer, log logger.Logger, res *resetter.Pl
 	s.res = res
 	s.stopc = make(chan struct{}, 1)
 	s.services = make(map[string]interface{})
+	s.reloaders = make(map[string]ConfigReloader)
 
 	configs := make([]WatcherConfig, 0, len(s.cfg.Services))
 
@@ -123,7 +131,12 @@ func (s *Plugin) Serve() chan error {
 				timer.Reset(s.cfg.Interval)
 			case <-timer.C:
 				if len(updated) > 0 {
-					for name := range updated {
+					for name, svc := range updated {
+						if svc.ReloadConfig {
+							s.reloadConfig(name)
+							continue
+						}
+
 						err := s.res.Reset(name)
 						if err != nil {
 							timer.Stop()
@@ -158,6 +171,35 @@ func (s *Plugin) Stop() error {
 	return nil
 }
 
+// Collects declares services to be collected.
+func (s *Plugin) Collects() []interface{} {
+	return []interface{}{
+		s.CollectConfigReloader,
+	}
+}
+
+// CollectConfigReloader collects plugins which are able to reload the configuration
+func (s *Plugin) CollectConfigReloader(name endure.Named, r ConfigReloader) {
+	s.reloaders[name.Name()] = r
+}
+
+// reloadConfig applies the configuration changes, failed reload is not fatal, previous configuration stays in use
+func (s *Plugin) reloadConfig(name string) {
+	r, ok := s.reloaders[name]
+	if !ok {
+		s.log.Error("service does not support the configuration reload", "service", name)
+		return
+	}
+
+	err := r.ReloadConfig()
+	if err != nil {
+		s.log.Error("configuration reload failed", "service", name, "error", err)
+		return
+	}
+
+	s.log.Info("configuration reloaded", "service", name)
+}
+
 func (s *Plugin) Name() string {
 	return PluginName
 }
@@ -0,0 +1,61 @@
+package sharded
+
+import (
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/sharded/shardedjobs"
+	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
+)
+
+const PluginName string = "sharded"
+
+// Plugin is a virtual jobs driver, which routes jobs over the shards (child pipelines of any driver)
+// by the consistent hash of the header or the job ID.
+type Plugin struct {
+	log logger.Logger
+	cfg config.Configurer
+	// drivers for the shards
+	constructors map[string]jobs.Constructor
+}
+
+func (p *Plugin) Init(log logger.Logger, cfg config.Configurer) error {
+	p.log = log
+	p.cfg = cfg
+	p.constructors = make(map[string]jobs.Constructor)
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+func (p *Plugin) Collects() []interface{} {
+	return []interface{}{
+		p.CollectConstructors,
+	}
+}
+
+// CollectConstructors collects jobs drivers used for the shards
+func (p *Plugin) CollectConstructors(name endure.Named, c jobs.Constructor) {
+	// nested sharding is not supported
+	if name.Name() == PluginName {
+		return
+	}
+
+	p.constructors[name.Name()] = c
+}
+
+// ConsumerFromConfig creates new sharded consumer from the configuration
+func (p *Plugin) ConsumerFromConfig(configKey string, pq priorityqueue.Queue) (jobs.Consumer, error) {
+	return shardedjobs.FromConfig(configKey, p.constructors, p.log, p.cfg, pq)
+}
+
+// ConsumerFromPipeline creates new sharded consumer from the provided pipeline
+func (p *Plugin) ConsumerFromPipeline(pipe *pipeline.Pipeline, pq priorityqueue.Queue) (jobs.Consumer, error) {
+	return shardedjobs.FromPipeline(pipe, p.constructors, p.log, pq)
+}
@@ -0,0 +1,28 @@
+package shardedjobs
+
+const (
+	key      string = "key"
+	replicas string = "replicas"
+	shards   string = "shards"
+
+	defaultReplicas int = 128
+)
+
+// Config is the sharded pipeline configuration
+type Config struct {
+	// Key is the header used for the routing, jobs with the same key are pushed into the same shard.
+	// Job ID is used if the key is empty or the job has no such header.
+	Key string `mapstructure:"key"`
+
+	// Replicas is the number of the virtual nodes per shard on the hash ring, default: 128
+	Replicas int `mapstructure:"replicas"`
+
+	// Shards is the set of the child pipelines configurations (key - shard name), every shard should have a driver
+	Shards map[string]map[string]interface{} `mapstructure:"shards"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.Replicas <= 0 {
+		c.Replicas = defaultReplicas
+	}
+}
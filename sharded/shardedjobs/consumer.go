@@ -0,0 +1,416 @@
+package shardedjobs
+
+import (
+	"context"
+	stderr "errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
+)
+
+const (
+	pluginName  string = "sharded"
+	driver      string = "driver"
+	name        string = "name"
+	persistPath string = "persist_path"
+)
+
+// shard is a child pipeline
+type shard struct {
+	name     string
+	consumer jobs.Consumer
+	// child pipeline options, the pipeline name is <sharded pipeline>.<shard>
+	options map[string]interface{}
+	pipe    *pipeline.Pipeline
+}
+
+type consumer struct {
+	log logger.Logger
+	key string
+
+	ring *ring
+	// sorted by name
+	shards []*shard
+	byName map[string]*shard
+
+	pipeline atomic.Value
+}
+
+// FromConfig creates the sharded consumer, shards are initialized from the configKey.shards.<name> sections
+func FromConfig(configKey string, constructors map[string]jobs.Constructor, log logger.Logger, cfg config.Configurer, pq priorityqueue.Queue) (*consumer, error) {
+	const op = errors.Op("sharded_consumer_from_config")
+
+	var conf *Config
+	err := cfg.UnmarshalKey(configKey, &conf)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if conf == nil {
+		return nil, errors.E(op, errors.Errorf("config not found by provided key: %s", configKey))
+	}
+
+	conf.InitDefaults()
+
+	c, err := newConsumer(conf, log)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	for _, s := range c.shards {
+		constructor, errC := shardConstructor(constructors, s)
+		if errC != nil {
+			return nil, errors.E(op, errC)
+		}
+
+		s.consumer, err = constructor.ConsumerFromConfig(configKey+"."+shards+"."+s.name, pq)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return c, nil
+}
+
+// FromPipeline creates the sharded consumer from the declared pipeline, shards option is a JSON object:
+// {"shard-1": {"driver": "memory", "prefetch": "100"}, "shard-2": {...}}
+func FromPipeline(pipe *pipeline.Pipeline, constructors map[string]jobs.Constructor, log logger.Logger, pq priorityqueue.Queue) (*consumer, error) {
+	const op = errors.Op("sharded_consumer_from_pipeline")
+
+	conf := &Config{
+		Key:      pipe.String(key, ""),
+		Replicas: pipe.Int(replicas, defaultReplicas),
+	}
+
+	err := json.Unmarshal([]byte(pipe.String(shards, "{}")), &conf.Shards)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	conf.InitDefaults()
+
+	c, err := newConsumer(conf, log)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	for _, s := range c.shards {
+		constructor, errC := shardConstructor(constructors, s)
+		if errC != nil {
+			return nil, errors.E(op, errC)
+		}
+
+		childPipe := s.pipeline(pipe.Name())
+		s.consumer, err = constructor.ConsumerFromPipeline(childPipe, pq)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	return c, nil
+}
+
+func newConsumer(conf *Config, log logger.Logger) (*consumer, error) {
+	if len(conf.Shards) == 0 {
+		return nil, errors.Str("sharded pipeline should have at least one shard")
+	}
+
+	c := &consumer{
+		log:    log,
+		key:    conf.Key,
+		shards: make([]*shard, 0, len(conf.Shards)),
+		byName: make(map[string]*shard, len(conf.Shards)),
+	}
+
+	// the journal can't be shared between the shards
+	paths := make(map[string]string, len(conf.Shards))
+	names := make([]string, 0, len(conf.Shards))
+	for n, opts := range conf.Shards {
+		if pp, _ := opts[persistPath].(string); pp != "" {
+			if other, ok := paths[pp]; ok {
+				return nil, errors.Errorf("shards %s and %s have the same %s: %s", other, n, persistPath, pp)
+			}
+			paths[pp] = n
+		}
+
+		s := &shard{name: n, options: opts}
+		c.shards = append(c.shards, s)
+		c.byName[n] = s
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	sort.Slice(c.shards, func(i, j int) bool {
+		return c.shards[i].name < c.shards[j].name
+	})
+
+	c.ring = newRing(names, conf.Replicas)
+
+	return c, nil
+}
+
+func shardConstructor(constructors map[string]jobs.Constructor, s *shard) (jobs.Constructor, error) {
+	dr, _ := s.options[driver].(string)
+	if dr == "" {
+		return nil, errors.Errorf("no driver for the shard: %s", s.name)
+	}
+
+	if dr == pluginName {
+		return nil, errors.Errorf("nested sharded pipelines are not supported, shard: %s", s.name)
+	}
+
+	constructor, ok := constructors[dr]
+	if !ok {
+		return nil, errors.Errorf("no such driver: %s, shard: %s", dr, s.name)
+	}
+
+	return constructor, nil
+}
+
+// pipeline returns the child pipeline named <sharded pipeline>.<shard>, so the drivers' storages keyed by the pipeline
+// name (e.g. boltdb buckets) don't collide between the shards
+func (s *shard) pipeline(pipeName string) *pipeline.Pipeline {
+	if s.pipe != nil {
+		return s.pipe
+	}
+
+	p := make(pipeline.Pipeline, len(s.options)+1)
+	for k, v := range s.options {
+		p[k] = v
+	}
+
+	p.With(name, pipeName+"."+s.name)
+	s.pipe = &p
+
+	return s.pipe
+}
+
+// job returns the copy of the job pushed into the shard pipeline
+func (s *shard) job(jb *job.Job) *job.Job {
+	j := *jb
+	if jb.Options != nil {
+		opts := *jb.Options
+		j.Options = &opts
+	} else {
+		j.Options = &job.Options{}
+	}
+
+	j.Options.Pipeline = s.pipe.Name()
+	return &j
+}
+
+// route returns the shard for the job
+func (c *consumer) route(jb *job.Job) *shard {
+	k := jb.Ident
+	if c.key != "" {
+		if v := jb.Headers[c.key]; len(v) > 0 && v[0] != "" {
+			k = v[0]
+		}
+	}
+
+	return c.byName[c.ring.get(k)]
+}
+
+func (c *consumer) Push(ctx context.Context, jb *job.Job) error {
+	const op = errors.Op("sharded_push")
+	_, ok := c.pipeline.Load().(*pipeline.Pipeline)
+	if !ok {
+		return errors.E(op, errors.Errorf("no such pipeline: %s", jb.Options.Pipeline))
+	}
+
+	s := c.route(jb)
+	err := s.consumer.Push(ctx, s.job(jb))
+	if err != nil {
+		return errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+	}
+
+	return nil
+}
+
+// PushBatch splits jobs by the shards keeping the order of the jobs within the shard. Shards are pushed
+// independently, so *jobs.BatchError is returned if only part of the jobs were pushed.
+func (c *consumer) PushBatch(ctx context.Context, jbs []*job.Job) error {
+	const op = errors.Op("sharded_push_batch")
+	_, ok := c.pipeline.Load().(*pipeline.Pipeline)
+	if !ok {
+		return errors.E(op, errors.Str("pipeline is not registered"))
+	}
+
+	// indexes of the jobs in the batch by the shard
+	batches := make(map[*shard][]int, len(c.shards))
+	for i := 0; i < len(jbs); i++ {
+		s := c.route(jbs[i])
+		batches[s] = append(batches[s], i)
+	}
+
+	failed := make(map[int]error)
+	for _, s := range c.shards {
+		idx, ok := batches[s]
+		if !ok {
+			continue
+		}
+
+		batch := make([]*job.Job, len(idx))
+		for i := 0; i < len(idx); i++ {
+			batch[i] = s.job(jbs[idx[i]])
+		}
+
+		if bp, ok := s.consumer.(jobs.BatchPusher); ok {
+			err := bp.PushBatch(ctx, batch)
+			if err == nil {
+				continue
+			}
+
+			var be *jobs.BatchError
+			if stderr.As(err, &be) && be.Valid(len(idx)) {
+				for i, e := range be.Errors {
+					failed[idx[i]] = errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, e))
+				}
+				continue
+			}
+
+			// nothing was pushed, or the shard reported the wrong jobs indexes and the pushed jobs are unknown
+			for i := 0; i < len(idx); i++ {
+				failed[idx[i]] = errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+			}
+			continue
+		}
+
+		for i := 0; i < len(batch); i++ {
+			err := s.consumer.Push(ctx, batch[i])
+			if err != nil {
+				failed[idx[i]] = errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+			}
+		}
+	}
+
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(failed) == len(jbs):
+		// nothing was pushed
+		return failed[0]
+	default:
+		return &jobs.BatchError{Errors: failed}
+	}
+}
+
+func (c *consumer) Register(ctx context.Context, p *pipeline.Pipeline) error {
+	const op = errors.Op("sharded_register")
+	for _, s := range c.shards {
+		err := s.consumer.Register(ctx, s.pipeline(p.Name()))
+		if err != nil {
+			return errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+		}
+	}
+
+	c.pipeline.Store(p)
+	return nil
+}
+
+// Children returns the shards' pipelines, registered by the Register
+func (c *consumer) Children() []*pipeline.Pipeline {
+	out := make([]*pipeline.Pipeline, 0, len(c.shards))
+	for _, s := range c.shards {
+		if s.pipe != nil {
+			out = append(out, s.pipe)
+		}
+	}
+
+	return out
+}
+
+// Run consumes from all shards
+func (c *consumer) Run(ctx context.Context, p *pipeline.Pipeline) error {
+	const op = errors.Op("sharded_run")
+	for _, s := range c.shards {
+		err := s.consumer.Run(ctx, s.pipeline(p.Name()))
+		if err != nil {
+			return errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+		}
+	}
+
+	c.log.Debug("sharded pipeline is running", "pipeline", p.Name(), "shards", len(c.shards))
+	return nil
+}
+
+func (c *consumer) Pause(ctx context.Context, p string) {
+	for _, s := range c.shards {
+		s.consumer.Pause(ctx, s.pipeline(p).Name())
+	}
+}
+
+func (c *consumer) Resume(ctx context.Context, p string) {
+	for _, s := range c.shards {
+		s.consumer.Resume(ctx, s.pipeline(p).Name())
+	}
+}
+
+func (c *consumer) Stop(ctx context.Context) error {
+	const op = errors.Op("sharded_stop")
+	var errs []string
+	for _, s := range c.shards {
+		err := s.consumer.Stop(ctx)
+		if err != nil {
+			errs = append(errs, s.name+": "+err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.E(op, errors.Str(strings.Join(errs, "; ")))
+	}
+
+	return nil
+}
+
+// State aggregates the shards' state, per-shard counters are in the Extra (<shard>.active, <shard>.delayed, ...)
+func (c *consumer) State(ctx context.Context) (*jobs.State, error) {
+	const op = errors.Op("sharded_state")
+	pipe, ok := c.pipeline.Load().(*pipeline.Pipeline)
+	if !ok {
+		return nil, errors.E(op, errors.Str("pipeline is not registered"))
+	}
+
+	st := &jobs.State{
+		Pipeline: pipe.Name(),
+		Driver:   pipe.Driver(),
+		Ready:    true,
+		Extra:    make(map[string]int64, len(c.shards)*4),
+	}
+
+	queues := make([]string, 0, len(c.shards))
+	for _, s := range c.shards {
+		sst, err := s.consumer.State(ctx)
+		if err != nil {
+			return nil, errors.E(op, errors.Errorf("shard: %s, error: %v", s.name, err))
+		}
+
+		st.Active += sst.Active
+		st.Delayed += sst.Delayed
+		st.Reserved += sst.Reserved
+		st.Buried += sst.Buried
+		// ready only if all shards are consumed
+		st.Ready = st.Ready && sst.Ready
+
+		st.Extra[s.name+".active"] = sst.Active
+		st.Extra[s.name+".delayed"] = sst.Delayed
+		st.Extra[s.name+".reserved"] = sst.Reserved
+		st.Extra[s.name+".buried"] = sst.Buried
+
+		if sst.Queue != "" {
+			queues = append(queues, sst.Queue)
+		}
+	}
+
+	st.Queue = strings.Join(queues, ",")
+	return st, nil
+}
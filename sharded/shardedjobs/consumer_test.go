@@ -0,0 +1,151 @@
+package shardedjobs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/jobstest"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/pipeline"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	priorityqueue "github.com/spiral/roadrunner/v2/priority_queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeConstructor struct {
+	consumers []*jobstest.Consumer
+}
+
+func (f *fakeConstructor) ConsumerFromConfig(_ string, _ priorityqueue.Queue) (jobs.Consumer, error) {
+	c := &jobstest.Consumer{}
+	f.consumers = append(f.consumers, c)
+	return c, nil
+}
+
+func (f *fakeConstructor) ConsumerFromPipeline(_ *pipeline.Pipeline, _ priorityqueue.Queue) (jobs.Consumer, error) {
+	return f.ConsumerFromConfig("", nil)
+}
+
+func newTestConsumer(t *testing.T) (*consumer, *fakeConstructor) {
+	fc := &fakeConstructor{}
+	pipe := &pipeline.Pipeline{
+		"name":   "orders",
+		"driver": "sharded",
+		"key":    "order_id",
+		"shards": `{"s1":{"driver":"fake","queue":"q1"},"s2":{"driver":"fake","queue":"q2"},"s3":{"driver":"fake","queue":"q3"}}`,
+	}
+
+	c, err := FromPipeline(pipe, map[string]jobs.Constructor{"fake": fc}, logger.NewZapAdapter(zap.NewNop()), nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Register(context.Background(), pipe))
+	require.NoError(t, c.Run(context.Background(), pipe))
+
+	return c, fc
+}
+
+func TestShardedRouting(t *testing.T) {
+	c, fc := newTestConsumer(t)
+	require.Len(t, fc.consumers, 3)
+
+	// every shard has its own pipeline name
+	for _, s := range c.shards {
+		assert.Equal(t, "orders."+s.name, s.pipe.Name())
+	}
+
+	// child pipelines are exposed to the jobs plugin
+	children := c.Children()
+	require.Len(t, children, 3)
+	for i, s := range c.shards {
+		assert.Same(t, s.pipe, children[i])
+	}
+
+	batch := make([]*job.Job, 0, 100)
+	for i := 0; i < 100; i++ {
+		batch = append(batch, &job.Job{
+			Ident:   strconv.Itoa(i),
+			Headers: map[string][]string{"order_id": {strconv.Itoa(i % 10)}},
+			Options: &job.Options{Pipeline: "orders"},
+		})
+	}
+
+	require.NoError(t, c.PushBatch(context.Background(), batch))
+	require.NoError(t, c.Push(context.Background(), &job.Job{Ident: "no-key", Options: &job.Options{Pipeline: "orders"}}))
+
+	owner := make(map[string]*jobstest.Consumer)
+	total := 0
+	for _, fcons := range fc.consumers {
+		last := -1
+		for _, j := range fcons.Pushed() {
+			total++
+			if len(j.Headers["order_id"]) == 0 {
+				continue
+			}
+
+			// jobs are pushed into the shard pipeline
+			assert.Equal(t, fcons.Pipeline().Name(), j.Options.Pipeline)
+
+			// all jobs with the same key are in the same shard
+			k := j.Headers["order_id"][0]
+			if o, ok := owner[k]; ok {
+				assert.Same(t, o, fcons)
+			}
+			owner[k] = fcons
+
+			// order is preserved within the shard
+			id, _ := strconv.Atoi(j.Ident)
+			assert.Greater(t, id, last)
+			last = id
+		}
+	}
+
+	assert.Equal(t, 101, total)
+	assert.Len(t, owner, 10)
+
+	st, err := c.State(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), st.Active)
+	assert.True(t, st.Ready)
+	assert.Equal(t, "q1,q2,q3", st.Queue)
+	assert.Equal(t, "orders", st.Pipeline)
+
+	c.Pause(context.Background(), "orders")
+	st, err = c.State(context.Background())
+	require.NoError(t, err)
+	assert.False(t, st.Ready)
+}
+
+func TestShardedPersistPath(t *testing.T) {
+	pipe := &pipeline.Pipeline{
+		"name":   "orders",
+		"driver": "sharded",
+		"shards": `{"s1":{"driver":"fake","persist_path":"orders.wal"},"s2":{"driver":"fake","persist_path":"orders.wal"}}`,
+	}
+
+	_, err := FromPipeline(pipe, map[string]jobs.Constructor{"fake": &fakeConstructor{}}, logger.NewZapAdapter(zap.NewNop()), nil)
+	assert.Error(t, err)
+}
+
+func TestRingStability(t *testing.T) {
+	r3 := newRing([]string{"s1", "s2", "s3"}, defaultReplicas)
+	r2 := newRing([]string{"s1", "s2"}, defaultReplicas)
+
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		k := strconv.Itoa(i)
+		owner := r3.get(k)
+		counts[owner]++
+
+		// only the keys of the removed shard are moved
+		if owner != "s3" {
+			assert.Equal(t, owner, r2.get(k))
+		}
+	}
+
+	for _, n := range counts {
+		assert.InDelta(t, 1000, n, 300)
+	}
+}
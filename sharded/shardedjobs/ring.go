@@ -0,0 +1,54 @@
+package shardedjobs
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// ring is the consistent hash ring, adding or removing a shard moves only the keys of that shard
+type ring struct {
+	hashes []uint32
+	// hash -> shard name
+	owners map[uint32]string
+}
+
+func newRing(names []string, replicas int) *ring {
+	r := &ring{
+		hashes: make([]uint32, 0, len(names)*replicas),
+		owners: make(map[uint32]string, len(names)*replicas),
+	}
+
+	for _, name := range names {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(name + "#" + strconv.Itoa(i)))
+			// collisions are resolved in favor of the first shard
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+
+			r.owners[h] = name
+			r.hashes = append(r.hashes, h)
+		}
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+
+	return r
+}
+
+// get returns the shard for the key
+func (r *ring) get(key string) string {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+
+	if i == len(r.hashes) {
+		i = 0
+	}
+
+	return r.owners[r.hashes[i]]
+}
@@ -0,0 +1,164 @@
+package sqsjobs
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// maxBatchSize is the maximum number of entries in the SQS batch requests
+const maxBatchSize int = 10
+
+// background deletes acknowledged messages in batches and extends the visibility timeout of the in-flight messages
+func (c *consumer) background() {
+	var flushCh, heartbeatCh <-chan time.Time
+
+	if c.ackFlushInterval > 0 {
+		flush := time.NewTicker(c.ackFlushInterval)
+		defer flush.Stop()
+		flushCh = flush.C
+	}
+
+	if c.visibilityHeartbeat > 0 {
+		heartbeat := time.NewTicker(c.visibilityHeartbeat)
+		defer heartbeat.Stop()
+		heartbeatCh = heartbeat.C
+	}
+
+	batch := make([]*string, 0, maxBatchSize)
+
+	for {
+		select {
+		case handle := <-c.deleteCh:
+			batch = append(batch, handle)
+			if len(batch) == maxBatchSize {
+				c.flushDeletes(batch)
+				batch = batch[:0]
+			}
+		case <-flushCh:
+			if len(batch) > 0 {
+				c.flushDeletes(batch)
+				batch = batch[:0]
+			}
+		case <-heartbeatCh:
+			c.extendVisibility()
+		case <-c.stopCh:
+			// drain pending deletes
+			for {
+				select {
+				case handle := <-c.deleteCh:
+					batch = append(batch, handle)
+					if len(batch) == maxBatchSize {
+						c.flushDeletes(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						c.flushDeletes(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// deleteMessage deletes the message from the queue immediately or puts it into the next delete batch
+func (c *consumer) deleteMessage(handle *string) error {
+	if c.visibilityHeartbeat > 0 {
+		c.inflight.Delete(*handle)
+	}
+
+	if c.ackFlushInterval > 0 {
+		c.stopMu.RLock()
+		// the background goroutine drains the batch on stop, delete synchronously after that
+		if atomic.LoadUint32(&c.stopped) == 0 {
+			c.deleteCh <- handle
+			c.stopMu.RUnlock()
+			return nil
+		}
+		c.stopMu.RUnlock()
+	}
+
+	_, err := c.client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
+		QueueUrl:      c.queueURL,
+		ReceiptHandle: handle,
+	})
+
+	return err
+}
+
+func (c *consumer) flushDeletes(handles []*string) {
+	entries := make([]types.DeleteMessageBatchRequestEntry, len(handles))
+	for i := 0; i < len(handles); i++ {
+		entries[i] = types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: handles[i],
+		}
+	}
+
+	out, err := c.client.DeleteMessageBatch(context.Background(), &sqs.DeleteMessageBatchInput{
+		QueueUrl: c.queueURL,
+		Entries:  entries,
+	})
+	if err != nil {
+		c.log.Error("delete message batch, messages will be redelivered after the visibility timeout", "error", err, "messages", len(handles))
+		return
+	}
+
+	for i := 0; i < len(out.Failed); i++ {
+		c.log.Error("delete message batch entry", "code", aws.ToString(out.Failed[i].Code), "message", aws.ToString(out.Failed[i].Message))
+	}
+}
+
+// extendVisibility changes the visibility timeout of the in-flight messages
+func (c *consumer) extendVisibility() {
+	// visibility timeout (in seconds) should be larger than the heartbeat interval
+	timeout := int32((c.visibilityHeartbeat*2 + time.Second - 1) / time.Second)
+	if c.visibilityTimeout > timeout {
+		timeout = c.visibilityTimeout
+	}
+
+	entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, 0, maxBatchSize)
+	c.inflight.Range(func(key, _ interface{}) bool {
+		entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(len(entries))),
+			ReceiptHandle:     aws.String(key.(string)),
+			VisibilityTimeout: timeout,
+		})
+
+		if len(entries) == maxBatchSize {
+			c.changeVisibility(entries)
+			entries = entries[:0]
+		}
+
+		return true
+	})
+
+	if len(entries) > 0 {
+		c.changeVisibility(entries)
+	}
+}
+
+func (c *consumer) changeVisibility(entries []types.ChangeMessageVisibilityBatchRequestEntry) {
+	out, err := c.client.ChangeMessageVisibilityBatch(context.Background(), &sqs.ChangeMessageVisibilityBatchInput{
+		QueueUrl: c.queueURL,
+		Entries:  entries,
+	})
+	if err != nil {
+		c.log.Error("change message visibility batch", "error", err)
+		return
+	}
+
+	for i := 0; i < len(out.Failed); i++ {
+		idx, _ := strconv.Atoi(aws.ToString(out.Failed[i].Id))
+		// receipt handle is not valid anymore, stop tracking it
+		c.inflight.Delete(aws.ToString(entries[idx].ReceiptHandle))
+		c.log.Warn("change message visibility", "code", aws.ToString(out.Failed[i].Code), "message", aws.ToString(out.Failed[i].Message))
+	}
+}
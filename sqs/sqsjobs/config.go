@@ -1,14 +1,25 @@
 package sqsjobs
 
-import "github.com/aws/aws-sdk-go-v2/aws"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
 
 const (
-	attributes string = "attributes"
-	tags       string = "tags"
-	queue      string = "queue"
-	pref       string = "prefetch"
-	visibility string = "visibility_timeout"
-	waitTime   string = "wait_time"
+	attributes          string = "attributes"
+	tags                string = "tags"
+	queue               string = "queue"
+	pref                string = "prefetch"
+	visibility          string = "visibility_timeout"
+	waitTime            string = "wait_time"
+	ackFlushInterval    string = "ack_flush_interval"
+	visibilityHeartbeat string = "visibility_heartbeat"
+
+	// fifoSuffix - FIFO queue name must end with the .fifo suffix
+	fifoSuffix string = ".fifo"
+	// fifoAttribute is mandatory for the FIFO queues
+	fifoAttribute string = "FifoQueue"
 )
 
 // Config is used to parse pipeline configuration
@@ -32,6 +43,12 @@ type Config struct {
 	// than this value (however, fewer messages might be returned). Valid values: 1 to
 	// 10. Default: 1.
 	Prefetch int32 `mapstructure:"prefetch"`
+	// AckFlushInterval enables batched DeleteMessage calls on Ack. Acknowledged messages are deleted every
+	// interval or when the batch is full (10 messages). Default: 0 - delete every message immediately.
+	AckFlushInterval time.Duration `mapstructure:"ack_flush_interval"`
+	// VisibilityHeartbeat is the interval to extend the visibility timeout of the received but not yet
+	// acknowledged messages, so long-running jobs are not redelivered. Default: 0 - disabled.
+	VisibilityHeartbeat time.Duration `mapstructure:"visibility_heartbeat"`
 	// The name of the new queue. The following limits apply to this name:
 	//
 	// * A queue
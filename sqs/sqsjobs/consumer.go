@@ -3,6 +3,7 @@ package sqsjobs
 import (
 	"context"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -45,6 +46,18 @@ type consumer struct {
 	prefetch          int32
 	visibilityTimeout int32
 
+	// FIFO queue, messages are sent with MessageGroupId and MessageDeduplicationId
+	fifo bool
+	// batched deletes and visibility heartbeats
+	ackFlushInterval    time.Duration
+	visibilityHeartbeat time.Duration
+	deleteCh            chan *string
+	// receipt handles of the messages received but not yet deleted
+	inflight sync.Map
+	// stopMu guards the deleteCh sends against the stop, the batch is not accepted after the background drained it
+	stopMu  sync.RWMutex
+	stopped uint32
+
 	// if user invoke several resume operations
 	listeners uint32
 
@@ -56,6 +69,7 @@ type consumer struct {
 	queueURL *string
 
 	pauseCh chan struct{}
+	stopCh  chan struct{}
 }
 
 func NewSQSConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Configurer, pq priorityqueue.Queue) (*consumer, error) {
@@ -104,6 +118,19 @@ func NewSQSConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Configure
 		secret:            conf.Secret,
 		endpoint:          conf.Endpoint,
 		pauseCh:           make(chan struct{}, 1),
+		stopCh:            make(chan struct{}, 1),
+
+		ackFlushInterval:    conf.AckFlushInterval,
+		visibilityHeartbeat: conf.VisibilityHeartbeat,
+		deleteCh:            make(chan *string, 1000),
+	}
+
+	// FIFO queue should be created with the FifoQueue attribute
+	if strings.HasSuffix(*jb.queue, fifoSuffix) {
+		jb.fifo = true
+		if _, ok := jb.attributes[fifoAttribute]; !ok {
+			jb.attributes[fifoAttribute] = "true"
+		}
 	}
 
 	// PARSE CONFIGURATION -------
@@ -138,6 +165,9 @@ func NewSQSConsumer(configKey string, log logger.Logger, cfg cfgPlugin.Configure
 	// queue. To get the queue URL, use the GetQueueUrl action. GetQueueUrl require
 	time.Sleep(time.Second * 2)
 
+	// batched deletes and visibility heartbeats
+	go jb.background()
+
 	return jb, nil
 }
 
@@ -186,6 +216,19 @@ func FromPipeline(pipe *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.Conf
 		secret:            conf.Secret,
 		endpoint:          conf.Endpoint,
 		pauseCh:           make(chan struct{}, 1),
+		stopCh:            make(chan struct{}, 1),
+
+		ackFlushInterval:    pipe.Duration(ackFlushInterval, 0),
+		visibilityHeartbeat: pipe.Duration(visibilityHeartbeat, 0),
+		deleteCh:            make(chan *string, 1000),
+	}
+
+	// FIFO queue should be created with the FifoQueue attribute
+	if strings.HasSuffix(*jb.queue, fifoSuffix) {
+		jb.fifo = true
+		if _, ok := jb.attributes[fifoAttribute]; !ok {
+			jb.attributes[fifoAttribute] = "true"
+		}
 	}
 
 	// PARSE CONFIGURATION -------
@@ -220,6 +263,9 @@ func FromPipeline(pipe *pipeline.Pipeline, log logger.Logger, cfg cfgPlugin.Conf
 	// queue. To get the queue URL, use the GetQueueUrl action. GetQueueUrl require
 	time.Sleep(time.Second * 2)
 
+	// batched deletes and visibility heartbeats
+	go jb.background()
+
 	return jb, nil
 }
 
@@ -233,13 +279,12 @@ func (c *consumer) Push(ctx context.Context, jb *job.Job) error {
 		return errors.E(op, errors.Errorf("no such pipeline: %s, actual: %s", jb.Options.Pipeline, pipe.Name()))
 	}
 
-	// The length of time, in seconds, for which to delay a specific message. Valid
-	// values: 0 to 900. Maximum: 15 minutes.
-	if jb.Options.Delay > 900 {
-		return errors.E(op, errors.Errorf("unable to push, maximum possible delay is 900 seconds (15 minutes), provided: %d", jb.Options.Delay))
+	err := c.checkDelay(jb.Options.Delay)
+	if err != nil {
+		return errors.E(op, err)
 	}
 
-	err := c.handleItem(ctx, fromJob(jb))
+	err = c.handleItem(ctx, fromJob(jb))
 	if err != nil {
 		return errors.E(op, err)
 	}
@@ -247,6 +292,90 @@ func (c *consumer) Push(ctx context.Context, jb *job.Job) error {
 	return nil
 }
 
+// PushBatch pushes jobs using SendMessageBatch, up to 10 jobs per request. SendMessageBatch is not atomic,
+// so *jobs.BatchError is returned if only part of the jobs were pushed.
+func (c *consumer) PushBatch(ctx context.Context, jbs []*job.Job) error {
+	const op = errors.Op("sqs_push_batch")
+
+	pipe := c.pipeline.Load().(*pipeline.Pipeline)
+	for i := 0; i < len(jbs); i++ {
+		if pipe.Name() != jbs[i].Options.Pipeline {
+			return errors.E(op, errors.Errorf("no such pipeline: %s, actual: %s", jbs[i].Options.Pipeline, pipe.Name()))
+		}
+
+		err := c.checkDelay(jbs[i].Options.Delay)
+		if err != nil {
+			return errors.E(op, err)
+		}
+	}
+
+	// failed jobs by the index in the batch
+	failed := make(map[int]error)
+	for i := 0; i < len(jbs); i += maxBatchSize {
+		end := i + maxBatchSize
+		if end > len(jbs) {
+			end = len(jbs)
+		}
+
+		entries := make([]types.SendMessageBatchRequestEntry, 0, end-i)
+		for j := i; j < end; j++ {
+			entry, err := fromJob(jbs[j]).packBatchEntry(strconv.Itoa(j), c.fifo)
+			if err != nil {
+				return batchError(op, failed, i, len(jbs), err)
+			}
+
+			entries = append(entries, entry)
+		}
+
+		out, err := c.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: c.queueURL,
+			Entries:  entries,
+		})
+		if err != nil {
+			return batchError(op, failed, i, len(jbs), err)
+		}
+
+		for j := 0; j < len(out.Failed); j++ {
+			idx, _ := strconv.Atoi(aws.ToString(out.Failed[j].Id))
+			failed[idx] = errors.E(op, errors.Errorf("code: %s, message: %s", aws.ToString(out.Failed[j].Code), aws.ToString(out.Failed[j].Message)))
+		}
+	}
+
+	if len(failed) > 0 {
+		return &jobState.BatchError{Errors: failed}
+	}
+
+	return nil
+}
+
+// batchError marks jobs from the index as failed, if no jobs were pushed yet, returns the error as is
+func batchError(op errors.Op, failed map[int]error, from, to int, err error) error {
+	if from == 0 {
+		return errors.E(op, err)
+	}
+
+	for ; from < to; from++ {
+		failed[from] = errors.E(op, err)
+	}
+
+	return &jobState.BatchError{Errors: failed}
+}
+
+func (c *consumer) checkDelay(delay int64) error {
+	// The length of time, in seconds, for which to delay a specific message. Valid
+	// values: 0 to 900. Maximum: 15 minutes.
+	if delay > 900 {
+		return errors.Errorf("unable to push, maximum possible delay is 900 seconds (15 minutes), provided: %d", delay)
+	}
+
+	// FIFO queues don't support per-message delays, only the queue DelaySeconds attribute
+	if c.fifo && delay > 0 {
+		return errors.Errorf("unable to push, per-message delay is not supported by the FIFO queues, provided: %d", delay)
+	}
+
+	return nil
+}
+
 func (c *consumer) State(ctx context.Context) (*jobState.State, error) {
 	const op = errors.Op("sqs_state")
 	attr, err := c.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
@@ -322,6 +451,15 @@ func (c *consumer) Stop(context.Context) error {
 		c.pauseCh <- struct{}{}
 	}
 
+	// flush pending deletes and stop the heartbeats
+	c.stopMu.Lock()
+	stopped := atomic.CompareAndSwapUint32(&c.stopped, 0, 1)
+	c.stopMu.Unlock()
+
+	if stopped {
+		c.stopCh <- struct{}{}
+	}
+
 	pipe := c.pipeline.Load().(*pipeline.Pipeline)
 	c.log.Debug("pipeline stopped", "driver", pipe.Driver(), "pipeline", pipe.Name(), "start", time.Now(), "elapsed", time.Since(start))
 	return nil
@@ -375,7 +513,12 @@ func (c *consumer) Resume(_ context.Context, p string) {
 }
 
 func (c *consumer) handleItem(ctx context.Context, msg *Item) error {
-	d, err := msg.pack(c.queueURL)
+	err := c.checkDelay(msg.Options.Delay)
+	if err != nil {
+		return err
+	}
+
+	d, err := msg.pack(c.queueURL, c.fifo)
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
 	json "github.com/json-iterator/go"
 	"github.com/spiral/errors"
 	"github.com/spiral/roadrunner-plugins/v2/jobs/job"
@@ -19,6 +20,11 @@ const (
 	NumberType              string = "Number"
 	BinaryType              string = "Binary"
 	ApproximateReceiveCount string = "ApproximateReceiveCount"
+
+	// MessageGroupID job header is used as the FIFO queue MessageGroupId, job ID is used if not set
+	MessageGroupID string = "rr_message_group_id"
+	// MessageDeduplicationID job header is used as the FIFO queue MessageDeduplicationId, job ID is used if not set
+	MessageDeduplicationID string = "rr_message_deduplication_id"
 )
 
 var itemAttributes = []string{
@@ -64,6 +70,7 @@ type Options struct {
 	receiptHandler     *string
 	client             *sqs.Client
 	requeueFn          func(context.Context, *Item) error
+	deleteFn           func(*string) error
 }
 
 // DelayDuration returns delay duration in a form of time.Duration.
@@ -104,11 +111,7 @@ func (i *Item) Context() ([]byte, error) {
 }
 
 func (i *Item) Ack() error {
-	_, err := i.Options.client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
-		QueueUrl:      i.Options.queue,
-		ReceiptHandle: i.Options.receiptHandler,
-	})
-
+	err := i.Options.deleteFn(i.Options.receiptHandler)
 	if err != nil {
 		return err
 	}
@@ -123,11 +126,7 @@ func (i *Item) Nack() error {
 		return err
 	}
 
-	_, err = i.Options.client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
-		QueueUrl:      i.Options.queue,
-		ReceiptHandle: i.Options.receiptHandler,
-	})
-
+	err = i.Options.deleteFn(i.Options.receiptHandler)
 	if err != nil {
 		return err
 	}
@@ -147,11 +146,7 @@ func (i *Item) Requeue(headers map[string][]string, delay int64) error {
 	}
 
 	// Delete job from the queue only after successful requeue
-	_, err = i.Options.client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
-		QueueUrl:      i.Options.queue,
-		ReceiptHandle: i.Options.receiptHandler,
-	})
-
+	err = i.Options.deleteFn(i.Options.receiptHandler)
 	if err != nil {
 		return err
 	}
@@ -185,27 +180,83 @@ func fromJob(job *job.Job) *Item {
 	}
 }
 
-func (i *Item) pack(queue *string) (*sqs.SendMessageInput, error) {
+func (i *Item) pack(queue *string, fifo bool) (*sqs.SendMessageInput, error) {
+	attr, err := i.attributes()
+	if err != nil {
+		return nil, err
+	}
+
+	in := &sqs.SendMessageInput{
+		MessageBody:       aws.String(i.Payload),
+		QueueUrl:          queue,
+		DelaySeconds:      int32(i.Options.Delay),
+		MessageAttributes: attr,
+	}
+
+	if fifo {
+		in.MessageGroupId, in.MessageDeduplicationId = i.fifoIDs()
+	}
+
+	return in, nil
+}
+
+// packBatchEntry packs the item into the SendMessageBatch entry, id should be unique within the batch
+func (i *Item) packBatchEntry(id string, fifo bool) (types.SendMessageBatchRequestEntry, error) {
+	attr, err := i.attributes()
+	if err != nil {
+		return types.SendMessageBatchRequestEntry{}, err
+	}
+
+	entry := types.SendMessageBatchRequestEntry{
+		Id:                aws.String(id),
+		MessageBody:       aws.String(i.Payload),
+		DelaySeconds:      int32(i.Options.Delay),
+		MessageAttributes: attr,
+	}
+
+	if fifo {
+		entry.MessageGroupId, entry.MessageDeduplicationId = i.fifoIDs()
+	}
+
+	return entry, nil
+}
+
+func (i *Item) attributes() (map[string]types.MessageAttributeValue, error) {
 	// pack headers map
 	data, err := json.Marshal(i.Headers)
 	if err != nil {
 		return nil, err
 	}
 
-	return &sqs.SendMessageInput{
-		MessageBody:  aws.String(i.Payload),
-		QueueUrl:     queue,
-		DelaySeconds: int32(i.Options.Delay),
-		MessageAttributes: map[string]types.MessageAttributeValue{
-			job.RRID:       {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(i.Ident)},
-			job.RRJob:      {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(i.Job)},
-			job.RRDelay:    {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(strconv.Itoa(int(i.Options.Delay)))},
-			job.RRHeaders:  {DataType: aws.String(BinaryType), BinaryValue: data, BinaryListValues: nil, StringListValues: nil, StringValue: nil},
-			job.RRPriority: {DataType: aws.String(NumberType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(strconv.Itoa(int(i.Options.Priority)))},
-		},
+	return map[string]types.MessageAttributeValue{
+		job.RRID:       {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(i.Ident)},
+		job.RRJob:      {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(i.Job)},
+		job.RRDelay:    {DataType: aws.String(StringType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(strconv.Itoa(int(i.Options.Delay)))},
+		job.RRHeaders:  {DataType: aws.String(BinaryType), BinaryValue: data, BinaryListValues: nil, StringListValues: nil, StringValue: nil},
+		job.RRPriority: {DataType: aws.String(NumberType), BinaryValue: nil, BinaryListValues: nil, StringListValues: nil, StringValue: aws.String(strconv.Itoa(int(i.Options.Priority)))},
 	}, nil
 }
 
+// fifoIDs returns MessageGroupId and MessageDeduplicationId for the FIFO queue
+func (i *Item) fifoIDs() (*string, *string) {
+	groupID := i.Ident
+	if h := i.Headers[MessageGroupID]; len(h) > 0 && h[0] != "" {
+		groupID = h[0]
+	}
+
+	// requeued message should not be deduplicated with the original one
+	if i.Options.receiptHandler != nil {
+		return aws.String(groupID), aws.String(uuid.NewString())
+	}
+
+	dedupID := i.Ident
+	if h := i.Headers[MessageDeduplicationID]; len(h) > 0 && h[0] != "" {
+		dedupID = h[0]
+	}
+
+	return aws.String(groupID), aws.String(dedupID)
+}
+
 func (c *consumer) unpack(msg *types.Message) (*Item, error) {
 	const op = errors.Op("sqs_unpack")
 	// reserved
@@ -255,6 +306,7 @@ func (c *consumer) unpack(msg *types.Message) (*Item, error) {
 			queue:              c.queueURL,
 			receiptHandler:     msg.ReceiptHandle,
 			requeueFn:          c.handleItem,
+			deleteFn:           c.deleteMessage,
 		},
 	}
 
@@ -80,6 +80,11 @@ func (c *consumer) listen(ctx context.Context) { //nolint:gocognit
 					continue
 				}
 
+				// track the message to extend its visibility timeout until it's acknowledged
+				if c.visibilityHeartbeat > 0 {
+					c.inflight.Store(*m.ReceiptHandle, struct{}{})
+				}
+
 				c.pq.Insert(item)
 			}
 		}
@@ -0,0 +1,30 @@
+<?php
+
+/**
+ * @var Goridge\RelayInterface $relay
+ */
+
+use Spiral\Goridge;
+use Spiral\RoadRunner;
+use Spiral\Goridge\StreamRelay;
+
+require __DIR__ . "/vendor/autoload.php";
+
+$rr = new RoadRunner\Worker(new StreamRelay(\STDIN, \STDOUT));
+
+while ($in = $rr->waitPayload()) {
+    try {
+        // fail every job without requeue
+        $rr->respond(new RoadRunner\Payload(json_encode([
+            'type' => 1,
+            'data' => [
+                'message' => 'error',
+                'requeue' => false,
+                'delay_seconds' => 0,
+                'headers' => []
+            ]
+        ])));
+    } catch (\Throwable $e) {
+        $rr->error((string)$e);
+    }
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"os/signal"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -314,3 +315,24 @@ func TestConfigProvider_GeneralSection(t *testing.T) {
 		}
 	}
 }
+
+func TestViperProvider_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".rr.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("jobs:\n  num_pollers: 1\n"), 0o600))
+
+	vp := &config.Viper{
+		Path:   path,
+		Prefix: "rr",
+		Flags:  []string{"jobs.timeout=10"},
+	}
+
+	assert.NoError(t, vp.Init())
+	assert.Equal(t, 1, vp.Get("jobs.num_pollers"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("jobs:\n  num_pollers: 2\n"), 0o600))
+	assert.NoError(t, vp.Reload())
+
+	assert.Equal(t, 2, vp.Get("jobs.num_pollers"))
+	// flags are applied to the reloaded configuration
+	assert.Equal(t, "10", vp.Get("jobs.timeout"))
+}
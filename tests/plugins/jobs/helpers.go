@@ -7,6 +7,7 @@ import (
 	"net/rpc"
 	"testing"
 
+	"github.com/google/uuid"
 	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
 	jobState "github.com/spiral/roadrunner-plugins/v2/api/jobs"
 	jobsv1beta "github.com/spiral/roadrunner-plugins/v2/api/proto/jobs/v1beta"
@@ -15,11 +16,13 @@ import (
 )
 
 const (
-	push    string = "jobs.Push"
-	pause   string = "jobs.Pause"
-	destroy string = "jobs.Destroy"
-	resume  string = "jobs.Resume"
-	stat    string = "jobs.Stat"
+	push      string = "jobs.Push"
+	pushBatch string = "jobs.PushBatch"
+	pause     string = "jobs.Pause"
+	destroy   string = "jobs.Destroy"
+	resume    string = "jobs.Resume"
+	stat      string = "jobs.Stat"
+	list      string = "jobs.ListDetailed"
 )
 
 func resumePipes(pipes ...string) func(t *testing.T) {
@@ -87,6 +90,32 @@ func pushToPipe(pipeline string) func(t *testing.T) {
 	}
 }
 
+func pushBatchToPipe(pipeline string, num int) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		require.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		req := &jobsv1beta.PushBatchRequest{Jobs: make([]*jobsv1beta.Job, num)}
+		for i := 0; i < num; i++ {
+			req.Jobs[i] = &jobsv1beta.Job{
+				Job:     "some/php/namespace",
+				Id:      uuid.NewString(),
+				Payload: `{"hello":"world"}`,
+				Headers: map[string]*jobsv1beta.HeaderValue{"test": {Value: []string{"test2"}}},
+				Options: &jobsv1beta.Options{
+					Priority: 1,
+					Pipeline: pipeline,
+				},
+			}
+		}
+
+		er := &jobsv1beta.Empty{}
+		err = client.Call(pushBatch, req, er)
+		require.NoError(t, err)
+	}
+}
+
 func pushToPipeDelayed(pipeline string, delay int64) func(t *testing.T) {
 	return func(t *testing.T) {
 		conn, err := net.Dial("tcp", "127.0.0.1:6001")
@@ -111,6 +140,30 @@ func pushToPipeDelayed(pipeline string, delay int64) func(t *testing.T) {
 	}
 }
 
+func pushToPipeDelayedErr(pipeline string, delay int64) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		require.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		req := &jobsv1beta.PushRequest{Job: &jobsv1beta.Job{
+			Job:     "some/php/namespace",
+			Id:      "2",
+			Payload: `{"hello":"world"}`,
+			Headers: map[string]*jobsv1beta.HeaderValue{"test": {Value: []string{"test2"}}},
+			Options: &jobsv1beta.Options{
+				Priority: 1,
+				Pipeline: pipeline,
+				Delay:    delay,
+			},
+		}}
+
+		er := &jobsv1beta.Empty{}
+		err = client.Call(push, req, er)
+		require.Error(t, err)
+	}
+}
+
 func pushToPipeErr(pipeline string) func(t *testing.T) {
 	return func(t *testing.T) {
 		conn, err := net.Dial("tcp", "127.0.0.1:6001")
@@ -229,6 +282,42 @@ func stats(state *jobState.State) func(t *testing.T) {
 		state.Active = st.Stats[0].Active
 		state.Delayed = st.Stats[0].Delayed
 		state.Reserved = st.Stats[0].Reserved
+		state.Buried = st.Stats[0].Buried
 		state.Ready = st.Stats[0].Ready
+		state.Extra = st.Stats[0].Extra
 	}
 }
+
+func listPipelines(out map[string]*jobsv1beta.PipelineInfo) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		assert.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		resp := &jobsv1beta.PipelinesInfo{}
+		err = client.Call(list, &jobsv1beta.Empty{}, resp)
+		require.NoError(t, err)
+
+		for _, p := range resp.GetPipelines() {
+			out[p.GetName()] = p
+		}
+	}
+}
+
+// pipelineStats returns stats by the pipeline name
+func pipelineStats(t *testing.T) map[string]*jobsv1beta.Stat {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	st := &jobsv1beta.Stats{}
+	err = client.Call(stat, &jobsv1beta.Empty{}, st)
+	require.NoError(t, err)
+
+	out := make(map[string]*jobsv1beta.Stat, len(st.GetStats()))
+	for _, s := range st.GetStats() {
+		out[s.GetPipeline()] = s
+	}
+
+	return out
+}
@@ -522,3 +522,115 @@ func declareBeanstalkPipe(t *testing.T) {
 	err = client.Call("jobs.Declare", pipe, er)
 	require.NoError(t, err)
 }
+
+func TestBeanstalkBuryKick(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel), endure.GracefulShutdownTimeout(time.Second*60))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "beanstalk/.rr-beanstalk-bury.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&server.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+		&jobs.Plugin{},
+		&resetter.Plugin{},
+		&informer.Plugin{},
+		&beanstalk.Plugin{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 3)
+
+	t.Run("PushPipeline", pushToPipe("test-1"))
+	t.Run("PushPipeline", pushToPipe("test-1"))
+	time.Sleep(time.Second * 3)
+
+	out := &jobState.State{}
+	t.Run("Stats", stats(out))
+
+	assert.Equal(t, "test-1", out.Pipeline)
+	assert.Equal(t, "default-bury", out.Queue)
+	assert.Equal(t, int64(0), out.Active)
+	assert.Equal(t, int64(0), out.Reserved)
+	assert.Equal(t, int64(2), out.Buried)
+
+	t.Run("PausePipeline", pausePipelines("test-1"))
+	time.Sleep(time.Second * 2)
+	t.Run("KickBuried", kickBuried("default-bury", 1, 1))
+	time.Sleep(time.Second)
+
+	out = &jobState.State{}
+	t.Run("Stats", stats(out))
+
+	assert.Equal(t, int64(1), out.Active)
+	assert.Equal(t, int64(1), out.Buried)
+
+	t.Run("DestroyPipeline", destroyPipelines("test-1"))
+
+	time.Sleep(time.Second)
+	stopCh <- struct{}{}
+	wg.Wait()
+}
+
+func kickBuried(tube string, bound, expected int) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		require.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		kicked := 0
+		err = client.Call("beanstalk.Kick", &beanstalk.KickRequest{Tube: tube, Bound: bound}, &kicked)
+		require.NoError(t, err)
+		require.Equal(t, expected, kicked)
+	}
+}
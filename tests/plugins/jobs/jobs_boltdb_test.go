@@ -431,3 +431,125 @@ func declareBoltDBPipe(file string) func(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestBoltDBSharedFileCompact(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "boltdb/.rr-boltdb-shared.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&server.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+		&jobs.Plugin{},
+		&resetter.Plugin{},
+		&informer.Plugin{},
+		&boltdb.Plugin{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 3)
+
+	t.Run("PushPipeline", pushBatchToPipe("test-1", 3))
+	t.Run("PushPipeline", pushBatchToPipe("test-2", 1))
+	t.Run("PushPipelineDelayed", pushToPipeDelayed("test-2", 60))
+
+	st := pipelineStats(t)
+	require.Contains(t, st, "test-1")
+	require.Contains(t, st, "test-2")
+
+	assert.Equal(t, int64(3), st["test-1"].Extra["push"])
+	assert.Equal(t, int64(0), st["test-1"].Extra["delayed"])
+	assert.Equal(t, int64(1), st["test-2"].Extra["push"])
+	assert.Equal(t, int64(1), st["test-2"].Extra["delayed"])
+	assert.Greater(t, st["test-1"].Extra["file_size"], int64(0))
+	assert.Equal(t, st["test-1"].Extra["file_size"], st["test-2"].Extra["file_size"])
+
+	t.Run("Compact", compactBoltDB("rr-shared.db"))
+
+	// data survives the compaction
+	st = pipelineStats(t)
+	assert.Equal(t, int64(3), st["test-1"].Extra["push"])
+	assert.Equal(t, int64(1), st["test-2"].Extra["push"])
+	assert.Equal(t, int64(1), st["test-2"].Extra["delayed"])
+
+	t.Run("ResumePipeline", resumePipes("test-1", "test-2"))
+	time.Sleep(time.Second * 3)
+
+	st = pipelineStats(t)
+	assert.Equal(t, int64(0), st["test-1"].Extra["push"])
+	assert.Equal(t, int64(0), st["test-1"].Extra["processing"])
+	assert.Equal(t, int64(0), st["test-2"].Extra["push"])
+	assert.Equal(t, int64(1), st["test-2"].Extra["delayed"])
+
+	t.Run("DestroyPipeline", destroyPipelines("test-1", "test-2"))
+
+	time.Sleep(time.Second)
+	stopCh <- struct{}{}
+	wg.Wait()
+
+	assert.NoError(t, os.Remove("rr-shared.db"))
+}
+
+func compactBoltDB(file string) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		require.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		ok := false
+		err = client.Call("boltdb.Compact", file, &ok)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spiral/roadrunner-plugins/v2/amqp"
+	"github.com/spiral/roadrunner-plugins/v2/api/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/beanstalk"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/jobs/driverstest"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	"github.com/spiral/roadrunner-plugins/v2/nats"
+	"github.com/spiral/roadrunner-plugins/v2/sqs"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// driver is the jobs driver plugin
+type driver interface {
+	jobs.Constructor
+	Init(log logger.Logger, cfg config.Configurer) error
+}
+
+func TestConformance(t *testing.T) {
+	cfg := &config.Viper{
+		Path:   "configs/.rr-conformance.yaml",
+		Prefix: "rr",
+	}
+	require.NoError(t, cfg.Init())
+
+	log := logger.NewZapAdapter(zap.NewNop())
+
+	tests := []struct {
+		plugin driver
+		cfg    *driverstest.Config
+	}{
+		{&memory.Plugin{}, &driverstest.Config{Driver: "memory"}},
+		{&boltdb.Plugin{}, &driverstest.Config{
+			Driver: "boltdb",
+			Options: func(t *testing.T, name string) map[string]interface{} {
+				return map[string]interface{}{"file": filepath.Join(t.TempDir(), name+".db")}
+			},
+			// nacked jobs are returned into the queue
+			NackRedelivers: true,
+		}},
+		{&amqp.Plugin{}, &driverstest.Config{
+			Driver: "amqp",
+			Options: func(_ *testing.T, name string) map[string]interface{} {
+				return map[string]interface{}{"queue": name, "routing_key": name, "exchange": "amqp.default", "exchange_type": "direct"}
+			},
+		}},
+		{&beanstalk.Plugin{}, &driverstest.Config{
+			Driver: "beanstalk",
+			Options: func(_ *testing.T, name string) map[string]interface{} {
+				return map[string]interface{}{"tube": name, "reserve_timeout": 1}
+			},
+		}},
+		{&sqs.Plugin{}, &driverstest.Config{
+			Driver: "sqs",
+			Options: func(_ *testing.T, name string) map[string]interface{} {
+				return map[string]interface{}{"queue": name, "wait_time": 1}
+			},
+		}},
+		{&nats.Plugin{}, &driverstest.Config{
+			Driver: "nats",
+			Options: func(_ *testing.T, name string) map[string]interface{} {
+				return map[string]interface{}{"subject": name, "stream": name, "deliver_new": "true"}
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.cfg.Driver, func(t *testing.T) {
+			require.NoError(t, tt.plugin.Init(log, cfg))
+			driverstest.Run(t, tt.plugin, tt.cfg)
+		})
+	}
+}
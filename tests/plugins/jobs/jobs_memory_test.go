@@ -5,6 +5,7 @@ import (
 	"net/rpc"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/spiral/roadrunner-plugins/v2/server"
 	"github.com/spiral/roadrunner-plugins/v2/tests/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryInit(t *testing.T) {
@@ -633,3 +635,312 @@ func consumeMemoryPipe(t *testing.T) {
 	err = client.Call("jobs.Resume", pipe, er)
 	assert.NoError(t, err)
 }
+
+func TestMemoryPersist(t *testing.T) {
+	t.Cleanup(func() {
+		_ = os.Remove("memory/rr-memory-persist.wal")
+	})
+
+	run := func(fn func()) {
+		cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+		assert.NoError(t, err)
+
+		cfg := &config.Viper{
+			Path:   "memory/.rr-memory-persist.yaml",
+			Prefix: "rr",
+		}
+
+		err = cont.RegisterAll(
+			cfg,
+			&server.Plugin{},
+			&rpcPlugin.Plugin{},
+			&logger.ZapLogger{},
+			&jobs.Plugin{},
+			&resetter.Plugin{},
+			&informer.Plugin{},
+			&memory.Plugin{},
+		)
+		assert.NoError(t, err)
+
+		err = cont.Init()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ch, err := cont.Serve()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		stopCh := make(chan struct{}, 1)
+
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case e := <-ch:
+					assert.Fail(t, "error", e.Error.Error())
+					err = cont.Stop()
+					if err != nil {
+						assert.FailNow(t, "error", err.Error())
+					}
+				case <-stopCh:
+					err = cont.Stop()
+					if err != nil {
+						assert.FailNow(t, "error", err.Error())
+					}
+					return
+				}
+			}
+		}()
+
+		time.Sleep(time.Second * 3)
+		fn()
+		stopCh <- struct{}{}
+		wg.Wait()
+	}
+
+	run(func() {
+		t.Run("PushPipeline", pushToPipe("test-local"))
+		t.Run("PushPipeline", pushToPipe("test-local"))
+		t.Run("PushPipelineDelayed", pushToPipeDelayed("test-local", 60))
+		time.Sleep(time.Second)
+
+		out := &jobState.State{}
+		t.Run("Stats", stats(out))
+
+		assert.Equal(t, int64(2), out.Active)
+		assert.Equal(t, int64(1), out.Delayed)
+	})
+
+	// jobs and the remaining delay are restored from the journal
+	run(func() {
+		out := &jobState.State{}
+		t.Run("Stats", stats(out))
+
+		assert.Equal(t, "test-local", out.Pipeline)
+		assert.Equal(t, int64(2), out.Active)
+		assert.Equal(t, int64(1), out.Delayed)
+
+		t.Run("ResumePipeline", resumePipes("test-local"))
+		time.Sleep(time.Second * 3)
+
+		out = &jobState.State{}
+		t.Run("Stats", stats(out))
+
+		assert.Equal(t, int64(0), out.Active)
+		assert.Equal(t, int64(1), out.Delayed)
+		t.Run("DestroyPipeline", destroyPipelines("test-local"))
+	})
+}
+
+func TestMemoryDeclareRegistry(t *testing.T) {
+	t.Cleanup(func() {
+		_ = os.Remove("memory/rr-memory-registry.json")
+	})
+
+	run := func(fn func()) {
+		cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+		assert.NoError(t, err)
+
+		cfg := &config.Viper{
+			Path:   "memory/.rr-memory-registry.yaml",
+			Prefix: "rr",
+		}
+
+		err = cont.RegisterAll(
+			cfg,
+			&server.Plugin{},
+			&rpcPlugin.Plugin{},
+			&logger.ZapLogger{},
+			&jobs.Plugin{},
+			&resetter.Plugin{},
+			&informer.Plugin{},
+			&memory.Plugin{},
+		)
+		assert.NoError(t, err)
+
+		err = cont.Init()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ch, err := cont.Serve()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		stopCh := make(chan struct{}, 1)
+
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case e := <-ch:
+					assert.Fail(t, "error", e.Error.Error())
+					err = cont.Stop()
+					if err != nil {
+						assert.FailNow(t, "error", err.Error())
+					}
+				case <-stopCh:
+					err = cont.Stop()
+					if err != nil {
+						assert.FailNow(t, "error", err.Error())
+					}
+					return
+				}
+			}
+		}()
+
+		time.Sleep(time.Second * 3)
+		fn()
+		stopCh <- struct{}{}
+		wg.Wait()
+	}
+
+	run(func() {
+		t.Run("DeclarePipeline", declareMemoryPipe)
+		t.Run("ConsumePipeline", consumeMemoryPipe)
+		time.Sleep(time.Second)
+
+		out := make(map[string]*jobsv1beta.PipelineInfo)
+		t.Run("ListPipelines", listPipelines(out))
+
+		require.Len(t, out, 2)
+		assert.False(t, out["test-local"].GetDynamic())
+		assert.True(t, out["test-3"].GetDynamic())
+	})
+
+	// declared pipeline is replayed and consumed after the restart
+	run(func() {
+		out := make(map[string]*jobsv1beta.PipelineInfo)
+		t.Run("ListPipelines", listPipelines(out))
+
+		require.Len(t, out, 2)
+		assert.True(t, out["test-3"].GetDynamic())
+		assert.Equal(t, "memory", out["test-3"].GetDriver())
+
+		t.Run("PushPipeline", pushToPipe("test-3"))
+		time.Sleep(time.Second)
+
+		t.Run("DestroyPipeline", destroyPipelines("test-3"))
+	})
+
+	// destroyed pipeline is removed from the registry
+	run(func() {
+		out := make(map[string]*jobsv1beta.PipelineInfo)
+		t.Run("ListPipelines", listPipelines(out))
+
+		require.Len(t, out, 1)
+		assert.NotNil(t, out["test-local"])
+	})
+}
+
+func TestMemoryReloadConfig(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), ".rr-memory-reload.yaml")
+	copyFile(t, "memory/.rr-memory-reload.yaml", cfgPath)
+
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   cfgPath,
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&server.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+		&jobs.Plugin{},
+		&resetter.Plugin{},
+		&informer.Plugin{},
+		&memory.Plugin{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-stopCh:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 3)
+
+	st := pipelineStats(t)
+	require.Len(t, st, 3)
+	assert.True(t, st["test-1"].GetReady())
+	assert.True(t, st["test-2"].GetReady())
+	assert.False(t, st["test-3"].GetReady())
+
+	t.Run("PushPipeline", pushToPipe("test-2"))
+	time.Sleep(time.Second)
+
+	copyFile(t, "memory/.rr-memory-reload-2.yaml", cfgPath)
+	t.Run("ReloadConfig", reloadConfig)
+	time.Sleep(time.Second)
+
+	st = pipelineStats(t)
+	require.Len(t, st, 3)
+	assert.NotContains(t, st, "test-3")
+	assert.False(t, st["test-1"].GetReady())
+	assert.True(t, st["test-2"].GetReady())
+	assert.True(t, st["test-4"].GetReady())
+
+	t.Run("PushPipeline", pushToPipe("test-4"))
+	time.Sleep(time.Second)
+
+	stopCh <- struct{}{}
+	wg.Wait()
+}
+
+func copyFile(t *testing.T, from, to string) {
+	data, err := os.ReadFile(from)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(to, data, 0o600))
+}
+
+func reloadConfig(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	assert.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	err = client.Call("jobs.Reload", &jobsv1beta.Empty{}, &jobsv1beta.Empty{})
+	assert.NoError(t, err)
+}
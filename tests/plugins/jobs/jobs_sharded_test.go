@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/informer"
+	"github.com/spiral/roadrunner-plugins/v2/jobs"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	"github.com/spiral/roadrunner-plugins/v2/resetter"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/spiral/roadrunner-plugins/v2/server"
+	"github.com/spiral/roadrunner-plugins/v2/sharded"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedMemory(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "sharded/.rr-sharded.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&server.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+		&jobs.Plugin{},
+		&resetter.Plugin{},
+		&informer.Plugin{},
+		&memory.Plugin{},
+		&sharded.Plugin{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopCh := make(chan struct{}, 1)
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-stopCh:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 3)
+
+	t.Run("PushPipeline", pushBatchToPipe("test-sharded", 100))
+	time.Sleep(time.Second)
+
+	st := pipelineStats(t)
+	require.Contains(t, st, "test-sharded")
+	assert.Equal(t, "sharded", st["test-sharded"].GetDriver())
+	assert.Equal(t, int64(100), st["test-sharded"].GetActive())
+	assert.Equal(t, int64(100), st["test-sharded"].GetExtra()["shard-1.active"]+st["test-sharded"].GetExtra()["shard-2.active"])
+
+	t.Run("ResumePipeline", resumePipes("test-sharded"))
+	time.Sleep(time.Second * 3)
+
+	st = pipelineStats(t)
+	assert.Equal(t, int64(0), st["test-sharded"].GetActive())
+	assert.True(t, st["test-sharded"].GetReady())
+
+	t.Run("DestroyPipeline", destroyPipelines("test-sharded"))
+
+	stopCh <- struct{}{}
+	<-doneCh
+}
@@ -0,0 +1,132 @@
+package kv
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
+	payload "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	"github.com/spiral/roadrunner-plugins/v2/mirror"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVMirror(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "configs/.rr-kv-mirror.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&kv.Plugin{},
+		&mirror.Plugin{},
+		&memory.Plugin{},
+		&boltdb.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	assert.NoError(t, err)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 1)
+	t.Run("MIRROR", testMirror)
+	t.Run("COUNTERS", testCounters("mirror-rr"))
+	t.Run("CONDITIONAL", testConditional("mirror-rr"))
+	t.Run("KEYS", testKeys("mirror-rr"))
+	t.Run("TAGS", testTags("mirror-rr"))
+	stopCh <- struct{}{}
+	wg.Wait()
+
+	_ = os.Remove("mirror.db")
+}
+
+func testMirror(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	data := &payload.Request{
+		Storage: "mirror-rr",
+		Items: []*payload.Item{
+			{Key: "a", Value: []byte("aa")},
+			{Key: "b", Value: []byte("bb")},
+		},
+	}
+
+	ret := &payload.Response{}
+	err = client.Call("kv.Set", data, ret)
+	require.NoError(t, err)
+
+	// read from the primary and compared with the secondary
+	ret = &payload.Response{}
+	err = client.Call("kv.MGet", data, ret)
+	require.NoError(t, err)
+	assert.Len(t, ret.GetItems(), 2)
+
+	err = client.Call("kv.Delete", data, ret)
+	require.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.Has", data, ret)
+	require.NoError(t, err)
+	assert.Empty(t, ret.GetItems())
+}
@@ -0,0 +1,174 @@
+package kv
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
+	payload "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVSnapshot(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "configs/.rr-kv-snapshot.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&kv.Plugin{},
+		&memory.Plugin{},
+		&boltdb.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	assert.NoError(t, err)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 1)
+	t.Run("SNAPSHOT", testSnapshot)
+	stopCh <- struct{}{}
+	wg.Wait()
+
+	_ = os.Remove("snapshot.db")
+	_ = os.RemoveAll("snapshots")
+}
+
+func testSnapshot(t *testing.T) {
+	require.NoError(t, os.MkdirAll("snapshots", 0755))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	tt := time.Now().Add(time.Minute).Format(time.RFC3339)
+	data := &payload.Request{
+		Storage: "memory-rr",
+		Items: []*payload.Item{
+			{Key: "snap:a", Value: []byte("aa"), Timeout: tt, Tags: []string{"snap"}},
+			{Key: "snap:b", Value: []byte("bb")},
+			{Key: "other", Value: []byte("cc")},
+		},
+	}
+
+	ret := &payload.Response{}
+	err = client.Call("kv.Set", data, ret)
+	require.NoError(t, err)
+
+	out := &payload.SnapshotResponse{}
+	err = client.Call("kv.Export", &payload.SnapshotRequest{Storage: "memory-rr", Prefix: "snap:", File: "snapshot.jsonl", Chunk: 1}, out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), out.GetKeys())
+
+	out = &payload.SnapshotResponse{}
+	err = client.Call("kv.Import", &payload.SnapshotRequest{Storage: "boltdb-rr", File: "snapshot.jsonl"}, out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), out.GetKeys())
+
+	ret = &payload.Response{}
+	err = client.Call("kv.MGet", &payload.Request{Storage: "boltdb-rr", Items: data.Items}, ret)
+	require.NoError(t, err)
+
+	values := make(map[string]string, 2)
+	for _, it := range ret.GetItems() {
+		values[it.GetKey()] = string(it.GetValue())
+	}
+	assert.Equal(t, map[string]string{"snap:a": "aa", "snap:b": "bb"}, values)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.TTL", &payload.Request{Storage: "boltdb-rr", Items: []*payload.Item{{Key: "snap:a"}}}, ret)
+	require.NoError(t, err)
+	require.Len(t, ret.GetItems(), 1)
+	assert.Equal(t, tt, ret.GetItems()[0].GetTimeout())
+
+	// tags are exported
+	err = client.Call("kv.InvalidateTags", &payload.TagsRequest{Storage: "boltdb-rr", Tags: []string{"snap"}}, ret)
+	require.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.Has", &payload.Request{Storage: "boltdb-rr", Items: data.Items}, ret)
+	require.NoError(t, err)
+	require.Len(t, ret.GetItems(), 1)
+	assert.Equal(t, "snap:b", ret.GetItems()[0].GetKey())
+
+	// paths outside the snapshots directory are rejected
+	err = client.Call("kv.Export", &payload.SnapshotRequest{Storage: "memory-rr", File: "../snapshot.jsonl"}, out)
+	assert.Error(t, err)
+
+	err = client.Call("kv.Import", &payload.SnapshotRequest{Storage: "boltdb-rr", File: "/etc/passwd"}, out)
+	assert.Error(t, err)
+
+	// expired keys are skipped
+	expired := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	err = os.WriteFile("snapshots/snapshot.jsonl", []byte(`{"key":"snap:c","value":"Y2M=","timeout":"`+expired+`"}`+"\n"), 0600)
+	require.NoError(t, err)
+
+	out = &payload.SnapshotResponse{}
+	err = client.Call("kv.Import", &payload.SnapshotRequest{Storage: "boltdb-rr", File: "snapshot.jsonl"}, out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), out.GetKeys())
+	assert.Equal(t, int64(1), out.GetSkipped())
+
+	err = client.Call("kv.Import", &payload.SnapshotRequest{Storage: "boltdb-rr", File: "no-such-file.jsonl"}, out)
+	assert.Error(t, err)
+}
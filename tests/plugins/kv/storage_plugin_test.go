@@ -427,6 +427,10 @@ func TestBoltDb(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 	t.Run("BOLTDB", testRPCMethods)
+	t.Run("COUNTERS", testCounters("boltdb-rr"))
+	t.Run("CONDITIONAL", testConditional("boltdb-rr"))
+	t.Run("KEYS", testKeys("boltdb-rr"))
+	t.Run("TAGS", testTags("boltdb-rr"))
 	stopCh <- struct{}{}
 	wg.Wait()
 
@@ -690,6 +694,8 @@ func TestMemcached(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 	t.Run("MEMCACHED", testRPCMethodsMemcached)
+	t.Run("COUNTERS", testCounters("memcached-rr"))
+	t.Run("CONDITIONAL", testConditional("memcached-rr"))
 	stopCh <- struct{}{}
 	wg.Wait()
 }
@@ -952,6 +958,11 @@ func TestInMemory(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 	t.Run("INMEMORY", testRPCMethodsInMemory)
+	t.Run("COUNTERS", testCounters("memory-rr"))
+	t.Run("CONDITIONAL", testConditional("memory-rr"))
+	t.Run("KEYS", testKeys("memory-rr"))
+	t.Run("TAGS", testTags("memory-rr"))
+	t.Run("EVICTION", testEviction)
 	stopCh <- struct{}{}
 	wg.Wait()
 }
@@ -1214,6 +1225,10 @@ func TestRedis(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 	t.Run("REDIS", testRPCMethodsRedis)
+	t.Run("COUNTERS", testCounters("redis-rr"))
+	t.Run("CONDITIONAL", testConditional("redis-rr"))
+	t.Run("KEYS", testKeys("redis-rr"))
+	t.Run("TAGS", testTags("redis-rr"))
 	stopCh <- struct{}{}
 	wg.Wait()
 }
@@ -1282,6 +1297,10 @@ func TestRedisGlobalSection(t *testing.T) {
 
 	time.Sleep(time.Second * 1)
 	t.Run("REDIS", testRPCMethodsRedis)
+	t.Run("COUNTERS", testCounters("redis-rr"))
+	t.Run("CONDITIONAL", testConditional("redis-rr"))
+	t.Run("KEYS", testKeys("redis-rr"))
+	t.Run("TAGS", testTags("redis-rr"))
 	stopCh <- struct{}{}
 	wg.Wait()
 }
@@ -1515,3 +1534,222 @@ func testRPCMethodsRedis(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, ret.GetItems(), 0) // should be 5
 }
+
+func testCounters(storage string) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		assert.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		tt := time.Now().Add(time.Second * 5).Format(time.RFC3339)
+
+		// missing key is created, zero delta means 1
+		out := &payload.CounterResponse{}
+		err = client.Call("kv.Incr", &payload.CounterRequest{Storage: storage, Key: "counter", Timeout: tt}, out)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), out.Value)
+
+		err = client.Call("kv.Incr", &payload.CounterRequest{Storage: storage, Key: "counter", Delta: 10}, out)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), out.Value)
+
+		err = client.Call("kv.Decr", &payload.CounterRequest{Storage: storage, Key: "counter", Delta: 5}, out)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), out.Value)
+
+		ret := &payload.Response{}
+		err = client.Call("kv.MGet", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "counter"}}}, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 1)
+		assert.Equal(t, []byte("6"), ret.GetItems()[0].GetValue())
+
+		// not an integer
+		err = client.Call("kv.Set", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "string", Value: []byte("abc")}}}, ret)
+		assert.NoError(t, err)
+		err = client.Call("kv.Incr", &payload.CounterRequest{Storage: storage, Key: "string"}, out)
+		assert.Error(t, err)
+
+		err = client.Call("kv.Incr", &payload.CounterRequest{Storage: "no-such-storage", Key: "counter"}, out)
+		assert.Error(t, err)
+
+		err = client.Call("kv.Delete", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "counter"}, {Key: "string"}}}, ret)
+		assert.NoError(t, err)
+	}
+}
+
+func testConditional(storage string) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		assert.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		tt := time.Now().Add(time.Second * 5).Format(time.RFC3339)
+
+		ret := &payload.Response{}
+		err = client.Call("kv.SetNX", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "lock", Value: []byte("owner-1"), Timeout: tt}}}, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 1)
+
+		// already exists, only the new key is set
+		err = client.Call("kv.SetNX", &payload.Request{Storage: storage, Items: []*payload.Item{
+			{Key: "lock", Value: []byte("owner-2")},
+			{Key: "lock2", Value: []byte("owner-2")},
+		}}, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 1)
+		assert.Equal(t, "lock2", ret.GetItems()[0].GetKey())
+
+		out := &payload.CompareAndSwapResponse{}
+		err = client.Call("kv.CompareAndSwap", &payload.CompareAndSwapRequest{Storage: storage, Key: "lock", OldValue: []byte("owner-2"), NewValue: []byte("owner-3")}, out)
+		assert.NoError(t, err)
+		assert.False(t, out.Applied)
+
+		err = client.Call("kv.CompareAndSwap", &payload.CompareAndSwapRequest{Storage: storage, Key: "lock", OldValue: []byte("owner-1"), NewValue: []byte("owner-3"), Timeout: tt}, out)
+		assert.NoError(t, err)
+		assert.True(t, out.Applied)
+
+		out = &payload.CompareAndSwapResponse{}
+		err = client.Call("kv.CompareAndSwap", &payload.CompareAndSwapRequest{Storage: storage, Key: "no-such-key", OldValue: []byte("owner-1"), NewValue: []byte("owner-3")}, out)
+		assert.NoError(t, err)
+		assert.False(t, out.Applied)
+
+		err = client.Call("kv.MGet", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "lock"}}}, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 1)
+		assert.Equal(t, []byte("owner-3"), ret.GetItems()[0].GetValue())
+
+		err = client.Call("kv.Delete", &payload.Request{Storage: storage, Items: []*payload.Item{{Key: "lock"}, {Key: "lock2"}}}, ret)
+		assert.NoError(t, err)
+	}
+}
+
+func testKeys(storage string) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		assert.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		data := &payload.Request{
+			Storage: storage,
+			Items: []*payload.Item{
+				{Key: "tenant:1:a", Value: []byte("a")},
+				{Key: "tenant:1:b", Value: []byte("b")},
+				{Key: "tenant:1:c", Value: []byte("c")},
+				{Key: "tenant:2:a", Value: []byte("a")},
+			},
+		}
+
+		ret := &payload.Response{}
+		err = client.Call("kv.Set", data, ret)
+		assert.NoError(t, err)
+
+		keys := make(map[string]struct{})
+		cursor := ""
+		for i := 0; ; i++ {
+			// SCAN might return the empty pages
+			assert.Less(t, i, 100)
+
+			out := &payload.KeysResponse{}
+			err = client.Call("kv.Keys", &payload.KeysRequest{Storage: storage, Prefix: "tenant:1:", Cursor: cursor, Limit: 2}, out)
+			assert.NoError(t, err)
+
+			for _, k := range out.GetKeys() {
+				keys[k] = struct{}{}
+			}
+
+			cursor = out.GetCursor()
+			if cursor == "" {
+				break
+			}
+		}
+
+		assert.Equal(t, map[string]struct{}{"tenant:1:a": {}, "tenant:1:b": {}, "tenant:1:c": {}}, keys)
+
+		err = client.Call("kv.Delete", data, ret)
+		assert.NoError(t, err)
+	}
+}
+
+func testTags(storage string) func(t *testing.T) {
+	return func(t *testing.T) {
+		conn, err := net.Dial("tcp", "127.0.0.1:6001")
+		assert.NoError(t, err)
+		client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+		data := &payload.Request{
+			Storage: storage,
+			Items: []*payload.Item{
+				{Key: "user:42:profile", Value: []byte("a"), Tags: []string{"user:42"}},
+				{Key: "user:42:orders", Value: []byte("b"), Tags: []string{"user:42", "orders"}},
+				{Key: "user:43:orders", Value: []byte("c"), Tags: []string{"user:43", "orders"}},
+				{Key: "untagged", Value: []byte("d")},
+			},
+		}
+
+		ret := &payload.Response{}
+		err = client.Call("kv.Set", data, ret)
+		assert.NoError(t, err)
+
+		err = client.Call("kv.InvalidateTags", &payload.TagsRequest{Storage: storage, Tags: []string{"user:42"}}, ret)
+		assert.NoError(t, err)
+
+		ret = &payload.Response{}
+		err = client.Call("kv.Has", data, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 2)
+
+		// already invalidated tag
+		err = client.Call("kv.InvalidateTags", &payload.TagsRequest{Storage: storage, Tags: []string{"orders", "user:42"}}, ret)
+		assert.NoError(t, err)
+
+		ret = &payload.Response{}
+		err = client.Call("kv.Has", data, ret)
+		assert.NoError(t, err)
+		assert.Len(t, ret.GetItems(), 1)
+		assert.Equal(t, "untagged", ret.GetItems()[0].GetKey())
+
+		err = client.Call("kv.Delete", data, ret)
+		assert.NoError(t, err)
+	}
+}
+
+func testEviction(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	assert.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	data := &payload.Request{
+		Storage: "memory-lru",
+		Items: []*payload.Item{
+			{Key: "a", Value: []byte("a")},
+			{Key: "b", Value: []byte("b")},
+			{Key: "c", Value: []byte("c")},
+		},
+	}
+
+	ret := &payload.Response{}
+	err = client.Call("kv.Set", data, ret)
+	assert.NoError(t, err)
+
+	// a is the most recently used now
+	ret = &payload.Response{}
+	err = client.Call("kv.MGet", &payload.Request{Storage: "memory-lru", Items: []*payload.Item{{Key: "a"}}}, ret)
+	assert.NoError(t, err)
+	assert.Len(t, ret.GetItems(), 1)
+
+	err = client.Call("kv.Set", &payload.Request{Storage: "memory-lru", Items: []*payload.Item{{Key: "d", Value: []byte("d")}}}, ret)
+	assert.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.Has", &payload.Request{Storage: "memory-lru", Items: []*payload.Item{{Key: "a"}, {Key: "b"}, {Key: "c"}, {Key: "d"}}}, ret)
+	assert.NoError(t, err)
+
+	keys := make([]string, 0, len(ret.GetItems()))
+	for _, it := range ret.GetItems() {
+		keys = append(keys, it.GetKey())
+	}
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, keys)
+
+	err = client.Call("kv.Clear", &payload.Request{Storage: "memory-lru"}, ret)
+	assert.NoError(t, err)
+}
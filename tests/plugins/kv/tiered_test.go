@@ -0,0 +1,156 @@
+package kv
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
+	payload "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/spiral/roadrunner-plugins/v2/tiered"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVTiered(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "configs/.rr-kv-tiered.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&kv.Plugin{},
+		&tiered.Plugin{},
+		&memory.Plugin{},
+		&boltdb.Plugin{},
+		&broadcast.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	assert.NoError(t, err)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 1)
+	t.Run("TIERED", testTiered)
+	t.Run("COUNTERS", testCounters("tiered-rr"))
+	t.Run("CONDITIONAL", testConditional("tiered-rr"))
+	t.Run("KEYS", testKeys("tiered-rr"))
+	t.Run("TAGS", testTags("tiered-rr"))
+	stopCh <- struct{}{}
+	wg.Wait()
+
+	_ = os.Remove("tiered.db")
+}
+
+func testTiered(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	tt := time.Now().Add(time.Minute).Format(time.RFC3339)
+	data := &payload.Request{
+		Storage: "tiered-rr",
+		Items: []*payload.Item{
+			{Key: "a", Value: []byte("aa"), Timeout: tt},
+			{Key: "b", Value: []byte("bb")},
+		},
+	}
+
+	ret := &payload.Response{}
+	err = client.Call("kv.Set", data, ret)
+	require.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.MGet", data, ret)
+	require.NoError(t, err)
+	require.Len(t, ret.GetItems(), 2)
+
+	values := make(map[string]string, 2)
+	for _, it := range ret.GetItems() {
+		values[it.GetKey()] = string(it.GetValue())
+	}
+	assert.Equal(t, map[string]string{"a": "aa", "b": "bb"}, values)
+
+	// TTL is read from the L2, not capped by the L1 TTL
+	ret = &payload.Response{}
+	err = client.Call("kv.TTL", &payload.Request{Storage: "tiered-rr", Items: []*payload.Item{{Key: "a"}}}, ret)
+	require.NoError(t, err)
+	require.Len(t, ret.GetItems(), 1)
+	assert.Equal(t, tt, ret.GetItems()[0].GetTimeout())
+
+	err = client.Call("kv.Delete", &payload.Request{Storage: "tiered-rr", Items: []*payload.Item{{Key: "a"}}}, ret)
+	require.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.Has", data, ret)
+	require.NoError(t, err)
+	require.Len(t, ret.GetItems(), 1)
+	assert.Equal(t, "b", ret.GetItems()[0].GetKey())
+
+	err = client.Call("kv.Clear", &payload.Request{Storage: "tiered-rr"}, ret)
+	require.NoError(t, err)
+
+	ret = &payload.Response{}
+	err = client.Call("kv.Has", data, ret)
+	require.NoError(t, err)
+	assert.Empty(t, ret.GetItems())
+}
@@ -0,0 +1,133 @@
+package kv
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
+	payload "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/memory"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVWatch(t *testing.T) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   "configs/.rr-kv-watch.yaml",
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(
+		cfg,
+		&kv.Plugin{},
+		&memory.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+	)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	assert.NoError(t, err)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 1)
+	t.Run("WATCH", testWatch)
+	stopCh <- struct{}{}
+	wg.Wait()
+}
+
+func testWatch(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	ret := &payload.Response{}
+	err = client.Call("kv.Set", &payload.Request{Storage: "memory-watch", Items: []*payload.Item{
+		{Key: "flags:dark-mode", Value: []byte("1")},
+		{Key: "users:1", Value: []byte("1")},
+	}}, ret)
+	require.NoError(t, err)
+
+	out := &payload.Events{}
+	err = client.Call("kv.Watch", &payload.WatchRequest{Storage: "memory-watch", Prefix: "flags:"}, out)
+	require.NoError(t, err)
+	require.Len(t, out.GetEvents(), 1)
+	assert.Equal(t, "flags:dark-mode", out.GetEvents()[0].GetKey())
+	assert.Equal(t, "set", out.GetEvents()[0].GetOp())
+	assert.Equal(t, uint64(2), out.GetLast())
+
+	// wait for the next change
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		c, errD := net.Dial("tcp", "127.0.0.1:6001")
+		if errD != nil {
+			return
+		}
+		cl := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(c))
+		_ = cl.Call("kv.Delete", &payload.Request{Storage: "memory-watch", Items: []*payload.Item{{Key: "flags:dark-mode"}}}, &payload.Response{})
+	}()
+
+	after := out.GetLast()
+	out = &payload.Events{}
+	err = client.Call("kv.Watch", &payload.WatchRequest{Storage: "memory-watch", Prefix: "flags:", After: after, Wait: 5000}, out)
+	require.NoError(t, err)
+	require.Len(t, out.GetEvents(), 1)
+	assert.Equal(t, "delete", out.GetEvents()[0].GetOp())
+
+	// storage without the watch section
+	err = client.Call("kv.Watch", &payload.WatchRequest{Storage: "memory-rr"}, &payload.Events{})
+	assert.Error(t, err)
+}
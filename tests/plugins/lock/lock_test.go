@@ -0,0 +1,180 @@
+package lock
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	endure "github.com/spiral/endure/pkg/container"
+	goridgeRpc "github.com/spiral/goridge/v3/pkg/rpc"
+	lockv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/lock/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/boltdb"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/kv"
+	"github.com/spiral/roadrunner-plugins/v2/lock"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	rpcPlugin "github.com/spiral/roadrunner-plugins/v2/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockBoltDB(t *testing.T) {
+	serve(t, "configs/.rr-lock-boltdb.yaml", &kv.Plugin{}, &boltdb.Plugin{})
+	_ = os.Remove("rr-lock.db")
+}
+
+func TestLockLocal(t *testing.T) {
+	serve(t, "configs/.rr-lock-local.yaml")
+}
+
+func serve(t *testing.T, path string, plugins ...interface{}) {
+	cont, err := endure.NewContainer(nil, endure.SetLogLevel(endure.ErrorLevel))
+	assert.NoError(t, err)
+
+	cfg := &config.Viper{
+		Path:   path,
+		Prefix: "rr",
+	}
+
+	err = cont.RegisterAll(append([]interface{}{
+		cfg,
+		&lock.Plugin{},
+		&rpcPlugin.Plugin{},
+		&logger.ZapLogger{},
+	}, plugins...)...)
+	assert.NoError(t, err)
+
+	err = cont.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := cont.Serve()
+	assert.NoError(t, err)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	stopCh := make(chan struct{}, 1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case e := <-ch:
+				assert.Fail(t, "error", e.Error.Error())
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+			case <-sig:
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			case <-stopCh:
+				// timeout
+				err = cont.Stop()
+				if err != nil {
+					assert.FailNow(t, "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+
+	time.Sleep(time.Second * 1)
+	t.Run("LockRPC", testLockRPC)
+	stopCh <- struct{}{}
+	wg.Wait()
+}
+
+func testLockRPC(t *testing.T) {
+	conn, err := net.Dial("tcp", "127.0.0.1:6001")
+	require.NoError(t, err)
+	client := rpc.NewClientWithCodec(goridgeRpc.NewClientCodec(conn))
+
+	first := &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "orders", Ttl: 10}, first)
+	require.NoError(t, err)
+	require.True(t, first.Ok)
+	assert.NotEmpty(t, first.Owner)
+	assert.Greater(t, first.Fence, int64(0))
+
+	out := &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "orders", Owner: "another", Wait: 100}, out)
+	require.NoError(t, err)
+	assert.False(t, out.Ok)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Refresh", &lockv1.Request{Resource: "orders", Owner: first.Owner, Ttl: 10}, out)
+	require.NoError(t, err)
+	assert.True(t, out.Ok)
+	assert.Equal(t, first.Fence, out.Fence)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Release", &lockv1.Request{Resource: "orders", Owner: "another"}, out)
+	require.NoError(t, err)
+	assert.False(t, out.Ok)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Release", &lockv1.Request{Resource: "orders", Owner: first.Owner}, out)
+	require.NoError(t, err)
+	assert.True(t, out.Ok)
+
+	// fencing token is increased for the next holder
+	second := &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "orders", Owner: "another", Ttl: 1}, second)
+	require.NoError(t, err)
+	require.True(t, second.Ok)
+	assert.Equal(t, "another", second.Owner)
+	assert.Greater(t, second.Fence, first.Fence)
+
+	// the lock expires while waiting
+	third := &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "orders", Wait: 5000}, third)
+	require.NoError(t, err)
+	require.True(t, third.Ok)
+	assert.Greater(t, third.Fence, second.Fence)
+
+	// lost lock
+	out = &lockv1.Response{}
+	err = client.Call("lock.Refresh", &lockv1.Request{Resource: "orders", Owner: "another"}, out)
+	require.NoError(t, err)
+	assert.False(t, out.Ok)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.ForceRelease", &lockv1.Request{Resource: "orders"}, out)
+	require.NoError(t, err)
+	assert.True(t, out.Ok)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Release", &lockv1.Request{Resource: "orders", Owner: third.Owner}, out)
+	require.NoError(t, err)
+	assert.False(t, out.Ok)
+
+	// the resource name doesn't collide with the fencing tokens counter
+	fence := &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "fence", Ttl: 10}, fence)
+	require.NoError(t, err)
+	require.True(t, fence.Ok)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "orders", Ttl: 10}, out)
+	require.NoError(t, err)
+	require.True(t, out.Ok)
+	assert.Greater(t, out.Fence, fence.Fence)
+
+	out = &lockv1.Response{}
+	err = client.Call("lock.Acquire", &lockv1.Request{Resource: "__fence__"}, out)
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package tiered
+
+import (
+	endure "github.com/spiral/endure/pkg/container"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/spiral/roadrunner-plugins/v2/tiered/tieredkv"
+)
+
+const PluginName string = "tiered"
+
+// Plugin is a virtual KV driver, which composes two storages of any driver: the near cache (L1) in front of the
+// shared storage (L2).
+type Plugin struct {
+	log logger.Logger
+	cfg config.Configurer
+	// drivers for the tiers
+	constructors map[string]kv.Constructor
+	// publisher is used for the L1 invalidation, nil if the broadcast plugin is not available
+	publisher broadcast.Publisher
+}
+
+func (p *Plugin) Init(log logger.Logger, cfg config.Configurer) error {
+	p.log = log
+	p.cfg = cfg
+	p.constructors = make(map[string]kv.Constructor)
+	return nil
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Available() {}
+
+func (p *Plugin) Collects() []interface{} {
+	return []interface{}{
+		p.CollectConstructors,
+		p.CollectPublisher,
+	}
+}
+
+// CollectConstructors collects KV drivers used for the tiers
+func (p *Plugin) CollectConstructors(name endure.Named, c kv.Constructor) {
+	// nested tiers are not supported
+	if name.Name() == PluginName {
+		return
+	}
+
+	p.constructors[name.Name()] = c
+}
+
+// CollectPublisher collects the broadcast plugin, used for the L1 invalidation
+func (p *Plugin) CollectPublisher(_ endure.Named, pub broadcast.Publisher) {
+	p.publisher = pub
+}
+
+// KvFromConfig creates new tiered storage from the configuration
+func (p *Plugin) KvFromConfig(key string) (kv.Storage, error) {
+	return tieredkv.FromConfig(key, p.constructors, p.publisher, p.log, p.cfg)
+}
@@ -0,0 +1,34 @@
+package tieredkv
+
+const (
+	l1 string = "l1"
+	l2 string = "l2"
+
+	defaultTTL int = 10
+)
+
+// Config is the tiered storage configuration
+type Config struct {
+	// L1 is the near cache storage configuration (driver and config sections), e.g. memory
+	L1 *Tier `mapstructure:"l1"`
+
+	// L2 is the shared storage configuration, e.g. redis
+	L2 *Tier `mapstructure:"l2"`
+
+	// TTL of the keys in the L1 in seconds, default: 10
+	TTL int `mapstructure:"ttl"`
+
+	// Broker is the broadcast section used to invalidate the L1 on the other nodes, empty - no invalidation
+	Broker string `mapstructure:"broker"`
+}
+
+// Tier is the storage configuration, the driver config is in the config section
+type Tier struct {
+	Driver string `mapstructure:"driver"`
+}
+
+func (c *Config) InitDefaults() {
+	if c.TTL <= 0 {
+		c.TTL = defaultTTL
+	}
+}
@@ -0,0 +1,522 @@
+package tieredkv
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	json "github.com/json-iterator/go"
+	"github.com/spiral/errors"
+	"github.com/spiral/roadrunner-plugins/v2/api/kv"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/config"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+)
+
+const (
+	pluginName string = "tiered"
+	// TopicPrefix is the prefix of the L1 invalidation topics, the topic is TopicPrefix + storage name
+	TopicPrefix string = pubsub.ReservedTopicPrefix + "kv.tiered."
+)
+
+// invalidation is the message sent to the other nodes on writes
+type invalidation struct {
+	// Node is the sender, it skips its own messages
+	Node  string   `json:"node"`
+	Keys  []string `json:"keys,omitempty"`
+	Clear bool     `json:"clear,omitempty"`
+}
+
+// Storage reads the keys from the L1 and falls through to the L2, keys found in the L2 are stored in the L1 with
+// the short TTL. Writes go to the L2 first, the written keys are stored in the L1 or deleted from it (counters and
+// conditional writes). L1 of the other nodes is invalidated via the broadcast, so the stale reads are bounded by the TTL.
+type Storage struct {
+	log logger.Logger
+	l1  kv.Storage
+	l2  kv.Storage
+	ttl time.Duration
+
+	// invalidation, publisher is nil if the broker is not configured
+	node      string
+	topic     string
+	broker    string
+	publisher broadcast.Publisher
+	sub       pubsub.SubReader
+	cancel    context.CancelFunc
+}
+
+// FromConfig creates the tiered storage, tiers are initialized from the configKey.l1.config and configKey.l2.config sections
+func FromConfig(configKey string, constructors map[string]kv.Constructor, publisher broadcast.Publisher, log logger.Logger, cfg config.Configurer) (*Storage, error) {
+	const op = errors.Op("tiered_storage_from_config")
+
+	var conf *Config
+	err := cfg.UnmarshalKey(configKey, &conf)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	if conf == nil {
+		return nil, errors.E(op, errors.Errorf("config not found by provided key: %s", configKey))
+	}
+
+	conf.InitDefaults()
+
+	s := &Storage{
+		log:   log,
+		ttl:   time.Second * time.Duration(conf.TTL),
+		node:  uuid.NewString(),
+		topic: TopicPrefix + strings.TrimSuffix(strings.TrimPrefix(configKey, "kv."), ".config"),
+	}
+
+	s.l1, err = tier(configKey, l1, conf.L1, constructors)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	s.l2, err = tier(configKey, l2, conf.L2, constructors)
+	if err != nil {
+		s.l1.Stop()
+		return nil, errors.E(op, err)
+	}
+
+	if conf.Broker == "" {
+		return s, nil
+	}
+
+	err = s.subscribe(conf.Broker, publisher)
+	if err != nil {
+		s.l1.Stop()
+		s.l2.Stop()
+		return nil, errors.E(op, err)
+	}
+
+	return s, nil
+}
+
+func tier(configKey, name string, t *Tier, constructors map[string]kv.Constructor) (kv.Storage, error) {
+	if t == nil || t.Driver == "" {
+		return nil, errors.Errorf("could not find mandatory driver field in the %s tier", name)
+	}
+
+	if t.Driver == pluginName {
+		return nil, errors.Errorf("nested %s storages are not supported, tier: %s", pluginName, name)
+	}
+
+	c, ok := constructors[t.Driver]
+	if !ok {
+		return nil, errors.Errorf("no constructors registered for the %s tier, requested: %s", name, t.Driver)
+	}
+
+	return c.KvFromConfig(configKey + "." + name + ".config")
+}
+
+func (s *Storage) subscribe(broker string, publisher broadcast.Publisher) error {
+	if publisher == nil {
+		return errors.Errorf("broadcast plugin is not available, broker: %s", broker)
+	}
+
+	// the reader of the broker shared with the other subscribers (websockets, other tiered storages)
+	sub, err := publisher.GetDriver(broker)
+	if err != nil {
+		return err
+	}
+
+	err = sub.Subscribe(s.node, s.topic)
+	if err != nil {
+		sub.Stop()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.broker = broker
+	s.publisher = publisher
+	s.sub = sub
+	s.cancel = cancel
+
+	go s.listen(ctx)
+	return nil
+}
+
+// listen invalidates the L1 on the messages from the other nodes
+func (s *Storage) listen(ctx context.Context) {
+	for {
+		msg, err := s.sub.Next(ctx)
+		if err != nil {
+			if errors.Is(errors.TimeOut, err) {
+				return
+			}
+
+			s.log.Error("invalidation messages reading failed, L1 is not invalidated", "error", err, "topic", s.topic)
+			return
+		}
+
+		// not subscribed topic or the closed driver
+		if msg == nil || msg.Topic != s.topic {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		s.handle(msg.Payload)
+	}
+}
+
+func (s *Storage) handle(payload []byte) {
+	inv := &invalidation{}
+	err := json.Unmarshal(payload, inv)
+	if err != nil {
+		s.log.Warn("wrong invalidation message", "error", err, "topic", s.topic)
+		return
+	}
+
+	if inv.Node == s.node {
+		return
+	}
+
+	if inv.Clear {
+		err = s.l1.Clear()
+	} else if len(inv.Keys) > 0 {
+		err = s.l1.Delete(inv.Keys...)
+	}
+
+	if err != nil {
+		s.log.Error("L1 invalidation failed", "error", err, "topic", s.topic)
+	}
+}
+
+// invalidate deletes the keys from the L1 of the other nodes, clear - all keys
+func (s *Storage) invalidate(clear bool, keys ...string) {
+	if s.publisher == nil {
+		return
+	}
+
+	data, err := json.Marshal(&invalidation{Node: s.node, Keys: keys, Clear: clear})
+	if err != nil {
+		s.log.Error("invalidation message marshal failed", "error", err)
+		return
+	}
+
+	err = s.publisher.PublishTo(s.broker, &pubsub.Message{Topic: s.topic, Payload: data})
+	if err != nil {
+		s.log.Error("invalidation message publish failed", "error", err, "topic", s.topic)
+	}
+}
+
+// timeout returns the L1 timeout, not later than the item timeout
+func (s *Storage) timeout(itemTimeout string) string {
+	t := time.Now().Add(s.ttl)
+	if itemTimeout != "" {
+		if it, err := time.Parse(time.RFC3339, itemTimeout); err == nil && it.Before(t) {
+			t = it
+		}
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// populate stores the values read from the L2 in the L1
+func (s *Storage) populate(values map[string][]byte) {
+	if len(values) == 0 {
+		return
+	}
+
+	tt := s.timeout("")
+	items := make([]*kvv1.Item, 0, len(values))
+	for k, v := range values {
+		items = append(items, &kvv1.Item{Key: k, Value: v, Timeout: tt})
+	}
+
+	err := s.l1.Set(items...)
+	if err != nil {
+		s.log.Warn("L1 populate failed", "error", err)
+	}
+}
+
+// dropL1 deletes the changed keys from the L1 of all nodes
+func (s *Storage) dropL1(keys ...string) error {
+	err := s.l1.Delete(keys...)
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(false, keys...)
+	return nil
+}
+
+func (s *Storage) Has(keys ...string) (map[string]bool, error) {
+	const op = errors.Op("tiered_storage_has")
+	m, err := s.l1.Has(keys...)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	rest := make([]string, 0, len(keys))
+	for i := range keys {
+		if !m[keys[i]] {
+			rest = append(rest, keys[i])
+		}
+	}
+
+	if len(rest) == 0 {
+		return m, nil
+	}
+
+	m2, err := s.l2.Has(rest...)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	for k, v := range m2 {
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func (s *Storage) Get(key string) ([]byte, error) {
+	const op = errors.Op("tiered_storage_get")
+	m, err := s.MGet(key)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	return m[key], nil
+}
+
+func (s *Storage) MGet(keys ...string) (map[string][]byte, error) {
+	const op = errors.Op("tiered_storage_mget")
+	m, err := s.l1.MGet(keys...)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	rest := make([]string, 0, len(keys))
+	for i := range keys {
+		if _, ok := m[keys[i]]; !ok {
+			rest = append(rest, keys[i])
+		}
+	}
+
+	if len(rest) == 0 {
+		return m, nil
+	}
+
+	m2, err := s.l2.MGet(rest...)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	s.populate(m2)
+	for k, v := range m2 {
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func (s *Storage) Set(items ...*kvv1.Item) error {
+	const op = errors.Op("tiered_storage_set")
+	err := s.l2.Set(items...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	near := make([]*kvv1.Item, 0, len(items))
+	keys := make([]string, 0, len(items))
+	for i := range items {
+		if items[i] == nil {
+			continue
+		}
+
+		near = append(near, &kvv1.Item{
+			Key:     items[i].Key,
+			Value:   items[i].Value,
+			Timeout: s.timeout(items[i].Timeout),
+			Tags:    items[i].Tags,
+		})
+		keys = append(keys, items[i].Key)
+	}
+
+	err = s.l1.Set(near...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	s.invalidate(false, keys...)
+	return nil
+}
+
+func (s *Storage) MExpire(items ...*kvv1.Item) error {
+	const op = errors.Op("tiered_storage_mexpire")
+	err := s.l2.MExpire(items...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	keys := make([]string, 0, len(items))
+	for i := range items {
+		if items[i] != nil {
+			keys = append(keys, items[i].Key)
+		}
+	}
+
+	err = s.dropL1(keys...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// TTL is read from the L2
+func (s *Storage) TTL(keys ...string) (map[string]string, error) {
+	return s.l2.TTL(keys...)
+}
+
+// Tags returns the tags of the l2 storage keys, empty if the l2 storage doesn't return the tags
+func (s *Storage) Tags(keys ...string) (map[string][]string, error) {
+	if t, ok := s.l2.(kv.Tagger); ok {
+		return t.Tags(keys...)
+	}
+
+	return map[string][]string{}, nil
+}
+
+func (s *Storage) Clear() error {
+	const op = errors.Op("tiered_storage_clear")
+	err := s.l2.Clear()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.l1.Clear()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	s.invalidate(true)
+	return nil
+}
+
+func (s *Storage) Delete(keys ...string) error {
+	const op = errors.Op("tiered_storage_delete")
+	err := s.l2.Delete(keys...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.dropL1(keys...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Incr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("tiered_storage_incr")
+	v, err := s.l2.Incr(key, delta, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	err = s.dropL1(key)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+func (s *Storage) Decr(key string, delta int64, timeout string) (int64, error) {
+	const op = errors.Op("tiered_storage_decr")
+	v, err := s.l2.Decr(key, delta, timeout)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	err = s.dropL1(key)
+	if err != nil {
+		return 0, errors.E(op, err)
+	}
+
+	return v, nil
+}
+
+func (s *Storage) SetNX(item *kvv1.Item) (bool, error) {
+	const op = errors.Op("tiered_storage_setnx")
+	ok, err := s.l2.SetNX(item)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	err = s.dropL1(item.Key)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+func (s *Storage) CompareAndSwap(key string, oldValue, newValue []byte, timeout string) (bool, error) {
+	const op = errors.Op("tiered_storage_cas")
+	ok, err := s.l2.CompareAndSwap(key, oldValue, newValue, timeout)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	if !ok {
+		return false, nil
+	}
+
+	err = s.dropL1(key)
+	if err != nil {
+		return false, errors.E(op, err)
+	}
+
+	return true, nil
+}
+
+// Keys are listed from the L2
+func (s *Storage) Keys(prefix string, cursor string, limit int) ([]string, string, error) {
+	return s.l2.Keys(prefix, cursor, limit)
+}
+
+// InvalidateTags invalidates the tags in the L2, L1 is cleared entirely since the keys read from the L2 have no tags there
+func (s *Storage) InvalidateTags(tags ...string) error {
+	const op = errors.Op("tiered_storage_invalidate_tags")
+	err := s.l2.InvalidateTags(tags...)
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = s.l1.Clear()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	s.invalidate(true)
+	return nil
+}
+
+func (s *Storage) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		err := s.sub.Unsubscribe(s.node, s.topic)
+		if err != nil {
+			s.log.Warn("invalidation topic unsubscribe failed", "error", err, "topic", s.topic)
+		}
+		s.sub.Stop()
+	}
+
+	s.l1.Stop()
+	s.l2.Stop()
+}
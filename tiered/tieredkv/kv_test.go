@@ -0,0 +1,119 @@
+package tieredkv
+
+import (
+	"testing"
+	"time"
+
+	json "github.com/json-iterator/go"
+	kvv1 "github.com/spiral/roadrunner-plugins/v2/api/proto/kv/v1beta"
+	"github.com/spiral/roadrunner-plugins/v2/api/pubsub"
+	"github.com/spiral/roadrunner-plugins/v2/broadcast"
+	"github.com/spiral/roadrunner-plugins/v2/kv/kvtest"
+	"github.com/spiral/roadrunner-plugins/v2/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakePublisher struct {
+	broadcast.Publisher
+
+	messages []*pubsub.Message
+}
+
+func (f *fakePublisher) PublishTo(_ string, m *pubsub.Message) error {
+	f.messages = append(f.messages, m)
+	return nil
+}
+
+func newTestStorage() (*Storage, *kvtest.Storage, *kvtest.Storage, *fakePublisher) {
+	l1, l2, pub := kvtest.NewStorage(), kvtest.NewStorage(), &fakePublisher{}
+	return &Storage{
+		log:       logger.NewZapAdapter(zap.NewNop()),
+		l1:        l1,
+		l2:        l2,
+		ttl:       time.Second * 10,
+		node:      "node-1",
+		topic:     TopicPrefix + "near",
+		publisher: pub,
+	}, l1, l2, pub
+}
+
+func TestReadThrough(t *testing.T) {
+	s, l1, l2, _ := newTestStorage()
+	require.NoError(t, l2.Set(&kvv1.Item{Key: "a", Value: []byte("a")}))
+
+	v, err := s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), v)
+
+	// populated with the L1 TTL
+	it := l1.Item("a")
+	require.NotNil(t, it)
+	tt, err := time.Parse(time.RFC3339, it.Timeout)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(s.ttl), tt, time.Second*2)
+
+	has, err := s.Has("a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true}, has)
+
+	m, err := s.MGet("a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("a")}, m)
+}
+
+func TestWrites(t *testing.T) {
+	s, l1, l2, pub := newTestStorage()
+
+	// item timeout is earlier than the L1 TTL
+	tt := time.Now().Add(time.Second * 2).Format(time.RFC3339)
+	require.NoError(t, s.Set(&kvv1.Item{Key: "a", Value: []byte("1"), Timeout: tt}, &kvv1.Item{Key: "b", Value: []byte("b")}))
+	assert.Equal(t, tt, l1.Item("a").Timeout)
+	assert.Equal(t, tt, l2.Item("a").Timeout)
+	assert.NotEmpty(t, l1.Item("b").Timeout)
+	assert.Empty(t, l2.Item("b").Timeout)
+
+	require.Len(t, pub.messages, 1)
+	inv := &invalidation{}
+	require.NoError(t, json.Unmarshal(pub.messages[0].Payload, inv))
+	assert.Equal(t, &invalidation{Node: "node-1", Keys: []string{"a", "b"}}, inv)
+
+	// counters are not cached
+	v, err := s.Incr("a", 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+	assert.Nil(t, l1.Item("a"))
+	assert.Equal(t, []byte("2"), l2.Value("a"))
+	assert.Len(t, pub.messages, 2)
+
+	require.NoError(t, s.Delete("b"))
+	assert.Nil(t, l1.Item("b"))
+	assert.Nil(t, l2.Item("b"))
+}
+
+func TestInvalidation(t *testing.T) {
+	s, l1, _, _ := newTestStorage()
+	require.NoError(t, l1.Set(&kvv1.Item{Key: "a"}, &kvv1.Item{Key: "b"}, &kvv1.Item{Key: "c"}))
+
+	msg := func(inv *invalidation) []byte {
+		data, err := json.Marshal(inv)
+		require.NoError(t, err)
+		return data
+	}
+
+	// own message
+	s.handle(msg(&invalidation{Node: "node-1", Keys: []string{"a"}}))
+	assert.NotNil(t, l1.Item("a"))
+
+	s.handle(msg(&invalidation{Node: "node-2", Keys: []string{"a"}}))
+	assert.Nil(t, l1.Item("a"))
+	assert.NotNil(t, l1.Item("b"))
+
+	s.handle([]byte("{"))
+	assert.NotNil(t, l1.Item("b"))
+
+	s.handle(msg(&invalidation{Node: "node-2", Clear: true}))
+	assert.Nil(t, l1.Item("b"))
+	assert.Nil(t, l1.Item("c"))
+}